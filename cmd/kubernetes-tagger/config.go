@@ -2,32 +2,63 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"os"
 	"path/filepath"
-	"time"
 
 	"github.com/fsnotify/fsnotify"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/business"
 	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/metrics"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/tracing"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	componentbaseconfig "k8s.io/component-base/config"
 )
 
 // Kubernetes configuration home path.
 const kubeConfig = ".kube/config"
 
-func configureViper(onChange func(e fsnotify.Event)) error {
+// Default configuration values.
+const (
+	defaultNamespace = "kube-system"
+	defaultAddress   = ":8085"
+	defaultLogLevel  = "info"
+	defaultLogFormat = "json"
+	defaultProvider  = config.AWSProviderName
+)
+
+// leaderElectionFlags Leader election flags by configuration key.
+var leaderElectionFlags = map[string]string{
+	"leaderElection.enabled":       "leader-elect",
+	"leaderElection.lockType":      "leader-elect-lock-type",
+	"leaderElection.lockName":      "leader-elect-lock-name",
+	"leaderElection.lockNamespace": "leader-elect-lock-namespace",
+	"leaderElection.leaseDuration": "leader-elect-lease-duration",
+	"leaderElection.renewDeadline": "leader-elect-renew-deadline",
+	"leaderElection.retryPeriod":   "leader-elect-retry-period",
+}
+
+func configureViper() error {
 	kubeConfigPath := filepath.Join(os.Getenv("HOME"), kubeConfig)
 	// Flags
-	flag.String("namespace", "kube-system", "Namespace where "+projectName+" is deployed")
+	flag.String("namespace", defaultNamespace, "Namespace where "+projectName+" is deployed")
 	flag.String("kubeconfig", kubeConfigPath, "Kubernetes configuration file path")
-	flag.String("address", ":8085", "The address to expose health and prometheus metrics")
-	flag.String("loglevel", "info", "Log level")
-	flag.String("logformat", "json", "Log format")
-	flag.String("provider", "aws", "Kubernetes Provider")
+	flag.String("address", defaultAddress, "The address to expose health and prometheus metrics")
+	flag.String("loglevel", defaultLogLevel, "Log level")
+	flag.String("logformat", defaultLogFormat, "Log format")
+	flag.String("provider", defaultProvider, "Kubernetes Provider")
+	// Leader election flags
+	flag.Bool("leader-elect", true, "Enable leader election, only one instance must run when disabled")
+	flag.String("leader-elect-lock-type", config.DefaultLeaderElectionLockType, "Leader election lock type (leases, endpointsleases or configmapsleases)")
+	flag.String("leader-elect-lock-name", config.DefaultLeaderElectionLockName, "Leader election lock name")
+	flag.String("leader-elect-lock-namespace", "", "Leader election lock namespace (default to the deployment one)")
+	flag.String("leader-elect-lease-duration", config.DefaultLeaderElectionLeaseDuration.String(), "Leader election lease duration")
+	flag.String("leader-elect-renew-deadline", config.DefaultLeaderElectionRenewDeadline.String(), "Leader election renew deadline")
+	flag.String("leader-elect-retry-period", config.DefaultLeaderElectionRetryPeriod.String(), "Leader election retry period")
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
 	pflag.Parse()
 
@@ -36,40 +67,125 @@ func configureViper(onChange func(e fsnotify.Event)) error {
 	if err != nil {
 		return err
 	}
+
+	// Leader election flags are in the leaderElection configuration block
+	for key, flagName := range leaderElectionFlags {
+		err = viper.BindPFlag(key, pflag.Lookup(flagName))
+		// Check error
+		if err != nil {
+			return err
+		}
+	}
 	// Add config file name
 	viper.SetConfigName(config.RecommendedConfigFileName)
 	// Add config possible path
 	viper.AddConfigPath("/etc/" + projectName + "/")
 	viper.AddConfigPath("$HOME/." + projectName)
 	viper.AddConfigPath(".")
-	// Watch configuration file change
-	viper.WatchConfig()
-	viper.OnConfigChange(onChange)
 
 	// Default
 	return nil
 }
 
-// Default values for leader election.
-const (
-	defaultLeaseDuration = 15 * time.Second
-	defaultRenewDeadline = 10 * time.Second
-	defaultRetryPeriod   = 2 * time.Second
-)
+func watchConfiguration(onChange func(e fsnotify.Event)) {
+	// Watch configuration file change
+	viper.OnConfigChange(onChange)
+	viper.WatchConfig()
+}
 
-func defaultLeaderElectionConfiguration() componentbaseconfig.LeaderElectionConfiguration {
-	return componentbaseconfig.LeaderElectionConfiguration{
-		LeaderElect:   true,
-		LeaseDuration: metav1.Duration{Duration: defaultLeaseDuration},
-		RenewDeadline: metav1.Duration{Duration: defaultRenewDeadline},
-		RetryPeriod:   metav1.Duration{Duration: defaultRetryPeriod},
-		ResourceLock:  resourcelock.EndpointsResourceLock,
+func loadConfiguration() (*business.Snapshot, error) {
+	err := viper.ReadInConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error reading configuration file: %w", err)
+	}
+
+	var cfg config.Configuration
+
+	err = viper.Unmarshal(&cfg)
+	// Check error
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling configuration: %w", err)
+	}
+
+	// Validate configuration and rules before using them
+	return business.NewSnapshot(&cfg)
+}
+
+// readConfigurationFile Read a configuration file without flags, environment and watch.
+// This is used by subcommands working on a candidate configuration.
+func readConfigurationFile(path string) (*config.Configuration, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	// Set same defaults as flags
+	v.SetDefault("namespace", defaultNamespace)
+	v.SetDefault("kubeconfig", filepath.Join(os.Getenv("HOME"), kubeConfig))
+	v.SetDefault("address", defaultAddress)
+	v.SetDefault("loglevel", defaultLogLevel)
+	v.SetDefault("logformat", defaultLogFormat)
+	v.SetDefault("provider", defaultProvider)
+	v.SetDefault("leaderElection.enabled", true)
+
+	err := v.ReadInConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error reading configuration file: %w", err)
 	}
+
+	var cfg config.Configuration
+
+	err = v.Unmarshal(&cfg)
+	// Check error
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling configuration: %w", err)
+	}
+
+	return &cfg, nil
+}
+
+// reloadConfiguration Reload configuration and keep the previous one when the new one is invalid.
+func reloadConfiguration(podReference *v1.ObjectReference) {
+	snapshot, err := loadConfiguration()
+	if err != nil {
+		logrus.Errorf("Invalid configuration, previous one is kept: %v", err)
+		metrics.ConfigurationReloads.WithLabelValues(metrics.FailureResult).Inc()
+		context.SetConfigurationError(err)
+		context.EventRecorder.Eventf(podReference, v1.EventTypeWarning, "ConfigurationReloadFailed",
+			"Invalid configuration, previous one is kept: %v", err)
+
+		return
+	}
+
+	context.SetSnapshot(snapshot)
+	context.SetConfigurationError(nil)
+	metrics.ConfigurationReloads.WithLabelValues(metrics.SuccessResult).Inc()
+	logrus.Info("Configuration reloaded")
+
+	// Apply new configuration on all objects
+	context.Reconcile()
+}
+
+// getLeaderElectionConfiguration Get leader election configuration from configuration.
+func getLeaderElectionConfiguration(cfg *config.Configuration) (componentbaseconfig.LeaderElectionConfiguration, error) {
+	durations, err := cfg.LeaderElection.GetDurations()
+	if err != nil {
+		return componentbaseconfig.LeaderElectionConfiguration{}, err
+	}
+
+	return componentbaseconfig.LeaderElectionConfiguration{
+		LeaderElect:       cfg.LeaderElection.IsLeaderElectionEnabled(),
+		LeaseDuration:     metav1.Duration{Duration: durations.LeaseDuration},
+		RenewDeadline:     metav1.Duration{Duration: durations.RenewDeadline},
+		RetryPeriod:       metav1.Duration{Duration: durations.RetryPeriod},
+		ResourceLock:      cfg.LeaderElection.GetLockType(),
+		ResourceName:      cfg.LeaderElection.GetLockName(),
+		ResourceNamespace: cfg.LeaderElection.GetLockNamespace(cfg.Namespace),
+	}, nil
 }
 
 func configureLogger() {
 	// Log level
-	lvl, err := logrus.ParseLevel(context.Configuration.LogLevel)
+	cfg := context.GetSnapshot().Configuration
+
+	lvl, err := logrus.ParseLevel(cfg.LogLevel)
 	if err != nil {
 		logrus.Fatal(err)
 	}
@@ -77,12 +193,15 @@ func configureLogger() {
 	logrus.SetLevel(lvl)
 
 	// Log Formatter
-	switch context.Configuration.LogFormat {
+	switch cfg.LogFormat {
 	case "json":
 		logrus.SetFormatter(&logrus.JSONFormatter{})
 	case "text":
 		logrus.SetFormatter(&logrus.TextFormatter{})
 	default:
-		logrus.Fatalf("Log format not supported: %s", context.Configuration.LogFormat)
+		logrus.Fatalf("Log format not supported: %s", cfg.LogFormat)
 	}
+
+	// Trace fields are added to entries with a traced context
+	logrus.AddHook(tracing.LogHook{})
 }
@@ -0,0 +1,154 @@
+package main
+
+import (
+	ctx "context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/business"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+)
+
+// Explain output formats.
+const textOutputFormat = "text"
+
+// runExplain Trace rules evaluation for one object.
+func runExplain(args []string) int {
+	flags := pflag.NewFlagSet("explain", pflag.ContinueOnError)
+	configPath := flags.String("config", "", "Configuration file path")
+	kubeConfigPath := flags.String("kubeconfig", "", "Kubernetes configuration file path (default to the configuration one)")
+	output := flags.StringP("output", "o", textOutputFormat, "Output format (text or json)")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return exitCodeError
+	}
+
+	if *configPath == "" || flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: explain --config <file> pv/<name>|svc/<namespace>/<name>")
+
+		return exitCodeError
+	}
+
+	// Only errors must be displayed to keep output clean
+	logrus.SetLevel(logrus.ErrorLevel)
+
+	cfg, err := readConfigurationFile(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR %v\n", err)
+
+		return exitCodeError
+	}
+
+	snapshot, err := business.NewSnapshot(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR %v\n", err)
+
+		return exitCodeError
+	}
+
+	if *kubeConfigPath != "" {
+		cfg.Kubeconfig = *kubeConfigPath
+	}
+
+	kubeClient, err := getKubernetesClient(cfg.Kubeconfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot create a Kubernetes client: %v\n", err)
+
+		return exitCodeError
+	}
+
+	getRules, err := getRulesGetter(snapshot)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot create a Kubernetes dynamic client: %v\n", err)
+
+		return exitCodeError
+	}
+
+	explanation, err := business.Explain(ctx.Background(), kubeClient, snapshot, getRules, flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR %v\n", err)
+
+		return exitCodeError
+	}
+
+	switch *output {
+	case jsonOutputFormat:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+
+		err = encoder.Encode(explanation)
+	case textOutputFormat:
+		printTextExplanation(explanation, os.Stdout)
+	default:
+		err = fmt.Errorf("output format not supported: %s", *output)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR %v\n", err)
+
+		return exitCodeError
+	}
+
+	return exitCodeSuccess
+}
+
+func printTextExplanation(explanation *business.Explanation, out io.Writer) {
+	fmt.Fprintf(out, "Resource: %s (%s/%s)\n\n", explanation.Reference, explanation.Platform, explanation.Type)
+
+	fmt.Fprintln(out, "Rules:")
+
+	for _, ruleTrace := range explanation.Trace.Rules {
+		fmt.Fprintf(out, "  #%d %s tag \"%s\": %s (%s)\n",
+			ruleTrace.Index, ruleTrace.Action, ruleTrace.Tag, ruleTrace.Result, ruleTrace.Reason)
+
+		for _, conditionTrace := range ruleTrace.Conditions {
+			fmt.Fprintf(out, "      when %s %s \"%s\": actual \"%s\", matched: %t\n",
+				conditionTrace.Condition, conditionTrace.Operator, conditionTrace.Expected,
+				conditionTrace.Actual, conditionTrace.Matched)
+		}
+
+		if ruleTrace.Query != "" {
+			fmt.Fprintf(out, "      query %s: \"%s\"\n", ruleTrace.Query, ruleTrace.QueryResult)
+		}
+	}
+
+	fmt.Fprintln(out, "\nChanges:")
+
+	if len(explanation.Changes) == 0 {
+		fmt.Fprintln(out, "  No changes")
+	}
+
+	for _, change := range explanation.Changes {
+		fmt.Fprintf(out, "  %s %s: \"%s\" -> \"%s\"\n", change.Action, change.Key, change.OldValue, change.NewValue)
+	}
+}
+
+// explainHandler Debug endpoint returning rules evaluation trace for the reference query parameter.
+func explainHandler(w http.ResponseWriter, r *http.Request) {
+	snapshot := context.GetSnapshot()
+	// Check if debug endpoints are enabled
+	if !snapshot.Configuration.DebugEndpoints {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	explanation, err := business.Explain(r.Context(), context.KubernetesClient, snapshot, context.GetAdmissionRules, r.URL.Query().Get("reference"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	err = json.NewEncoder(w).Encode(explanation)
+	if err != nil {
+		logrus.Errorf("Cannot write explain response: %v", err)
+	}
+}
@@ -3,21 +3,25 @@ package main
 import (
 	ctx "context"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/tracing"
 	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/utils"
 
 	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/version"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/business"
-	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
-	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/rules"
 
 	"github.com/sirupsen/logrus"
-	"github.com/spf13/viper"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/leaderelection"
@@ -29,9 +33,27 @@ import (
 // Project name used for configuration path.
 const projectName = "kubernetes-tagger"
 
+// tracingStopTimeout Maximum duration to export remaining spans when exiting.
+const tracingStopTimeout = 5 * time.Second
+
+// workersStopTimeout Maximum duration to wait for in-flight reconciles when stopping business.
+// It must be lower than the pod termination grace period to release the leader lease before being killed.
+const workersStopTimeout = 20 * time.Second
+
 var context = &business.Context{}
 
 func main() {
+	// Run subcommand if one is asked
+	if len(os.Args) > 1 {
+		if cmd, ok := subcommands[os.Args[1]]; ok {
+			os.Exit(cmd(os.Args[2:]))
+		}
+	}
+
+	// Root context cancelled on termination signals
+	rootCtx, stopSignals := signal.NotifyContext(ctx.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
 	// Get Hostname to have unique id for container
 	id, err := os.Hostname()
 	if err != nil {
@@ -39,25 +61,19 @@ func main() {
 	}
 
 	// Viper configuration
-	err = configureViper(func(e fsnotify.Event) {
-		// Event only say that the file is reloading
-		logrus.WithField("file", e.Name).Info("Configuration file changed")
-		// Reload configuration
-		err = readConfiguration()
-		if err != nil {
-			logrus.Fatal(err)
-		}
-	})
+	err = configureViper()
 	// Check error
 	if err != nil {
 		logrus.Fatal(err)
 	}
 
-	err = readConfiguration()
+	snapshot, err := loadConfiguration()
 	if err != nil {
 		logrus.Fatal(err)
 	}
 
+	context.SetSnapshot(snapshot)
+
 	// Configure logger
 	configureLogger()
 
@@ -69,10 +85,11 @@ func main() {
 		"version":    versionObj.Version,
 	}).Infof("Starting %s", projectName)
 
-	// Go routine for server listener
-	go serve()
+	// Tracing configuration needs a restart to be changed
+	stopTracing := startTracing(snapshot.Configuration.Tracing, versionObj.Version)
+	defer stopTracing()
 
-	kubeClient, err := getKubernetesClient()
+	kubeClient, err := getKubernetesClient(snapshot.Configuration.Kubeconfig)
 	if err != nil {
 		logrus.Fatalf("Cannot create a Kubernetes client: %v", err)
 	}
@@ -80,21 +97,68 @@ func main() {
 	// Add Kubernetes client to context
 	context.KubernetesClient = kubeClient
 
-	// Create default leader election configuration
-	leaderElection := defaultLeaderElectionConfiguration()
+	// Dynamic client is used to watch tagging policies
+	if snapshot.Configuration.TaggingPolicies {
+		dynamicClient, err := getKubernetesDynamicClient(snapshot.Configuration.Kubeconfig)
+		if err != nil {
+			logrus.Fatalf("Cannot create a Kubernetes dynamic client: %v", err)
+		}
+
+		context.DynamicClient = dynamicClient
+	}
+
+	// Go routine for server listener
+	go serve()
+	// Go routine for admission webhook listener
+	go serveWebhook()
+
+	// Leader election configuration needs a restart to be changed
+	leaderElection, err := getLeaderElectionConfiguration(snapshot.Configuration)
+	if err != nil {
+		logrus.Fatalf("Invalid leader election configuration: %v", err)
+	}
 
 	// Create event broadcaster
 	eventBroadcaster := kube_record.NewBroadcaster()
 	// Add logger to event broadcaster
 	eventBroadcaster.StartLogging(logrus.Infof)
+	// Send events to Kubernetes
+	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events("")})
 	// Create event recorder from event broadcaster
 	eventRecorder := eventBroadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: projectName})
+	// Add event recorder to context
+	context.EventRecorder = eventRecorder
+
+	// Reference to the current pod for events
+	podReference := &v1.ObjectReference{Kind: "Pod", Namespace: snapshot.Configuration.Namespace, Name: id}
+
+	// Watch configuration changes now that context is ready
+	watchConfiguration(func(e fsnotify.Event) {
+		// Event only say that the file is reloading
+		logrus.WithField("file", e.Name).Info("Configuration file changed")
+		// Reload configuration
+		reloadConfiguration(podReference)
+	})
+
+	// Only one instance must run without leader election
+	if !leaderElection.LeaderElect {
+		logrus.Warn("Leader election disabled, this instance is the leader")
+		context.SetLeader(true)
+		run(rootCtx)
+
+		// Business runs in background until termination
+		<-rootCtx.Done()
+		stopBusiness("Termination signal received, stopping business")
+		eventBroadcaster.Shutdown()
+
+		return
+	}
 
 	// Create new resource lock
 	lock, err := resourcelock.New(
 		leaderElection.ResourceLock,
-		context.Configuration.Namespace,
-		projectName,
+		leaderElection.ResourceNamespace,
+		leaderElection.ResourceName,
 		kubeClient.CoreV1(),
 		kubeClient.CoordinationV1(),
 		resourcelock.ResourceLockConfig{
@@ -106,90 +170,154 @@ func main() {
 		logrus.Fatalf("Unable to create leader election lock: %v", err)
 	}
 
-	// Leader election
-	leaderelection.RunOrDie(ctx.TODO(), leaderelection.LeaderElectionConfig{
-		Lock:          lock,
-		LeaseDuration: leaderElection.LeaseDuration.Duration,
-		RenewDeadline: leaderElection.RenewDeadline.Duration,
-		RetryPeriod:   leaderElection.RetryPeriod.Duration,
-		Callbacks: leaderelection.LeaderCallbacks{
-			OnNewLeader: func(identity string) {
-				if identity != id {
-					logrus.WithField("leader", identity).Info("Other leader detected")
-				}
-			},
-			OnStartedLeading: func(_ ctx.Context) {
-				// Since we are committing a suicide after losing
-				// mastership, we can safely ignore the argument.
-				logrus.WithField("leader", id).Info("Start leading")
-				run()
+	// Election is cancelled after business is stopped to release the lease without in-flight reconciles
+	electionCtx, cancelElection := ctx.WithCancel(ctx.Background())
+
+	go func() {
+		<-rootCtx.Done()
+		stopBusiness("Termination signal received, stopping business")
+		cancelElection()
+	}()
+
+	// Leader election, instance is candidate again after losing leadership until termination
+	for electionCtx.Err() == nil {
+		leaderelection.RunOrDie(electionCtx, leaderelection.LeaderElectionConfig{
+			Lock:            lock,
+			LeaseDuration:   leaderElection.LeaseDuration.Duration,
+			RenewDeadline:   leaderElection.RenewDeadline.Duration,
+			RetryPeriod:     leaderElection.RetryPeriod.Duration,
+			ReleaseOnCancel: true,
+			Callbacks: leaderelection.LeaderCallbacks{
+				OnNewLeader: func(identity string) {
+					if identity != id {
+						logrus.WithField("leader", identity).Info("Other leader detected")
+					}
+				},
+				OnStartedLeading: func(leaderCtx ctx.Context) {
+					// Business is stopped by OnStoppedLeading
+					logrus.WithField("leader", id).Info("Start leading")
+					context.SetLeader(true)
+					run(leaderCtx)
+				},
+				OnStoppedLeading: func() {
+					stopBusiness("Leadership lost, stopping business")
+				},
 			},
-			OnStoppedLeading: func() {
-				logrus.Fatal("Lost master")
-			},
-		},
-	})
+		})
+	}
+
+	eventBroadcaster.Shutdown()
+	logrus.Info("Leader election stopped, exiting")
 }
 
-func run() {
-	logrus.Info("Launch business")
-	// Watch persistent volumes and services
-	business.Watch(context)
+// stopBusiness Stop business so that another instance can manage objects.
+// In-flight reconciles are waited until workersStopTimeout.
+func stopBusiness(reason string) {
+	// Called after each election, even when the instance wasn't leading
+	if !context.IsLeader() {
+		return
+	}
+
+	context.SetLeader(false)
+	logrus.Warn(reason)
+
+	if !context.StopWatch(workersStopTimeout) {
+		logrus.Warnf("Workers haven't stopped after %s, in-flight reconciles may still run", workersStopTimeout)
+	}
+
+	logrus.Info("Business stopped")
 }
 
-func readConfiguration() error {
-	err := viper.ReadInConfig()
+// startTracing Start tracing when enabled and return function exporting remaining spans.
+func startTracing(tracingConfig *config.TracingConfig, serviceVersion string) func() {
+	if !tracingConfig.IsTracingEnabled() {
+		return func() {}
+	}
+
+	stop, err := tracing.Start(tracingConfig, serviceVersion)
 	if err != nil {
-		logrus.Fatalf("Fatal error reading configuration file: %v", err)
+		logrus.Fatalf("Cannot start tracing: %v", err)
 	}
 
-	var cfg config.Configuration
+	logrus.WithField("exporter", tracingConfig.GetExporter()).Info("Tracing started")
 
-	err = viper.Unmarshal(&cfg)
-	// Check error
+	return func() {
+		stopCtx, cancel := ctx.WithTimeout(ctx.Background(), tracingStopTimeout)
+		defer cancel()
+
+		err := stop(stopCtx)
+		if err != nil {
+			logrus.Warnf("Cannot export remaining spans: %v", err)
+		}
+	}
+}
+
+func run(runCtx ctx.Context) {
+	logrus.Info("Launch business")
+	// Watch persistent volumes and services until context is done
+	business.Watch(runCtx, context)
+}
+
+func getKubernetesClient(kubeConfigPath string) (*kubernetes.Clientset, error) {
+	config, err := getKubernetesRestConfig(kubeConfigPath)
 	if err != nil {
-		logrus.Fatalf("Error marshaling configuration: %v", err)
+		return nil, err
 	}
 
-	// Generate rules from rules declared in configuration
-	rules, err := rules.New(cfg.Rules)
+	logrus.WithField("host", config.Host).Info("Create Kubernetes client")
+
+	return kubernetes.NewForConfig(config)
+}
+
+func getKubernetesDynamicClient(kubeConfigPath string) (dynamic.Interface, error) {
+	config, err := getKubernetesRestConfig(kubeConfigPath)
 	if err != nil {
-		logrus.Fatal(err)
+		return nil, err
 	}
 
-	// Update context
-	context.Rules = rules
-	context.Configuration = &cfg
+	logrus.WithField("host", config.Host).Info("Create Kubernetes dynamic client")
 
-	// Check if the configuration is valid
-	return cfg.IsValid()
+	return dynamic.NewForConfig(config)
 }
 
-func getKubernetesClient() (*kubernetes.Clientset, error) {
-	var config *rest.Config
+// getRulesGetter Get rules getter of commands, tagging policies are listed when enabled.
+func getRulesGetter(snapshot *business.Snapshot) (business.RulesGetter, error) {
+	if !snapshot.Configuration.TaggingPolicies {
+		return business.NewRulesGetter(nil, snapshot), nil
+	}
+
+	dynamicClient, err := getKubernetesDynamicClient(snapshot.Configuration.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
 
-	kubeConfigPath := context.Configuration.Kubeconfig
+	return business.NewRulesGetter(dynamicClient, snapshot), nil
+}
 
+func getKubernetesRestConfig(kubeConfigPath string) (*rest.Config, error) {
 	exists, err := utils.Exists(kubeConfigPath)
 	if err != nil {
 		return nil, err
 	}
 
+	var restConfig *rest.Config
+
 	if exists {
 		logrus.WithFields(logrus.Fields{"config": kubeConfigPath}).Info("Using out of cluster config")
 
-		config, err = clientcmd.BuildConfigFromFlags("", kubeConfigPath)
+		restConfig, err = clientcmd.BuildConfigFromFlags("", kubeConfigPath)
 	} else {
 		logrus.Info("Using in cluster config")
 
-		config, err = rest.InClusterConfig()
+		restConfig, err = rest.InClusterConfig()
 	}
-	// Check error
+
 	if err != nil {
 		return nil, err
 	}
 
-	logrus.WithField("host", config.Host).Info("Create Kubernetes client")
+	// Requests done during traced runs are in their traces
+	restConfig.Wrap(tracing.WrapTransport)
 
-	return kubernetes.NewForConfig(config)
+	return restConfig, nil
 }
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+// orphansHandler Last cloud sweep report endpoint listing cloud resources without Kubernetes object in JSON.
+func orphansHandler(w http.ResponseWriter, r *http.Request) {
+	// Only leader runs cloud sweeps
+	if !context.IsLeader() {
+		http.Error(w, "instance isn't the leader, orphans report is only available on the leader", http.StatusServiceUnavailable)
+
+		return
+	}
+
+	report := context.GetSweepReport()
+	if report == nil {
+		http.Error(w, "no cloud sweep done yet", http.StatusServiceUnavailable)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	err := json.NewEncoder(w).Encode(report)
+	if err != nil {
+		logrus.Errorf("Cannot write orphans response: %v", err)
+	}
+}
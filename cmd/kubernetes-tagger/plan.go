@@ -0,0 +1,189 @@
+package main
+
+import (
+	ctx "context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/business"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+)
+
+// Plan output formats.
+const (
+	tableOutputFormat    = "table"
+	jsonOutputFormat     = "json"
+	markdownOutputFormat = "markdown"
+)
+
+// runPlan Print tag changes that a candidate configuration would apply on live cluster objects.
+func runPlan(args []string) int {
+	flags := pflag.NewFlagSet("plan", pflag.ContinueOnError)
+	configPath := flags.String("config", "", "Candidate configuration file path")
+	kubeConfigPath := flags.String("kubeconfig", "", "Kubernetes configuration file path (default to the configuration one)")
+	output := flags.StringP("output", "o", tableOutputFormat, "Output format (table, json or markdown)")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return exitCodeError
+	}
+
+	if *configPath == "" {
+		fmt.Fprintln(os.Stderr, "--config flag is required")
+
+		return exitCodeError
+	}
+
+	// Only errors must be displayed to keep output clean
+	logrus.SetLevel(logrus.ErrorLevel)
+
+	cfg, err := readConfigurationFile(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR %v\n", err)
+
+		return exitCodeError
+	}
+
+	snapshot, err := business.NewSnapshot(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR %v\n", err)
+
+		return exitCodeError
+	}
+
+	if *kubeConfigPath != "" {
+		cfg.Kubeconfig = *kubeConfigPath
+	}
+
+	kubeClient, err := getKubernetesClient(cfg.Kubeconfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot create a Kubernetes client: %v\n", err)
+
+		return exitCodeError
+	}
+
+	getRules, err := getRulesGetter(snapshot)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot create a Kubernetes dynamic client: %v\n", err)
+
+		return exitCodeError
+	}
+
+	plans, err := business.Plan(ctx.Background(), kubeClient, snapshot, getRules)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR %v\n", err)
+
+		return exitCodeError
+	}
+
+	err = printPlans(plans, *output, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR %v\n", err)
+
+		return exitCodeError
+	}
+
+	return getPlansExitCode(plans)
+}
+
+func getPlansExitCode(plans []*business.ResourcePlan) int {
+	exitCode := exitCodeSuccess
+
+	for _, plan := range plans {
+		if plan.Error != "" {
+			return exitCodeError
+		}
+
+		if len(plan.Changes) != 0 {
+			exitCode = exitCodeChangesPending
+		}
+	}
+
+	return exitCode
+}
+
+func printPlans(plans []*business.ResourcePlan, output string, out io.Writer) error {
+	// Only keep resources with changes or errors
+	filtered := make([]*business.ResourcePlan, 0)
+
+	for _, plan := range plans {
+		if plan.Error != "" || len(plan.Changes) != 0 {
+			filtered = append(filtered, plan)
+		}
+	}
+
+	switch output {
+	case jsonOutputFormat:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(filtered)
+	case markdownOutputFormat:
+		printMarkdownPlans(filtered, out)
+
+		return nil
+	case tableOutputFormat:
+		return printTablePlans(filtered, out)
+	default:
+		return fmt.Errorf("output format not supported: %s", output)
+	}
+}
+
+func printTablePlans(plans []*business.ResourcePlan, out io.Writer) error {
+	if len(plans) == 0 {
+		fmt.Fprintln(out, "No changes")
+
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0) // nolint: gomnd // Padding
+
+	fmt.Fprintln(w, "RESOURCE\tTYPE\tACTION\tTAG\tOLD VALUE\tNEW VALUE")
+
+	for _, plan := range plans {
+		resourceType := plan.Platform + "/" + plan.Type
+
+		if plan.Error != "" {
+			fmt.Fprintf(w, "%s\t%s\terror\t%s\t\t\n", plan.Reference, resourceType, plan.Error)
+
+			continue
+		}
+
+		for _, change := range plan.Changes {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				plan.Reference, resourceType, change.Action, change.Key, change.OldValue, change.NewValue)
+		}
+	}
+
+	return w.Flush()
+}
+
+func printMarkdownPlans(plans []*business.ResourcePlan, out io.Writer) {
+	if len(plans) == 0 {
+		fmt.Fprintln(out, "No changes")
+
+		return
+	}
+
+	fmt.Fprintln(out, "| Resource | Type | Action | Tag | Old value | New value |")
+	fmt.Fprintln(out, "| -------- | ---- | ------ | --- | --------- | --------- |")
+
+	for _, plan := range plans {
+		resourceType := plan.Platform + "/" + plan.Type
+
+		if plan.Error != "" {
+			fmt.Fprintf(out, "| `%s` | %s | error | %s | | |\n", plan.Reference, resourceType, plan.Error)
+
+			continue
+		}
+
+		for _, change := range plan.Changes {
+			fmt.Fprintf(out, "| `%s` | %s | %s | `%s` | `%s` | `%s` |\n",
+				plan.Reference, resourceType, change.Action, change.Key, change.OldValue, change.NewValue)
+		}
+	}
+}
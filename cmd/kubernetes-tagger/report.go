@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/business"
+	"github.com/sirupsen/logrus"
+)
+
+// Report output formats.
+const csvOutputFormat = "csv"
+
+// reportHandler Compliance report endpoint listing required tags violations in JSON or CSV.
+func reportHandler(w http.ResponseWriter, r *http.Request) {
+	// Only leader manages resources and knows their compliance
+	if !context.IsLeader() {
+		http.Error(w, "instance isn't the leader, compliance report is only available on the leader", http.StatusServiceUnavailable)
+
+		return
+	}
+
+	report := context.GetComplianceReport()
+
+	var err error
+
+	switch r.URL.Query().Get("format") {
+	case "", jsonOutputFormat:
+		w.Header().Set("Content-Type", "application/json")
+
+		err = json.NewEncoder(w).Encode(report)
+	case csvOutputFormat:
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", "attachment; filename=\"compliance-report.csv\"")
+
+		err = writeCSVReport(report, w)
+	default:
+		http.Error(w, "format not supported, must be json or csv", http.StatusBadRequest)
+
+		return
+	}
+
+	if err != nil {
+		logrus.Errorf("Cannot write report response: %v", err)
+	}
+}
+
+// writeCSVReport Write compliance report violations as CSV.
+func writeCSVReport(report *business.ComplianceReport, out io.Writer) error {
+	writer := csv.NewWriter(out)
+
+	err := writer.Write([]string{"reference", "type", "platform", "key", "reason", "value", "pattern", "checkTime"})
+	if err != nil {
+		return err
+	}
+
+	for _, violation := range report.Violations {
+		err = writer.Write([]string{
+			violation.Reference,
+			violation.Type,
+			violation.Platform,
+			violation.Key,
+			string(violation.Reason),
+			violation.Value,
+			violation.Pattern,
+			violation.CheckTime.Format(time.RFC3339),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
@@ -4,17 +4,35 @@ import (
 	"net/http"
 
 	"github.com/dimiro1/health"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/business"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
 )
 
 func serve() {
 	// Variables
-	address := context.Configuration.Address
+	address := context.GetSnapshot().Configuration.Address
+	// Liveness only checks that the server answers
+	liveHandler := health.NewHandler()
+	// Readiness checks what is needed to manage tags
+	readyHandler := health.NewHandler()
+	readyHandler.AddChecker(business.ConfigurationCheckerName, context.ConfigurationChecker())
+	readyHandler.AddChecker(business.InformersCheckerName, context.InformersChecker())
+	readyHandler.AddChecker(business.ProviderCredentialsCheckerName, context.ProviderCredentialsChecker())
+	// Health gives all status
 	healthHandler := health.NewHandler()
+	healthHandler.AddChecker(business.ConfigurationCheckerName, context.ConfigurationChecker())
+	healthHandler.AddChecker(business.LeaderCheckerName, context.LeaderChecker())
+	healthHandler.AddChecker(business.InformersCheckerName, context.InformersChecker())
+	healthHandler.AddChecker(business.ProviderCredentialsCheckerName, context.ProviderCredentialsChecker())
 	// Listen path
 	http.Handle("/metrics", promhttp.Handler())
 	http.Handle("/health", healthHandler)
+	http.Handle("/live", liveHandler)
+	http.Handle("/ready", readyHandler)
+	http.HandleFunc("/report", reportHandler)
+	http.HandleFunc("/orphans", orphansHandler)
+	http.HandleFunc("/debug/explain", explainHandler)
 	// Listen
 	err := http.ListenAndServe(address, nil)
 	if err != nil {
@@ -0,0 +1,19 @@
+package main
+
+// Exit codes used by subcommands.
+const (
+	exitCodeSuccess        = 0
+	exitCodeError          = 1
+	exitCodeChangesPending = 2
+)
+
+// subcommand Subcommand run with its arguments that returns the process exit code.
+type subcommand func(args []string) int
+
+// subcommands Available subcommands by name.
+var subcommands = map[string]subcommand{
+	"validate":   runValidate,
+	"plan":       runPlan,
+	"explain":    runExplain,
+	"test-rules": runTestRules,
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/business"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/ruletest"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+)
+
+// runTestRules Run rules test suite against rules of a configuration file.
+func runTestRules(args []string) int {
+	flags := pflag.NewFlagSet("test-rules", pflag.ContinueOnError)
+	configPath := flags.String("config", "", "Configuration file path with rules to test")
+	suitePath := flags.String("suite", "", "Test suite file path")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return exitCodeError
+	}
+
+	if *configPath == "" || *suitePath == "" {
+		fmt.Fprintln(os.Stderr, "--config and --suite flags are required")
+
+		return exitCodeError
+	}
+
+	// Only errors must be displayed to keep output clean
+	logrus.SetLevel(logrus.ErrorLevel)
+
+	cfg, err := readConfigurationFile(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR %v\n", err)
+
+		return exitCodeError
+	}
+
+	snapshot, err := business.NewSnapshot(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR %v\n", err)
+
+		return exitCodeError
+	}
+
+	suite, err := ruletest.LoadSuite(*suitePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR %v\n", err)
+
+		return exitCodeError
+	}
+
+	// Policies are Kubernetes objects, suites run without a cluster
+	if cfg.TaggingPolicies {
+		fmt.Fprintln(os.Stdout, "Tagging policies rules aren't tested, only configuration rules are")
+	}
+
+	failed := 0
+
+	for _, result := range ruletest.Run(suite, cfg.Provider, snapshot.Rules) {
+		if result.Passed {
+			fmt.Fprintf(os.Stdout, "PASS %s\n", result.Name)
+
+			continue
+		}
+
+		failed++
+
+		fmt.Fprintf(os.Stdout, "FAIL %s\n", result.Name)
+
+		if result.Error != "" {
+			fmt.Fprintf(os.Stdout, "    error: %s\n", result.Error)
+		}
+
+		for _, diff := range result.Diffs {
+			fmt.Fprintf(os.Stdout, "    %s\n", diff)
+		}
+	}
+
+	fmt.Fprintf(os.Stdout, "%d test(s), %d failed\n", len(suite.Tests), failed)
+
+	if failed != 0 {
+		return exitCodeError
+	}
+
+	return exitCodeSuccess
+}
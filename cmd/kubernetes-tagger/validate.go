@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/resources"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/rules"
+	"github.com/spf13/pflag"
+)
+
+// runValidate Validate a configuration file and its rules.
+func runValidate(args []string) int {
+	flags := pflag.NewFlagSet("validate", pflag.ContinueOnError)
+	configPath := flags.String("config", "", "Configuration file path to validate")
+	strict := flags.Bool("strict", false, "Consider warnings as errors")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return exitCodeError
+	}
+
+	if *configPath == "" {
+		fmt.Fprintln(os.Stderr, "--config flag is required")
+
+		return exitCodeError
+	}
+
+	cfg, err := readConfigurationFile(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR %v\n", err)
+
+		return exitCodeError
+	}
+
+	errorsCount, warningsCount := validateConfiguration(cfg, os.Stdout)
+
+	fmt.Fprintf(os.Stdout, "%d error(s), %d warning(s)\n", errorsCount, warningsCount)
+
+	if errorsCount != 0 || (*strict && warningsCount != 0) {
+		return exitCodeError
+	}
+
+	return exitCodeSuccess
+}
+
+// validateConfiguration Write all configuration errors and warnings and return their count.
+func validateConfiguration(cfg *config.Configuration, out io.Writer) (int, int) {
+	errorsCount := 0
+	warningsCount := 0
+
+	// Check configuration
+	for _, cfgErr := range cfg.Validate() {
+		errorsCount++
+
+		fmt.Fprintf(out, "ERROR configuration: %v\n", cfgErr)
+	}
+
+	// Check rules
+	for _, ruleErr := range rules.Validate(cfg.Rules) {
+		errorsCount++
+
+		fmt.Fprintf(out, "ERROR %v\n", ruleErr)
+	}
+
+	// Check queries against data structure
+	for i, ruleConfig := range cfg.Rules {
+		if ruleConfig == nil {
+			continue
+		}
+
+		if ruleConfig.Query != "" && !resources.IsKnownQuery(ruleConfig.Query) {
+			warningsCount++
+
+			fmt.Fprintf(out, "WARNING rules[%d].query: query \"%s\" can never match the data structure\n", i, ruleConfig.Query)
+		}
+
+		for j, conditionConfig := range ruleConfig.When {
+			if conditionConfig.Condition != "" && !resources.IsKnownQuery(conditionConfig.Condition) {
+				warningsCount++
+
+				fmt.Fprintf(out, "WARNING rules[%d].when[%d].condition: query \"%s\" can never match the data structure\n",
+					i, j, conditionConfig.Condition)
+			}
+		}
+	}
+
+	return errorsCount, warningsCount
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/webhook"
+	"github.com/sirupsen/logrus"
+	admissionv1 "k8s.io/api/admission/v1"
+)
+
+// Default admission webhook listener address.
+const defaultWebhookAddress = ":8443"
+
+// Admission webhook listener timeouts, slow clients mustn't hold connections.
+// API server calls webhooks with a 10s timeout by default.
+const (
+	webhookReadHeaderTimeout = 5 * time.Second
+	webhookReadTimeout       = 10 * time.Second
+	webhookWriteTimeout      = 15 * time.Second
+	webhookIdleTimeout       = 60 * time.Second
+)
+
+// serveWebhook Start admission webhook listener when enabled.
+// Webhook configuration changes need a restart.
+func serveWebhook() {
+	webhookConfig := context.GetSnapshot().Configuration.Webhook
+	if webhookConfig == nil || !webhookConfig.Enabled {
+		return
+	}
+
+	address := webhookConfig.Address
+	if address == "" {
+		address = defaultWebhookAddress
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/validate", webhookHandler)
+
+	// Certificate is reloaded when mounted secret changes
+	certificateLoader := webhook.NewCertificateLoader(webhookConfig.CertFile, webhookConfig.KeyFile)
+	server := &http.Server{
+		Addr:              address,
+		Handler:           mux,
+		TLSConfig:         &tls.Config{GetCertificate: certificateLoader.GetCertificate, MinVersion: tls.VersionTLS12},
+		ReadHeaderTimeout: webhookReadHeaderTimeout,
+		ReadTimeout:       webhookReadTimeout,
+		WriteTimeout:      webhookWriteTimeout,
+		IdleTimeout:       webhookIdleTimeout,
+	}
+
+	logrus.Info("Admission webhook listening on address " + address)
+
+	err := server.ListenAndServeTLS("", "")
+	if err != nil {
+		logrus.Fatalf("Failed to start admission webhook server: %v", err)
+	}
+}
+
+// webhookHandler Validate admission review with rules applied to objects.
+func webhookHandler(w http.ResponseWriter, r *http.Request) {
+	var review admissionv1.AdmissionReview
+
+	err := json.NewDecoder(r.Body).Decode(&review)
+	if err != nil || review.Request == nil {
+		http.Error(w, "invalid admission review", http.StatusBadRequest)
+
+		return
+	}
+
+	// Rules of configuration and tagging policies are checked like reconcile does
+	review.Response = webhook.Review(r.Context(), review.Request, context, context.GetSnapshot().Configuration)
+	review.Request = nil
+
+	w.Header().Set("Content-Type", "application/json")
+
+	err = json.NewEncoder(w).Encode(review)
+	if err != nil {
+		logrus.Errorf("Cannot write admission review response: %v", err)
+	}
+}
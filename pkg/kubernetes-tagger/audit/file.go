@@ -0,0 +1,111 @@
+package audit
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// auditFileMode Audit files mode, records may contain sensitive values.
+const auditFileMode = 0600
+
+// rotatingFile File rotated when its size would exceed the maximum size.
+// Rotated files are named <path>.1 (most recent) to <path>.<maxBackups>, <path>.1 is always kept.
+type rotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+func openRotatingFile(path string, maxSize int64, maxBackups int) (*rotatingFile, error) {
+	rf := &rotatingFile{path: path, maxSize: maxSize, maxBackups: maxBackups}
+
+	err := rf.open()
+	if err != nil {
+		return nil, err
+	}
+
+	return rf, nil
+}
+
+func (rf *rotatingFile) open() error {
+	file, err := os.OpenFile(rf.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, auditFileMode)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+
+		return err
+	}
+
+	rf.file = file
+	rf.size = info.Size()
+
+	return nil
+}
+
+// Write Write data, file is rotated before when data doesn't fit in it.
+// Data bigger than maximum size is written in an empty file.
+// When rotation fails, data is still written in the current file and rotation is retried on next write.
+func (rf *rotatingFile) Write(data []byte) (int, error) {
+	var rotateErr error
+
+	if rf.size > 0 && rf.size+int64(len(data)) > rf.maxSize {
+		rotateErr = rf.rotate()
+	}
+
+	n, err := rf.file.Write(data)
+	rf.size += int64(n)
+
+	if err == nil && rotateErr != nil {
+		err = fmt.Errorf("data written without rotating file: %w", rotateErr)
+	}
+
+	return n, err
+}
+
+// rotate Rename current file and its backups then open a new file.
+// Current file is only closed once a new one is opened to keep writing in it on failure.
+func (rf *rotatingFile) rotate() error {
+	// Shift backups, the oldest one is overwritten
+	for i := rf.maxBackups - 1; i > 0; i-- {
+		err := os.Rename(rf.backupPath(i), rf.backupPath(i+1))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	// Opened file follows the rename
+	err := os.Rename(rf.path, rf.backupPath(1))
+	if err != nil {
+		return err
+	}
+
+	previous := rf.file
+
+	err = rf.open()
+	if err != nil {
+		// Current file gets its name back, backups are already shifted
+		rf.file = previous
+		_ = os.Rename(rf.backupPath(1), rf.path)
+
+		return err
+	}
+
+	return previous.Close()
+}
+
+func (rf *rotatingFile) backupPath(index int) string {
+	return rf.path + "." + strconv.Itoa(index)
+}
+
+// Close Close current file.
+func (rf *rotatingFile) Close() error {
+	return rf.file.Close()
+}
@@ -0,0 +1,73 @@
+package audit
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotatingFile_Write(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	// Existing content is kept
+	assert.NoError(t, ioutil.WriteFile(path, []byte("0000\n"), 0600))
+
+	rf, err := openRotatingFile(path, 10, 2)
+	assert.NoError(t, err)
+
+	for _, line := range []string{"1111\n", "2222\n", "3333\n", "4444\n", "5555\n", "666666666666\n"} {
+		_, err = rf.Write([]byte(line))
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, rf.Close())
+
+	readFile := func(name string) string {
+		content, err := ioutil.ReadFile(name)
+		assert.NoError(t, err)
+
+		return string(content)
+	}
+
+	// Oldest backups are removed
+	assert.Equal(t, "666666666666\n", readFile(path))
+	assert.Equal(t, "4444\n5555\n", readFile(path+".1"))
+	assert.Equal(t, "2222\n3333\n", readFile(path+".2"))
+
+	_, err = os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRotatingFile_WriteRotateError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	rf, err := openRotatingFile(path, 10, 1)
+	assert.NoError(t, err)
+
+	_, err = rf.Write([]byte("1111\n"))
+	assert.NoError(t, err)
+
+	// Backup path can't be replaced by a file
+	assert.NoError(t, os.MkdirAll(filepath.Join(path+".1", "dir"), 0700))
+
+	_, err = rf.Write([]byte("222222\n"))
+	assert.Error(t, err)
+
+	// Current file is still written and rotation is retried
+	assert.NoError(t, os.RemoveAll(path+".1"))
+
+	_, err = rf.Write([]byte("3333\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, rf.Close())
+
+	content, err := ioutil.ReadFile(path + ".1")
+	assert.NoError(t, err)
+	assert.Equal(t, "1111\n222222\n", string(content))
+
+	content, err = ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "3333\n", string(content))
+}
@@ -0,0 +1,139 @@
+package audit
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/tags"
+)
+
+// RedactedValue Value replacing redacted tag values.
+const RedactedValue = "[REDACTED]"
+
+// Source Origin of a tag mutation.
+type Source string
+
+// Tag mutation sources.
+const (
+	SourceReconcile = Source("reconcile")
+	SourceOnDelete  = Source("onDelete")
+	SourceSweep     = Source("sweep")
+)
+
+// Outcome Result of a tag mutation.
+type Outcome string
+
+// Tag mutation outcomes.
+const (
+	OutcomeSuccess = Outcome("success")
+	OutcomeFailure = Outcome("failure")
+)
+
+// Record Audit record of tag changes on a cloud resource.
+type Record struct {
+	Timestamp    time.Time `json:"timestamp"`
+	Source       Source    `json:"source"`
+	ResourceType string    `json:"resourceType"`
+	Platform     string    `json:"platform"`
+	ResourceID   string    `json:"resourceId"`
+	// Kubernetes object reference like pv/<name> or svc/<namespace>/<name>, empty for orphan resources
+	ObjectReference string    `json:"objectReference,omitempty"`
+	Changes         []*Change `json:"changes"`
+	// Dry run status, false as changes are always applied on cloud resources
+	DryRun  bool    `json:"dryRun"`
+	Outcome Outcome `json:"outcome"`
+	Error   string  `json:"error,omitempty"`
+}
+
+// Change Tag change with rules responsible for it.
+type Change struct {
+	*tags.Change
+	// Rules responsible for the change, empty for on delete actions and sweeps
+	Rules []*Rule `json:"rules,omitempty"`
+}
+
+// Rule Rule reference in the evaluated rules list.
+// Configuration rules are first, then tagging policies rules.
+type Rule struct {
+	Index int    `json:"index"`
+	Name  string `json:"name,omitempty"`
+	// Tagging policy key, empty for configuration rules
+	Policy string `json:"policy,omitempty"`
+}
+
+// Sink Destination of audit records.
+type Sink interface {
+	Write(record *Record) error
+	Close() error
+}
+
+// Logger Write audit records with redacted values to a sink.
+type Logger struct {
+	sink             Sink
+	redactKeyRegexps []*regexp.Regexp
+}
+
+// NewLogger Create a logger with the sink from configuration.
+func NewLogger(auditConfig *config.AuditConfig) (*Logger, error) {
+	redactKeyRegexps, err := auditConfig.GetRedactKeyRegexps()
+	if err != nil {
+		return nil, err
+	}
+
+	sink, err := NewSink(auditConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Logger{sink: sink, redactKeyRegexps: redactKeyRegexps}, nil
+}
+
+// Log Write record to sink once sensitive values are redacted.
+func (l *Logger) Log(record *Record) error {
+	return l.sink.Write(Redact(record, l.redactKeyRegexps))
+}
+
+// Close Close sink.
+func (l *Logger) Close() error {
+	return l.sink.Close()
+}
+
+// Redact Get a copy of record with values of tags matching one of the regular expressions redacted.
+func Redact(record *Record, redactKeyRegexps []*regexp.Regexp) *Record {
+	result := *record
+	result.Changes = make([]*Change, 0, len(record.Changes))
+
+	for _, change := range record.Changes {
+		if !IsRedacted(change.Key, redactKeyRegexps) {
+			result.Changes = append(result.Changes, change)
+
+			continue
+		}
+
+		tagChange := *change.Change
+
+		if tagChange.OldValue != "" {
+			tagChange.OldValue = RedactedValue
+		}
+
+		if tagChange.NewValue != "" {
+			tagChange.NewValue = RedactedValue
+		}
+
+		result.Changes = append(result.Changes, &Change{Change: &tagChange, Rules: change.Rules})
+	}
+
+	return &result
+}
+
+// IsRedacted Check if values of tag key must be redacted.
+func IsRedacted(key string, redactKeyRegexps []*regexp.Regexp) bool {
+	for _, re := range redactKeyRegexps {
+		if re.MatchString(key) {
+			return true
+		}
+	}
+
+	return false
+}
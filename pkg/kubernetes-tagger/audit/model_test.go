@@ -0,0 +1,82 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/tags"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRecord() *Record {
+	return &Record{
+		Timestamp:       time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		Source:          SourceReconcile,
+		ResourceType:    "volume",
+		Platform:        "aws",
+		ResourceID:      "vol-1",
+		ObjectReference: "pv/pv1",
+		Changes: []*Change{
+			{
+				Change: &tags.Change{Action: tags.ChangeActionUpdate, Key: "db-password", OldValue: "old", NewValue: "new"},
+				Rules:  []*Rule{{Index: 1, Name: "password"}},
+			},
+			{Change: &tags.Change{Action: tags.ChangeActionDelete, Key: "team", OldValue: "a"}},
+		},
+		Outcome: OutcomeSuccess,
+	}
+}
+
+func TestRedact(t *testing.T) {
+	record := newTestRecord()
+
+	redacted := Redact(record, []*regexp.Regexp{regexp.MustCompile("password")})
+	assert.Equal(t, &Change{
+		Change: &tags.Change{Action: tags.ChangeActionUpdate, Key: "db-password", OldValue: RedactedValue, NewValue: RedactedValue},
+		Rules:  []*Rule{{Index: 1, Name: "password"}},
+	}, redacted.Changes[0])
+	assert.Equal(t, record.Changes[1], redacted.Changes[1])
+	assert.Equal(t, "vol-1", redacted.ResourceID)
+
+	// Original record is unchanged
+	assert.Equal(t, "old", record.Changes[0].OldValue)
+
+	// Empty values stay empty
+	record.Changes[1].Key = "password"
+	redacted = Redact(record, []*regexp.Regexp{regexp.MustCompile("password")})
+	assert.Equal(t, RedactedValue, redacted.Changes[1].OldValue)
+	assert.Equal(t, "", redacted.Changes[1].NewValue)
+}
+
+func TestLogger_Log(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	logger := &Logger{sink: NewWriterSink(buffer), redactKeyRegexps: []*regexp.Regexp{regexp.MustCompile("(?i)PASSWORD")}}
+
+	assert.NoError(t, logger.Log(newTestRecord()))
+	assert.NoError(t, logger.Log(newTestRecord()))
+	assert.NoError(t, logger.Close())
+
+	lines := bytes.Split(bytes.TrimSpace(buffer.Bytes()), []byte("\n"))
+	assert.Len(t, lines, 2)
+
+	// One JSON object per line with flat changes
+	var decoded map[string]interface{}
+
+	assert.NoError(t, json.Unmarshal(lines[0], &decoded))
+	assert.Equal(t, "2021-01-01T00:00:00Z", decoded["timestamp"])
+	assert.Equal(t, "reconcile", decoded["source"])
+	assert.Equal(t, "vol-1", decoded["resourceId"])
+	assert.Equal(t, "pv/pv1", decoded["objectReference"])
+	assert.Equal(t, false, decoded["dryRun"])
+	assert.Equal(t, "success", decoded["outcome"])
+	assert.Equal(t, map[string]interface{}{
+		"action":   "update",
+		"key":      "db-password",
+		"oldValue": RedactedValue,
+		"newValue": RedactedValue,
+		"rules":    []interface{}{map[string]interface{}{"index": float64(1), "name": "password"}},
+	}, decoded["changes"].([]interface{})[0])
+}
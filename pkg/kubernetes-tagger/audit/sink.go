@@ -0,0 +1,139 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+)
+
+// ErrWebhookStatus Audit webhook answered with an error status error.
+var ErrWebhookStatus = errors.New("audit webhook answered with an error status")
+
+// megabyte Bytes in a megabyte, used for file maximum size.
+const megabyte = 1024 * 1024
+
+// NewSink Create sink from configuration.
+func NewSink(auditConfig *config.AuditConfig) (Sink, error) {
+	switch auditConfig.GetSink() {
+	case config.AuditSinkStdout:
+		return NewWriterSink(os.Stdout), nil
+	case config.AuditSinkFile:
+		file, err := openRotatingFile(
+			auditConfig.File.Path,
+			int64(auditConfig.File.GetMaxSize())*megabyte,
+			auditConfig.File.GetMaxBackups(),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		return &writerSink{writer: file, closer: file}, nil
+	case config.AuditSinkWebhook:
+		timeout, err := auditConfig.Webhook.GetTimeout()
+		if err != nil {
+			return nil, err
+		}
+
+		return &webhookSink{
+			client:  &http.Client{Timeout: timeout},
+			url:     auditConfig.Webhook.URL,
+			headers: auditConfig.Webhook.Headers,
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", config.ErrAuditSinkNotSupported, auditConfig.Sink)
+	}
+}
+
+// writerSink Sink writing one JSON record per line.
+type writerSink struct {
+	mutex  sync.Mutex
+	writer io.Writer
+	// Closed with sink when set
+	closer io.Closer
+}
+
+// NewWriterSink Create a sink writing one JSON record per line on writer.
+func NewWriterSink(writer io.Writer) Sink {
+	return &writerSink{writer: writer}
+}
+
+func (ws *writerSink) Write(record *Record) error {
+	// Record is encoded before writing to never write partial lines
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+
+	_, err = ws.writer.Write(append(line, '\n'))
+
+	return err
+}
+
+func (ws *writerSink) Close() error {
+	if ws.closer == nil {
+		return nil
+	}
+
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+
+	return ws.closer.Close()
+}
+
+// webhookSink Sink sending each record with a POST request.
+type webhookSink struct {
+	client  *http.Client
+	url     string
+	headers map[string]string
+}
+
+func (whs *webhookSink) Write(record *Record) error {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, whs.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	for key, value := range whs.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := whs.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	// Read body to reuse connection
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w: %d", ErrWebhookStatus, resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (whs *webhookSink) Close() error {
+	whs.client.CloseIdleConnections()
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package audit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSink(t *testing.T) {
+	sink, err := NewSink(&config.AuditConfig{})
+	assert.NoError(t, err)
+	assert.IsType(t, &writerSink{}, sink)
+
+	sink, err = NewSink(&config.AuditConfig{Sink: config.AuditSinkFile, File: &config.AuditFileConfig{Path: filepath.Join(t.TempDir(), "audit.log")}})
+	assert.NoError(t, err)
+	assert.IsType(t, &writerSink{}, sink)
+	assert.NoError(t, sink.Close())
+
+	_, err = NewSink(&config.AuditConfig{Sink: config.AuditSinkFile, File: &config.AuditFileConfig{Path: t.TempDir()}})
+	assert.Error(t, err)
+
+	sink, err = NewSink(&config.AuditConfig{Sink: config.AuditSinkWebhook, Webhook: &config.AuditWebhookConfig{URL: "http://localhost"}})
+	assert.NoError(t, err)
+	assert.IsType(t, &webhookSink{}, sink)
+
+	_, err = NewSink(&config.AuditConfig{Sink: "syslog"})
+	assert.ErrorIs(t, err, config.ErrAuditSinkNotSupported)
+}
+
+func TestWebhookSink_Write(t *testing.T) {
+	var received *Record
+
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		received = &Record{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(received))
+		w.WriteHeader(status)
+	}))
+
+	defer server.Close()
+
+	sink, err := NewSink(&config.AuditConfig{
+		Sink:    config.AuditSinkWebhook,
+		Webhook: &config.AuditWebhookConfig{URL: server.URL, Headers: map[string]string{"Authorization": "Bearer token"}},
+	})
+	assert.NoError(t, err)
+
+	record := newTestRecord()
+	assert.NoError(t, sink.Write(record))
+	assert.Equal(t, record, received)
+
+	status = http.StatusInternalServerError
+	assert.ErrorIs(t, sink.Write(record), ErrWebhookStatus)
+	assert.NoError(t, sink.Close())
+}
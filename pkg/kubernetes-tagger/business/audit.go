@@ -0,0 +1,155 @@
+package business
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/audit"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/metrics"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/resources"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/rules"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/tags"
+	"github.com/sirupsen/logrus"
+)
+
+// manageTags Apply delta on resource and write an audit record of its changes.
+func (context *Context) manageTags(
+	resource resources.Resource,
+	delta *tags.TagDelta,
+	record *audit.Record,
+	cfg *config.Configuration,
+) error {
+	err := resource.ManageTags(delta)
+
+	// Only mutations are audited
+	if len(record.Changes) != 0 {
+		completeAuditRecord(record, err)
+		context.writeAudit(cfg, record)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// Count tag changes
+	for _, tag := range delta.AddList {
+		metrics.TagChanges.WithLabelValues(string(tags.ChangeActionAdd), tag.Key).Inc()
+	}
+
+	for _, tag := range delta.DeleteList {
+		metrics.TagChanges.WithLabelValues(string(tags.ChangeActionDelete), tag.Key).Inc()
+	}
+
+	return nil
+}
+
+// newAuditRecord Create audit record of changes on resource of a Kubernetes object.
+func newAuditRecord(source audit.Source, reference string, resource resources.Resource, changes []*audit.Change) *audit.Record {
+	return &audit.Record{
+		Timestamp:       time.Now(),
+		Source:          source,
+		ResourceType:    resource.Type(),
+		Platform:        resource.Platform(),
+		ResourceID:      resource.ID(),
+		ObjectReference: reference,
+		Changes:         changes,
+	}
+}
+
+// completeAuditRecord Set outcome of audit record.
+func completeAuditRecord(record *audit.Record, err error) {
+	if err != nil {
+		record.Outcome = audit.OutcomeFailure
+		record.Error = err.Error()
+
+		return
+	}
+
+	record.Outcome = audit.OutcomeSuccess
+}
+
+// getAuditChanges Get audit changes with rules responsible for them from rules evaluation trace.
+// Origins give the policy key of each evaluated rule, changes don't have rules when trace is nil.
+func getAuditChanges(changes []*tags.Change, trace *rules.Trace, origins []string) []*audit.Change {
+	result := make([]*audit.Change, 0, len(changes))
+
+	for _, change := range changes {
+		auditChange := &audit.Change{Change: change}
+		result = append(result, auditChange)
+
+		if trace == nil {
+			continue
+		}
+
+		ruleResult := rules.TraceResultAdd
+		if change.Action == tags.ChangeActionDelete {
+			ruleResult = rules.TraceResultDelete
+		}
+
+		for _, ruleTrace := range trace.Rules {
+			if ruleTrace.Tag != change.Key || ruleTrace.Result != ruleResult {
+				continue
+			}
+
+			auditRule := &audit.Rule{Index: ruleTrace.Index, Name: ruleTrace.Name}
+			if ruleTrace.Index < len(origins) {
+				auditRule.Policy = origins[ruleTrace.Index]
+			}
+
+			auditChange.Rules = append(auditChange.Rules, auditRule)
+		}
+	}
+
+	return result
+}
+
+// writeAudit Write audit record when audit is enabled.
+// Errors are only logged because tags are already changed.
+func (context *Context) writeAudit(cfg *config.Configuration, record *audit.Record) {
+	if !cfg.Audit.IsAuditEnabled() {
+		return
+	}
+
+	logger, err := context.getAuditLogger(cfg.Audit)
+	if err == nil {
+		err = logger.Log(record)
+	}
+
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"resourceId":      record.ResourceID,
+			"objectReference": record.ObjectReference,
+		}).Errorf("Cannot write audit record: %v", err)
+		metrics.AuditRecords.WithLabelValues(metrics.FailureResult).Inc()
+
+		return
+	}
+
+	metrics.AuditRecords.WithLabelValues(metrics.SuccessResult).Inc()
+}
+
+// getAuditLogger Get audit logger of the current audit configuration.
+// Logger is created again when audit configuration changes with a reload.
+func (context *Context) getAuditLogger(auditConfig *config.AuditConfig) (*audit.Logger, error) {
+	context.auditMutex.Lock()
+	defer context.auditMutex.Unlock()
+
+	if context.auditLogger != nil || context.auditLoggerError != nil {
+		if reflect.DeepEqual(context.auditConfig, auditConfig) {
+			return context.auditLogger, context.auditLoggerError
+		}
+
+		if context.auditLogger != nil {
+			err := context.auditLogger.Close()
+			if err != nil {
+				logrus.Warnf("Cannot close previous audit sink: %v", err)
+			}
+		}
+	}
+
+	context.auditConfig = auditConfig
+	context.auditLogger, context.auditLoggerError = audit.NewLogger(auditConfig)
+
+	return context.auditLogger, context.auditLoggerError
+}
@@ -0,0 +1,136 @@
+package business
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/audit"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/rules"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/tags"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeManagedResource struct {
+	fakeResource
+	deltas []*tags.TagDelta
+	err    error
+}
+
+func (fmr *fakeManagedResource) ManageTags(delta *tags.TagDelta) error {
+	fmr.deltas = append(fmr.deltas, delta)
+
+	return fmr.err
+}
+
+func readAuditRecords(t *testing.T, path string) []*audit.Record {
+	content, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+
+	records := make([]*audit.Record, 0)
+
+	for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		if line == "" {
+			continue
+		}
+
+		record := &audit.Record{}
+		assert.NoError(t, json.Unmarshal([]byte(line), record))
+		records = append(records, record)
+	}
+
+	return records
+}
+
+func Test_getAuditChanges(t *testing.T) {
+	changes := []*tags.Change{
+		{Action: tags.ChangeActionAdd, Key: "team", NewValue: "a"},
+		{Action: tags.ChangeActionDelete, Key: "old", OldValue: "value"},
+	}
+	trace := &rules.Trace{Rules: []*rules.RuleTrace{
+		{Index: 0, Tag: "team", Result: rules.TraceResultSkip},
+		{Index: 1, Name: "team", Tag: "team", Result: rules.TraceResultAdd},
+		{Index: 2, Tag: "old", Result: rules.TraceResultDelete},
+		{Index: 3, Tag: "team", Result: rules.TraceResultAdd},
+	}}
+
+	got := getAuditChanges(changes, trace, []string{"", "", "", "team-policy"})
+	assert.Equal(t, []*audit.Change{
+		{Change: changes[0], Rules: []*audit.Rule{{Index: 1, Name: "team"}, {Index: 3, Policy: "team-policy"}}},
+		{Change: changes[1], Rules: []*audit.Rule{{Index: 2}}},
+	}, got)
+
+	// On delete actions and sweeps don't have rules
+	got = getAuditChanges(changes, nil, nil)
+	assert.Equal(t, []*audit.Change{{Change: changes[0]}, {Change: changes[1]}}, got)
+}
+
+func TestContext_manageTags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	cfg := &config.Configuration{Audit: &config.AuditConfig{
+		Enabled:           true,
+		Sink:              config.AuditSinkFile,
+		File:              &config.AuditFileConfig{Path: path},
+		RedactKeyPatterns: []string{"secret"},
+	}}
+	context := &Context{}
+	resource := &fakeManagedResource{fakeResource: fakeResource{resourceType: "volume"}}
+	actualTags := []*tags.Tag{{Key: "secret", Value: "old"}}
+	delta := &tags.TagDelta{AddList: []*tags.Tag{{Key: "secret", Value: "new"}}}
+	newRecord := func(delta *tags.TagDelta) *audit.Record {
+		return newAuditRecord(audit.SourceReconcile, "pv/pv1", resource, getAuditChanges(delta.Changes(actualTags), nil, nil))
+	}
+
+	// Empty delta is applied without audit record
+	assert.NoError(t, context.manageTags(resource, &tags.TagDelta{}, newRecord(&tags.TagDelta{}), cfg))
+	assert.Len(t, resource.deltas, 1)
+
+	assert.NoError(t, context.manageTags(resource, delta, newRecord(delta), cfg))
+	assert.Len(t, resource.deltas, 2)
+
+	resource.err = errors.New("access denied")
+	assert.Error(t, context.manageTags(resource, delta, newRecord(delta), cfg))
+
+	records := readAuditRecords(t, path)
+	assert.Len(t, records, 2)
+	assert.Equal(t, "id", records[0].ResourceID)
+	assert.Equal(t, "pv/pv1", records[0].ObjectReference)
+	assert.Equal(t, audit.SourceReconcile, records[0].Source)
+	assert.Equal(t, audit.OutcomeSuccess, records[0].Outcome)
+	assert.False(t, records[0].DryRun)
+	assert.Equal(t, &tags.Change{
+		Action:   tags.ChangeActionUpdate,
+		Key:      "secret",
+		OldValue: audit.RedactedValue,
+		NewValue: audit.RedactedValue,
+	}, records[0].Changes[0].Change)
+	assert.Equal(t, audit.OutcomeFailure, records[1].Outcome)
+	assert.Equal(t, "access denied", records[1].Error)
+}
+
+func TestContext_getAuditLogger(t *testing.T) {
+	context := &Context{}
+	auditConfig := &config.AuditConfig{Enabled: true, Sink: config.AuditSinkFile, File: &config.AuditFileConfig{Path: t.TempDir()}}
+
+	// Error is kept until configuration changes
+	logger, err := context.getAuditLogger(auditConfig)
+	assert.Error(t, err)
+	assert.Nil(t, logger)
+
+	_, err2 := context.getAuditLogger(&config.AuditConfig{Enabled: true, Sink: config.AuditSinkFile, File: &config.AuditFileConfig{Path: auditConfig.File.Path}})
+	assert.Equal(t, err, err2)
+
+	auditConfig = &config.AuditConfig{Enabled: true}
+	logger, err = context.getAuditLogger(auditConfig)
+	assert.NoError(t, err)
+	assert.NotNil(t, logger)
+
+	// Logger is reused with the same configuration
+	logger2, err := context.getAuditLogger(&config.AuditConfig{Enabled: true})
+	assert.NoError(t, err)
+	assert.Same(t, logger, logger2)
+}
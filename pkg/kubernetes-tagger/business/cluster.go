@@ -0,0 +1,136 @@
+package business
+
+import (
+	ctx "context"
+	"time"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/resources"
+	"github.com/sirupsen/logrus"
+	"k8s.io/client-go/kubernetes"
+)
+
+// clusterFactsRefreshInterval Interval between two cluster facts discoveries
+// because Kubernetes version changes with cluster upgrades.
+const clusterFactsRefreshInterval = time.Hour
+
+// clusterFactsRetryInterval Interval before retrying a failed cluster facts discovery.
+const clusterFactsRetryInterval = time.Minute
+
+// Cluster facts discovery, can be replaced in tests.
+var discoverFacts = discoverClusterFacts
+
+// getClusterFacts Get cluster facts discovered with the current configuration.
+// The result is cached to avoid calling APIs on each resource run.
+// Discovery is done by one run at a time without lock, others use previous facts meanwhile
+// or wait for the first discovery.
+func (context *Context) getClusterFacts(runCtx ctx.Context, cfg *config.Configuration) *resources.ClusterFacts {
+	context.clusterMutex.Lock()
+
+	interval := clusterFactsRefreshInterval
+	if context.clusterFactsError != nil {
+		interval = clusterFactsRetryInterval
+	}
+
+	// Use last result if it is recent enough
+	if context.clusterFacts != nil && time.Since(context.clusterFactsTime) < interval {
+		facts := context.clusterFacts
+		context.clusterMutex.Unlock()
+
+		return facts
+	}
+
+	// Another run is discovering facts
+	if context.clusterDiscovery != nil {
+		done := context.clusterDiscovery
+		facts := context.clusterFacts
+		context.clusterMutex.Unlock()
+
+		if facts != nil {
+			return facts
+		}
+
+		return context.waitClusterFacts(runCtx, done)
+	}
+
+	done := make(chan struct{})
+	context.clusterDiscovery = done
+	generation := context.clusterFactsGeneration
+	context.clusterMutex.Unlock()
+
+	facts, err := discoverFacts(runCtx, context.KubernetesClient, context.getObjectGetter(runCtx), cfg)
+
+	context.clusterMutex.Lock()
+	// Facts discovered with a previous configuration are dropped
+	if generation == context.clusterFactsGeneration {
+		context.clusterFacts = facts
+		context.clusterFactsError = err
+		context.clusterFactsTime = time.Now()
+		context.clusterDiscovery = nil
+	}
+	context.clusterMutex.Unlock()
+
+	close(done)
+
+	return facts
+}
+
+// waitClusterFacts Wait for the running discovery and get its facts, empty facts are returned when run is cancelled.
+func (context *Context) waitClusterFacts(runCtx ctx.Context, done chan struct{}) *resources.ClusterFacts {
+	select {
+	case <-done:
+	case <-runCtx.Done():
+	}
+
+	context.clusterMutex.Lock()
+	defer context.clusterMutex.Unlock()
+
+	if context.clusterFacts == nil {
+		return &resources.ClusterFacts{}
+	}
+
+	return context.clusterFacts
+}
+
+// invalidateClusterFacts Forget discovered cluster facts because provider configuration may have changed.
+// A running discovery result is dropped.
+func (context *Context) invalidateClusterFacts() {
+	context.clusterMutex.Lock()
+	defer context.clusterMutex.Unlock()
+
+	context.clusterFacts = nil
+	context.clusterFactsError = nil
+	context.clusterFactsTime = time.Time{}
+	context.clusterDiscovery = nil
+	context.clusterFactsGeneration++
+}
+
+// GetClusterFacts Get cluster facts discovered with the current configuration.
+func (context *Context) GetClusterFacts(factsCtx ctx.Context) *resources.ClusterFacts {
+	return context.getClusterFacts(factsCtx, context.GetSnapshot().Configuration)
+}
+
+// discoverClusterFacts Discover cluster facts, facts found are returned with errors.
+// Kubernetes facts are discovered even when provider client can't be created.
+func discoverClusterFacts(
+	runCtx ctx.Context,
+	k8sClient kubernetes.Interface,
+	objectGetter resources.ObjectGetter,
+	cfg *config.Configuration,
+) (*resources.ClusterFacts, error) {
+	prcl, prclErr := newProviderClient(cfg)
+	if prclErr != nil {
+		logrus.Warnf("Cannot discover cluster provider facts: %v", prclErr)
+
+		prcl = nil
+	}
+
+	facts, err := resources.DiscoverClusterFacts(runCtx, k8sClient, objectGetter, prcl)
+	if err != nil {
+		logrus.Warnf("Cannot discover all cluster facts: %v", err)
+
+		return facts, err
+	}
+
+	return facts, prclErr
+}
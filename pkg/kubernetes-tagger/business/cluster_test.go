@@ -0,0 +1,87 @@
+package business
+
+import (
+	ctx "context"
+	"errors"
+	"testing"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/resources"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/kubernetes"
+)
+
+func TestContext_getClusterFacts(t *testing.T) {
+	calls := 0
+	previous := discoverFacts
+	discoverFacts = func(
+		_ ctx.Context, _ kubernetes.Interface, _ resources.ObjectGetter, _ *config.Configuration,
+	) (*resources.ClusterFacts, error) {
+		calls++
+
+		// Facts found are kept with error
+		return &resources.ClusterFacts{KubernetesVersion: "v1.23.1"}, errors.New("no provider")
+	}
+
+	defer func() { discoverFacts = previous }()
+
+	context := &Context{}
+	cfg := &config.Configuration{}
+
+	// Failed discovery is cached until retry interval
+	facts := context.getClusterFacts(ctx.Background(), cfg)
+	assert.Equal(t, "v1.23.1", facts.KubernetesVersion)
+	assert.NotNil(t, context.clusterFactsError)
+
+	context.getClusterFacts(ctx.Background(), cfg)
+	assert.Equal(t, 1, calls)
+
+	context.clusterFactsTime = context.clusterFactsTime.Add(-clusterFactsRetryInterval)
+
+	context.getClusterFacts(ctx.Background(), cfg)
+	assert.Equal(t, 2, calls)
+
+	// New configuration invalidates facts
+	context.SetSnapshot(&Snapshot{Configuration: cfg})
+
+	context.getClusterFacts(ctx.Background(), cfg)
+	assert.Equal(t, 3, calls)
+}
+
+func TestContext_getClusterFacts_Concurrent(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	previous := discoverFacts
+	discoverFacts = func(
+		_ ctx.Context, _ kubernetes.Interface, _ resources.ObjectGetter, _ *config.Configuration,
+	) (*resources.ClusterFacts, error) {
+		close(started)
+		<-release
+
+		return &resources.ClusterFacts{ID: "id"}, nil
+	}
+
+	defer func() { discoverFacts = previous }()
+
+	context := &Context{}
+	cfg := &config.Configuration{}
+
+	result := make(chan *resources.ClusterFacts)
+
+	go func() {
+		result <- context.getClusterFacts(ctx.Background(), cfg)
+	}()
+
+	<-started
+
+	// Lock isn't held during discovery, first discovery is waited until run is cancelled
+	runCtx, cancel := ctx.WithCancel(ctx.Background())
+	cancel()
+
+	assert.Equal(t, &resources.ClusterFacts{}, context.getClusterFacts(runCtx, cfg))
+
+	close(release)
+
+	assert.Equal(t, "id", (<-result).ID)
+	assert.Equal(t, "id", context.getClusterFacts(ctx.Background(), cfg).ID)
+}
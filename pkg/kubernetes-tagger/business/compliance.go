@@ -0,0 +1,171 @@
+package business
+
+import (
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/audit"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/compliance"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/metrics"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/resources"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/tags"
+	"github.com/sirupsen/logrus"
+)
+
+// complianceEntry Last compliance check result of a Kubernetes object resource.
+type complianceEntry struct {
+	resourceType string
+	platform     string
+	violations   []*compliance.Violation
+	checkTime    time.Time
+}
+
+// ComplianceReport Required tags compliance report of watched resources.
+type ComplianceReport struct {
+	GeneratedAt        time.Time              `json:"generatedAt"`
+	Resources          int                    `json:"resources"`
+	CompliantResources int                    `json:"compliantResources"`
+	Violations         []*ComplianceViolation `json:"violations"`
+}
+
+// ComplianceViolation Required tag violation on a resource.
+type ComplianceViolation struct {
+	Reference string                     `json:"reference"`
+	Type      string                     `json:"type"`
+	Platform  string                     `json:"platform"`
+	Key       string                     `json:"key"`
+	Reason    compliance.ViolationReason `json:"reason"`
+	Value     string                     `json:"value,omitempty"`
+	Pattern   string                     `json:"pattern,omitempty"`
+	CheckTime time.Time                  `json:"checkTime"`
+}
+
+// recordCompliance Check resource tags after run against required tags and store result.
+func (context *Context) recordCompliance(
+	reference string,
+	resource resources.Resource,
+	complianceConfig *config.ComplianceConfig,
+	resourceTags []*tags.Tag,
+) error {
+	// Compliance disabled
+	if complianceConfig == nil {
+		context.forgetCompliance(reference)
+
+		return nil
+	}
+
+	violations, err := compliance.Check(complianceConfig, resource.Type(), resourceTags)
+	// Check error
+	if err != nil {
+		return err
+	}
+
+	compliant := 0.0
+	if len(violations) == 0 {
+		compliant = 1
+	}
+
+	metrics.ResourceCompliance.WithLabelValues(reference, resource.Type(), resource.Platform()).Set(compliant)
+
+	context.complianceMutex.Lock()
+	defer context.complianceMutex.Unlock()
+
+	if context.compliance == nil {
+		context.compliance = make(map[string]*complianceEntry)
+	}
+
+	context.compliance[reference] = &complianceEntry{
+		resourceType: resource.Type(),
+		platform:     resource.Platform(),
+		violations:   violations,
+		checkTime:    time.Now(),
+	}
+
+	return nil
+}
+
+// forgetCompliance Remove a Kubernetes object from compliance results.
+func (context *Context) forgetCompliance(reference string) {
+	context.complianceMutex.Lock()
+	defer context.complianceMutex.Unlock()
+
+	entry, ok := context.compliance[reference]
+	if !ok {
+		return
+	}
+
+	metrics.ResourceCompliance.DeleteLabelValues(reference, entry.resourceType, entry.platform)
+	delete(context.compliance, reference)
+}
+
+// GetComplianceReport Get required tags compliance report from last resources runs.
+// Values of tags matching audit redact key patterns are redacted like in audit records.
+func (context *Context) GetComplianceReport() *ComplianceReport {
+	redactKeyRegexps, redactErr := context.getRedactKeyRegexps()
+
+	context.complianceMutex.RLock()
+	defer context.complianceMutex.RUnlock()
+
+	report := &ComplianceReport{
+		GeneratedAt: time.Now(),
+		Resources:   len(context.compliance),
+		Violations:  make([]*ComplianceViolation, 0),
+	}
+
+	for reference, entry := range context.compliance {
+		if len(entry.violations) == 0 {
+			report.CompliantResources++
+
+			continue
+		}
+
+		for _, violation := range entry.violations {
+			value := violation.Value
+			// Hide all values when redact patterns can't be used
+			if value != "" && (redactErr != nil || audit.IsRedacted(violation.Key, redactKeyRegexps)) {
+				value = audit.RedactedValue
+			}
+
+			report.Violations = append(report.Violations, &ComplianceViolation{
+				Reference: reference,
+				Type:      entry.resourceType,
+				Platform:  entry.platform,
+				Key:       violation.Key,
+				Reason:    violation.Reason,
+				Value:     value,
+				Pattern:   violation.Pattern,
+				CheckTime: entry.checkTime,
+			})
+		}
+	}
+
+	// Sort violations to have a stable report
+	sort.SliceStable(report.Violations, func(i, j int) bool {
+		if report.Violations[i].Reference != report.Violations[j].Reference {
+			return report.Violations[i].Reference < report.Violations[j].Reference
+		}
+
+		return report.Violations[i].Key < report.Violations[j].Key
+	})
+
+	return report
+}
+
+// getRedactKeyRegexps Get regular expressions of tag keys with redacted values from current configuration.
+func (context *Context) getRedactKeyRegexps() ([]*regexp.Regexp, error) {
+	snapshot := context.GetSnapshot()
+	if snapshot == nil {
+		return []*regexp.Regexp{}, nil
+	}
+
+	redactKeyRegexps, err := snapshot.Configuration.Audit.GetRedactKeyRegexps()
+	if err != nil {
+		logrus.Errorf("Cannot redact compliance report values, all values are redacted: %v", err)
+
+		return nil, err
+	}
+
+	return redactKeyRegexps, nil
+}
@@ -0,0 +1,97 @@
+package business
+
+import (
+	"testing"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/audit"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/compliance"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/tags"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeResource struct {
+	resourceType string
+}
+
+func (fr *fakeResource) Type() string     { return fr.resourceType }
+func (fr *fakeResource) Platform() string { return "aws" }
+func (fr *fakeResource) ID() string       { return "id" }
+func (fr *fakeResource) GetAvailableTagValues() (map[string]interface{}, error) {
+	return map[string]interface{}{}, nil
+}
+func (fr *fakeResource) GetActualTags() ([]*tags.Tag, error)   { return []*tags.Tag{}, nil }
+func (fr *fakeResource) ManageTags(delta *tags.TagDelta) error { return nil }
+
+func TestContext_GetComplianceReport(t *testing.T) {
+	context := &Context{}
+	complianceConfig := &config.ComplianceConfig{RequiredTags: []*config.RequiredTagConfig{
+		{Key: "CostCenter", Pattern: "[0-9]{4}"},
+		{Key: "Owner"},
+	}}
+	resource := &fakeResource{resourceType: "volume"}
+
+	err := context.recordCompliance("pv/pv1", resource, complianceConfig, []*tags.Tag{
+		{Key: "CostCenter", Value: "1234"},
+		{Key: "Owner", Value: "team-a"},
+	})
+	assert.Nil(t, err)
+
+	err = context.recordCompliance("svc/default/svc1", resource, complianceConfig, []*tags.Tag{{Key: "CostCenter", Value: "abc"}})
+	assert.Nil(t, err)
+
+	report := context.GetComplianceReport()
+	assert.Equal(t, 2, report.Resources)
+	assert.Equal(t, 1, report.CompliantResources)
+	assert.Len(t, report.Violations, 2)
+	assert.Equal(t, "CostCenter", report.Violations[0].Key)
+	assert.Equal(t, compliance.ViolationReasonInvalidValue, report.Violations[0].Reason)
+	assert.Equal(t, "abc", report.Violations[0].Value)
+	assert.Equal(t, "Owner", report.Violations[1].Key)
+	assert.Equal(t, compliance.ViolationReasonMissing, report.Violations[1].Reason)
+
+	// Deleted objects are removed from report
+	context.forgetCompliance("svc/default/svc1")
+
+	report = context.GetComplianceReport()
+	assert.Equal(t, 1, report.Resources)
+	assert.Empty(t, report.Violations)
+
+	// Compliance disabled
+	err = context.recordCompliance("pv/pv1", resource, nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, context.GetComplianceReport().Resources)
+}
+
+func TestContext_GetComplianceReport_Redacted(t *testing.T) {
+	context := &Context{}
+	context.SetSnapshot(&Snapshot{Configuration: &config.Configuration{
+		Audit: &config.AuditConfig{RedactKeyPatterns: []string{"^Secret"}},
+	}})
+
+	complianceConfig := &config.ComplianceConfig{RequiredTags: []*config.RequiredTagConfig{
+		{Key: "CostCenter", Pattern: "[0-9]{4}"},
+		{Key: "SecretToken", Pattern: "[0-9]{4}"},
+	}}
+	resource := &fakeResource{resourceType: "volume"}
+
+	err := context.recordCompliance("pv/pv1", resource, complianceConfig, []*tags.Tag{
+		{Key: "CostCenter", Value: "abc"},
+		{Key: "SecretToken", Value: "token"},
+	})
+	assert.Nil(t, err)
+
+	report := context.GetComplianceReport()
+	assert.Len(t, report.Violations, 2)
+	assert.Equal(t, "abc", report.Violations[0].Value)
+	assert.Equal(t, audit.RedactedValue, report.Violations[1].Value)
+
+	// Invalid patterns redact all values
+	context.SetSnapshot(&Snapshot{Configuration: &config.Configuration{
+		Audit: &config.AuditConfig{RedactKeyPatterns: []string{"[0-9"}},
+	}})
+
+	report = context.GetComplianceReport()
+	assert.Equal(t, audit.RedactedValue, report.Violations[0].Value)
+	assert.Equal(t, audit.RedactedValue, report.Violations[1].Value)
+}
@@ -0,0 +1,129 @@
+package business
+
+import (
+	ctx "context"
+	"errors"
+	"strings"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/resources"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/rules"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/tags"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ErrInvalidReference Invalid object reference error.
+var ErrInvalidReference = errors.New("invalid reference, must be pv/<name> or svc/<namespace>/<name>")
+
+// ErrResourceNotSupported Object isn't a supported resource error.
+var ErrResourceNotSupported = errors.New("object isn't a supported resource for the configured provider")
+
+// Explanation Rules evaluation explanation for a resource.
+type Explanation struct {
+	Reference          string                 `json:"reference"`
+	Type               string                 `json:"type"`
+	Platform           string                 `json:"platform"`
+	AvailableTagValues map[string]interface{} `json:"availableTagValues"`
+	ActualTags         []*tags.Tag            `json:"actualTags"`
+	Trace              *rules.Trace           `json:"trace"`
+	Changes            []*tags.Change         `json:"changes"`
+}
+
+// Explain Trace rules evaluation for the object reference (pv/<name> or svc/<namespace>/<name>).
+// Rules are the ones applied by reconciles, with tagging policies ones.
+func Explain(
+	runCtx ctx.Context,
+	k8sClient kubernetes.Interface,
+	snapshot *Snapshot,
+	getRules RulesGetter,
+	reference string,
+) (*Explanation, error) {
+	resource, namespace, err := getResourceFromReference(runCtx, k8sClient, snapshot, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	rulesList, err := getRules(runCtx, resource.Type(), namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get actual tags
+	actualTags, err := resource.GetActualTags()
+	if err != nil {
+		return nil, err
+	}
+
+	availableTagValues, err := resource.GetAvailableTagValues()
+	if err != nil {
+		return nil, err
+	}
+
+	// Discovery errors are logged, missing facts are empty
+	clusterFacts, _ := discoverClusterFacts(runCtx, k8sClient, resources.NewClientObjectGetter(runCtx, k8sClient), snapshot.Configuration)
+	resources.AddClusterTagValues(availableTagValues, snapshot.Configuration.Cluster, clusterFacts)
+
+	delta, trace, err := rules.CalculateTagsWithTrace(actualTags, availableTagValues, rulesList)
+	// Check error
+	if err != nil {
+		return nil, err
+	}
+
+	return &Explanation{
+		Reference:          reference,
+		Type:               resource.Type(),
+		Platform:           resource.Platform(),
+		AvailableTagValues: availableTagValues,
+		ActualTags:         actualTags,
+		Trace:              trace,
+		Changes:            delta.Changes(actualTags),
+	}, nil
+}
+
+// getResourceFromReference Get resource of the object reference with the namespace used to select its rules.
+func getResourceFromReference(
+	runCtx ctx.Context,
+	k8sClient kubernetes.Interface,
+	snapshot *Snapshot,
+	reference string,
+) (resources.Resource, string, error) {
+	var (
+		resource  resources.Resource
+		namespace string
+		err       error
+	)
+
+	parts := strings.Split(reference, "/")
+
+	switch {
+	case len(parts) == 2 && parts[0] == PersistentVolumeReferencePrefix:
+		pv, getErr := k8sClient.CoreV1().PersistentVolumes().Get(runCtx, parts[1], metav1.GetOptions{})
+		if getErr != nil {
+			return nil, "", getErr
+		}
+
+		namespace = persistentVolumeNamespace(pv)
+		resource, err = resources.NewFromPersistentVolume(runCtx, k8sClient, resources.NewClientObjectGetter(runCtx, k8sClient), pv, snapshot.Configuration)
+	case len(parts) == 3 && parts[0] == ServiceReferencePrefix:
+		svc, getErr := k8sClient.CoreV1().Services(parts[1]).Get(runCtx, parts[2], metav1.GetOptions{})
+		if getErr != nil {
+			return nil, "", getErr
+		}
+
+		namespace = svc.Namespace
+		resource, err = resources.NewFromService(runCtx, k8sClient, resources.NewClientObjectGetter(runCtx, k8sClient), svc, snapshot.Configuration)
+	default:
+		return nil, "", ErrInvalidReference
+	}
+
+	// Check error
+	if err != nil {
+		return nil, "", err
+	}
+
+	if resource == nil {
+		return nil, "", ErrResourceNotSupported
+	}
+
+	return resource, namespace, nil
+}
@@ -0,0 +1,34 @@
+package business
+
+import (
+	ctx "context"
+	"testing"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclient "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestExplainErrors(t *testing.T) {
+	pv := &v1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv"}}
+	client := testclient.NewSimpleClientset(pv)
+	snapshot := &Snapshot{Configuration: &config.Configuration{
+		Provider: config.AWSProviderName,
+		AWS:      &config.AWSConfig{Region: "eu-west-1"},
+	}}
+
+	_, err := Explain(ctx.Background(), client, snapshot, NewRulesGetter(nil, snapshot), "pv")
+	assert.Equal(t, ErrInvalidReference, err)
+
+	_, err = Explain(ctx.Background(), client, snapshot, NewRulesGetter(nil, snapshot), "deployment/default/name")
+	assert.Equal(t, ErrInvalidReference, err)
+
+	_, err = Explain(ctx.Background(), client, snapshot, NewRulesGetter(nil, snapshot), "svc/default/not-found")
+	assert.True(t, k8serrors.IsNotFound(err))
+
+	_, err = Explain(ctx.Background(), client, snapshot, NewRulesGetter(nil, snapshot), "pv/pv")
+	assert.Equal(t, ErrResourceNotSupported, err)
+}
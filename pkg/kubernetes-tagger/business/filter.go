@@ -0,0 +1,137 @@
+package business
+
+import (
+	ctx "context"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Field used to filter objects on namespace in list options.
+const namespaceFieldName = "metadata.namespace"
+
+// getServiceNamespaces Get namespaces that must have a dedicated service informer.
+func getServiceNamespaces(cfg *config.Configuration) []string {
+	// No watch namespaces means a cluster wide informer
+	if len(cfg.WatchNamespaces) == 0 {
+		return []string{metav1.NamespaceAll}
+	}
+
+	namespaces := make([]string, 0)
+
+	for _, namespace := range cfg.WatchNamespaces {
+		if cfg.IsNamespaceWatched(namespace) {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+
+	return namespaces
+}
+
+// serviceTweakListOptions Generate tweak list options for service informers.
+func serviceTweakListOptions(cfg *config.Configuration) func(*metav1.ListOptions) {
+	return func(options *metav1.ListOptions) {
+		if cfg.LabelSelectors != nil {
+			options.LabelSelector = cfg.LabelSelectors.Services
+		}
+
+		setExcludedNamespacesFieldSelector(cfg, options)
+	}
+}
+
+// podTweakListOptions Generate tweak list options for pod informers.
+func podTweakListOptions(cfg *config.Configuration) func(*metav1.ListOptions) {
+	return func(options *metav1.ListOptions) {
+		setExcludedNamespacesFieldSelector(cfg, options)
+	}
+}
+
+// setExcludedNamespacesFieldSelector Filter excluded namespaces with a field selector.
+func setExcludedNamespacesFieldSelector(cfg *config.Configuration, options *metav1.ListOptions) {
+	// Excluded namespaces are only managed by field selector on cluster wide informer
+	// because namespaced informers are already filtered
+	if len(cfg.WatchNamespaces) == 0 && len(cfg.ExcludeNamespaces) != 0 {
+		selectors := make([]fields.Selector, 0)
+		for _, namespace := range cfg.ExcludeNamespaces {
+			selectors = append(selectors, fields.OneTermNotEqualSelector(namespaceFieldName, namespace))
+		}
+
+		options.FieldSelector = fields.AndSelectors(selectors...).String()
+	}
+}
+
+// persistentVolumeTweakListOptions Generate tweak list options for persistent volume informer.
+func persistentVolumeTweakListOptions(cfg *config.Configuration) func(*metav1.ListOptions) {
+	return func(options *metav1.ListOptions) {
+		if cfg.LabelSelectors != nil {
+			options.LabelSelector = cfg.LabelSelectors.PersistentVolumes
+		}
+	}
+}
+
+// isPersistentVolumeWatched Checks if a persistent volume is in the watch scope.
+// Persistent volumes are cluster scoped, so the namespace used is the claim one.
+func isPersistentVolumeWatched(runCtx ctx.Context, k8sClient kubernetes.Interface, pv *v1.PersistentVolume, cfg *config.Configuration) (bool, error) {
+	return checkPersistentVolumeScope(runCtx, k8sClient, pv, cfg, false)
+}
+
+// isDeletedPersistentVolumeWatched Checks if a deleted or released persistent volume was in the watch scope.
+// Its claim is often deleted before it, the claim label selector is only checked when the claim still exists.
+func isDeletedPersistentVolumeWatched(
+	runCtx ctx.Context,
+	k8sClient kubernetes.Interface,
+	pv *v1.PersistentVolume,
+	cfg *config.Configuration,
+) (bool, error) {
+	return checkPersistentVolumeScope(runCtx, k8sClient, pv, cfg, true)
+}
+
+func checkPersistentVolumeScope(
+	runCtx ctx.Context,
+	k8sClient kubernetes.Interface,
+	pv *v1.PersistentVolume,
+	cfg *config.Configuration,
+	missingClaimWatched bool,
+) (bool, error) {
+	claimRef := pv.Spec.ClaimRef
+	// Persistent volumes without claim are only watched when all namespaces are
+	if claimRef == nil {
+		return len(cfg.WatchNamespaces) == 0, nil
+	}
+
+	if !cfg.IsNamespaceWatched(claimRef.Namespace) {
+		return false, nil
+	}
+
+	// Check if claim label selector is set
+	if cfg.LabelSelectors == nil || cfg.LabelSelectors.PersistentVolumeClaims == "" {
+		return true, nil
+	}
+
+	selector, err := labels.Parse(cfg.LabelSelectors.PersistentVolumeClaims)
+	if err != nil {
+		return false, err
+	}
+
+	pvc, err := k8sClient.CoreV1().PersistentVolumeClaims(claimRef.Namespace).Get(runCtx, claimRef.Name, metav1.GetOptions{})
+	if err != nil {
+		// Claim not found cannot match the selector
+		if k8serrors.IsNotFound(err) {
+			return missingClaimWatched, nil
+		}
+
+		return false, err
+	}
+
+	// Claim of a released volume may have been created again with the same name
+	if missingClaimWatched && claimRef.UID != "" && pvc.UID != claimRef.UID {
+		return true, nil
+	}
+
+	return selector.Matches(labels.Set(pvc.Labels)), nil
+}
@@ -0,0 +1,135 @@
+package business
+
+import (
+	ctx "context"
+	"testing"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclient "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetServiceNamespaces(t *testing.T) {
+	assert.Equal(t, []string{""}, getServiceNamespaces(&config.Configuration{}))
+	assert.Equal(t, []string{"a"}, getServiceNamespaces(&config.Configuration{
+		WatchNamespaces:   []string{"a", "b"},
+		ExcludeNamespaces: []string{"b"},
+	}))
+}
+
+func TestServiceTweakListOptions(t *testing.T) {
+	options := &metav1.ListOptions{}
+	serviceTweakListOptions(&config.Configuration{
+		ExcludeNamespaces: []string{"a", "b"},
+		LabelSelectors:    &config.LabelSelectorsConfig{Services: "app=test"},
+	})(options)
+
+	assert.Equal(t, "app=test", options.LabelSelector)
+	assert.Equal(t, "metadata.namespace!=a,metadata.namespace!=b", options.FieldSelector)
+
+	// Namespaced informers don't need field selector
+	options = &metav1.ListOptions{}
+	serviceTweakListOptions(&config.Configuration{
+		WatchNamespaces:   []string{"a"},
+		ExcludeNamespaces: []string{"b"},
+	})(options)
+
+	assert.Equal(t, "", options.LabelSelector)
+	assert.Equal(t, "", options.FieldSelector)
+}
+
+func TestIsPersistentVolumeWatched(t *testing.T) {
+	claimRef := &v1.ObjectReference{Namespace: "ns", Name: "claim"}
+	pvc := &v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+		Name:      "claim",
+		Namespace: "ns",
+		Labels:    map[string]string{"team": "a"},
+	}}
+	tests := []struct {
+		name     string
+		pv       *v1.PersistentVolume
+		cfg      *config.Configuration
+		expected bool
+	}{
+		{"without claim and no filter", &v1.PersistentVolume{}, &config.Configuration{}, true},
+		{
+			"without claim and watch namespaces",
+			&v1.PersistentVolume{},
+			&config.Configuration{WatchNamespaces: []string{"ns"}},
+			false,
+		},
+		{
+			"claim in excluded namespace",
+			&v1.PersistentVolume{Spec: v1.PersistentVolumeSpec{ClaimRef: claimRef}},
+			&config.Configuration{ExcludeNamespaces: []string{"ns"}},
+			false,
+		},
+		{
+			"claim matching label selector",
+			&v1.PersistentVolume{Spec: v1.PersistentVolumeSpec{ClaimRef: claimRef}},
+			&config.Configuration{LabelSelectors: &config.LabelSelectorsConfig{PersistentVolumeClaims: "team=a"}},
+			true,
+		},
+		{
+			"claim not matching label selector",
+			&v1.PersistentVolume{Spec: v1.PersistentVolumeSpec{ClaimRef: claimRef}},
+			&config.Configuration{LabelSelectors: &config.LabelSelectorsConfig{PersistentVolumeClaims: "team=b"}},
+			false,
+		},
+		{
+			"claim not found with label selector",
+			&v1.PersistentVolume{Spec: v1.PersistentVolumeSpec{ClaimRef: &v1.ObjectReference{Namespace: "ns", Name: "other"}}},
+			&config.Configuration{LabelSelectors: &config.LabelSelectorsConfig{PersistentVolumeClaims: "team=a"}},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := testclient.NewSimpleClientset(pvc)
+
+			res, err := isPersistentVolumeWatched(ctx.Background(), client, tt.pv, tt.cfg)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, res)
+		})
+	}
+}
+
+func TestIsDeletedPersistentVolumeWatched(t *testing.T) {
+	pvc := &v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+		Name:      "claim",
+		Namespace: "ns",
+		UID:       "new",
+		Labels:    map[string]string{"team": "b"},
+	}}
+	cfg := &config.Configuration{LabelSelectors: &config.LabelSelectorsConfig{PersistentVolumeClaims: "team=a"}}
+	tests := []struct {
+		name     string
+		claimRef *v1.ObjectReference
+		cfg      *config.Configuration
+		expected bool
+	}{
+		{"deleted claim", &v1.ObjectReference{Namespace: "ns", Name: "other"}, cfg, true},
+		{"claim created again", &v1.ObjectReference{Namespace: "ns", Name: "claim", UID: "old"}, cfg, true},
+		{"existing claim not matching label selector", &v1.ObjectReference{Namespace: "ns", Name: "claim", UID: "new"}, cfg, false},
+		{
+			"deleted claim in excluded namespace",
+			&v1.ObjectReference{Namespace: "ns", Name: "other"},
+			&config.Configuration{ExcludeNamespaces: []string{"ns"}, LabelSelectors: cfg.LabelSelectors},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := testclient.NewSimpleClientset(pvc)
+			pv := &v1.PersistentVolume{Spec: v1.PersistentVolumeSpec{ClaimRef: tt.claimRef}}
+
+			res, err := isDeletedPersistentVolumeWatched(ctx.Background(), client, pv, tt.cfg)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, res)
+		})
+	}
+}
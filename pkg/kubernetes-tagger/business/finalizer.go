@@ -0,0 +1,174 @@
+package business
+
+import (
+	ctx "context"
+	"encoding/json"
+	"time"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	providerclient "github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/providerClient"
+	"github.com/sirupsen/logrus"
+	"github.com/thoas/go-funk"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// CleanupFinalizer Finalizer added on Kubernetes objects to run on delete actions before they are deleted.
+const CleanupFinalizer = "kubernetes-tagger.io/cleanup"
+
+// SkipCleanupAnnotation Annotation removing the cleanup finalizer without running on delete actions when set to "true".
+const SkipCleanupAnnotation = "kubernetes-tagger.io/skip-cleanup"
+
+// objectPatcher Apply a merge patch on a Kubernetes object.
+type objectPatcher func(patch []byte) error
+
+func persistentVolumePatcher(runCtx ctx.Context, k8sClient kubernetes.Interface, name string) objectPatcher {
+	return func(patch []byte) error {
+		_, err := k8sClient.CoreV1().PersistentVolumes().Patch(runCtx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+
+		return err
+	}
+}
+
+func servicePatcher(runCtx ctx.Context, k8sClient kubernetes.Interface, namespace, name string) objectPatcher {
+	return func(patch []byte) error {
+		_, err := k8sClient.CoreV1().Services(namespace).Patch(runCtx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+
+		return err
+	}
+}
+
+// updateCleanupFinalizer Add or remove cleanup finalizer, nothing is done when object is already up to date.
+func updateCleanupFinalizer(obj metav1.Object, patcher objectPatcher, needed bool) error {
+	finalizers := obj.GetFinalizers()
+	if funk.ContainsString(finalizers, CleanupFinalizer) == needed {
+		return nil
+	}
+
+	newFinalizers := make([]string, 0, len(finalizers)+1)
+
+	for _, finalizer := range finalizers {
+		if finalizer != CleanupFinalizer {
+			newFinalizers = append(newFinalizers, finalizer)
+		}
+	}
+
+	if needed {
+		newFinalizers = append(newFinalizers, CleanupFinalizer)
+	}
+
+	patch, err := getFinalizersPatch(obj.GetResourceVersion(), newFinalizers)
+	if err != nil {
+		return err
+	}
+
+	return patcher(patch)
+}
+
+// getFinalizersPatch Create merge patch replacing finalizers.
+// Resource version makes the patch fail when object has changed to avoid losing other finalizers.
+func getFinalizersPatch(resourceVersion string, finalizers []string) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"finalizers":      finalizers,
+			"resourceVersion": resourceVersion,
+		},
+	})
+}
+
+// runCleanup Run cleanup finalizer of a deleting object and save that delete event doesn't need on delete actions.
+func (context *Context) runCleanup(
+	reference string,
+	obj metav1.Object,
+	patcher objectPatcher,
+	cfg *config.Configuration,
+	log *logrus.Entry,
+	runActions func() error,
+) error {
+	// Objects without finalizer have their actions run on delete event
+	if !funk.ContainsString(obj.GetFinalizers(), CleanupFinalizer) {
+		return nil
+	}
+
+	err := runCleanupFinalizer(obj, patcher, cfg, log, runActions)
+	if err != nil {
+		return err
+	}
+
+	context.recordCleanup(reference)
+
+	return nil
+}
+
+// recordCleanup Save that on delete actions of an object have been run by its finalizer.
+func (context *Context) recordCleanup(reference string) {
+	context.cleanupMutex.Lock()
+	defer context.cleanupMutex.Unlock()
+
+	if context.cleanedUp == nil {
+		context.cleanedUp = make(map[string]bool)
+	}
+
+	context.cleanedUp[reference] = true
+}
+
+// forgetCleanup Remove saved cleanup of a deleted object and return if it was saved.
+func (context *Context) forgetCleanup(reference string) bool {
+	context.cleanupMutex.Lock()
+	defer context.cleanupMutex.Unlock()
+
+	cleanedUp := context.cleanedUp[reference]
+	delete(context.cleanedUp, reference)
+
+	return cleanedUp
+}
+
+// runCleanupFinalizer Run on delete actions of a deleting object then remove cleanup finalizer.
+// Finalizer is kept when actions fail before timeout to retry them on next update or resync.
+// Cloud resource already deleted has nothing to clean, finalizer is removed.
+func runCleanupFinalizer(
+	obj metav1.Object,
+	patcher objectPatcher,
+	cfg *config.Configuration,
+	log *logrus.Entry,
+	runActions func() error,
+) error {
+	if !funk.ContainsString(obj.GetFinalizers(), CleanupFinalizer) {
+		return nil
+	}
+
+	if obj.GetAnnotations()[SkipCleanupAnnotation] == "true" {
+		log.Warn("On delete actions skipped because of annotation")
+	} else {
+		err := runActions()
+		// Check error
+		switch {
+		case err == nil:
+		case providerclient.IsNotFoundError(err):
+			log.Infof("On delete actions skipped because cloud resource doesn't exist anymore: %v", err)
+		case !isCleanupTimedOut(obj, cfg):
+			return err
+		default:
+			log.Errorf("On delete actions failed until timeout, finalizer removed anyway: %v", err)
+		}
+	}
+
+	log.Debug("Remove cleanup finalizer")
+
+	return updateCleanupFinalizer(obj, patcher, false)
+}
+
+// isCleanupTimedOut Checks if finalizer timeout is reached since object deletion.
+func isCleanupTimedOut(obj metav1.Object, cfg *config.Configuration) bool {
+	timeout := config.DefaultOnDeleteFinalizerTimeout
+
+	// Configuration is validated, finalizer may be disabled after it has been added
+	if cfg.OnDelete != nil && cfg.OnDelete.Finalizer != nil {
+		timeout, _ = cfg.OnDelete.Finalizer.GetTimeout()
+	}
+
+	deletionTimestamp := obj.GetDeletionTimestamp()
+
+	return deletionTimestamp == nil || time.Since(deletionTimestamp.Time) >= timeout
+}
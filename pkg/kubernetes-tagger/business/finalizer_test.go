@@ -0,0 +1,127 @@
+package business
+
+import (
+	ctx "context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	providerclient "github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/providerClient"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclient "k8s.io/client-go/kubernetes/fake"
+)
+
+func getTestPersistentVolumeFinalizers(t *testing.T, k8sClient *testclient.Clientset) []string {
+	pv, err := k8sClient.CoreV1().PersistentVolumes().Get(ctx.TODO(), "pv1", metav1.GetOptions{})
+	assert.Nil(t, err)
+
+	return pv.Finalizers
+}
+
+func TestUpdateCleanupFinalizer(t *testing.T) {
+	pv := &v1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv1", Finalizers: []string{"kubernetes.io/pv-protection"}}}
+	k8sClient := testclient.NewSimpleClientset(pv)
+	patcher := persistentVolumePatcher(ctx.Background(), k8sClient, pv.Name)
+
+	err := updateCleanupFinalizer(pv, patcher, true)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"kubernetes.io/pv-protection", CleanupFinalizer}, getTestPersistentVolumeFinalizers(t, k8sClient))
+
+	// Nothing to do
+	err = updateCleanupFinalizer(pv, func(patch []byte) error { return errors.New("mustn't be called") }, false)
+	assert.Nil(t, err)
+
+	pv.Finalizers = getTestPersistentVolumeFinalizers(t, k8sClient)
+
+	err = updateCleanupFinalizer(pv, patcher, false)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"kubernetes.io/pv-protection"}, getTestPersistentVolumeFinalizers(t, k8sClient))
+}
+
+func TestRunCleanupFinalizer(t *testing.T) {
+	log := logrus.WithField("test", "finalizer")
+	cfg := &config.Configuration{OnDelete: &config.OnDeleteConfig{Finalizer: &config.OnDeleteFinalizerConfig{Enabled: true, Timeout: "5m"}}}
+	actionsErr := errors.New("volume not found")
+
+	tests := []struct {
+		name              string
+		deletedSince      time.Duration
+		annotations       map[string]string
+		actionsErr        error
+		wantErr           error
+		wantActionsCalled bool
+		wantFinalizers    []string
+	}{
+		{"actions succeed", time.Minute, nil, nil, nil, true, []string{}},
+		{"actions fail before timeout", time.Minute, nil, actionsErr, actionsErr, true, []string{CleanupFinalizer}},
+		{"actions fail after timeout", 10 * time.Minute, nil, actionsErr, nil, true, []string{}},
+		{"cloud resource not found", time.Minute, nil, providerclient.ErrLoadBalancerNotFound, nil, true, []string{}},
+		{"skip annotation", time.Minute, map[string]string{SkipCleanupAnnotation: "true"}, nil, nil, false, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deletionTimestamp := metav1.NewTime(time.Now().Add(-tt.deletedSince))
+			pv := &v1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{
+				Name:              "pv1",
+				Annotations:       tt.annotations,
+				Finalizers:        []string{CleanupFinalizer},
+				DeletionTimestamp: &deletionTimestamp,
+			}}
+			k8sClient := testclient.NewSimpleClientset(pv)
+			actionsCalled := false
+
+			err := runCleanupFinalizer(pv, persistentVolumePatcher(ctx.Background(), k8sClient, pv.Name), cfg, log, func() error {
+				actionsCalled = true
+
+				return tt.actionsErr
+			})
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantActionsCalled, actionsCalled)
+			assert.Equal(t, tt.wantFinalizers, getTestPersistentVolumeFinalizers(t, k8sClient))
+		})
+	}
+}
+
+func TestRunCleanupFinalizerWithoutFinalizer(t *testing.T) {
+	pv := &v1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv1"}}
+
+	err := runCleanupFinalizer(pv, nil, &config.Configuration{}, logrus.WithField("test", "finalizer"), func() error {
+		return errors.New("mustn't be called")
+	})
+	assert.Nil(t, err)
+}
+
+func TestContext_runCleanup(t *testing.T) {
+	context := &Context{}
+	log := logrus.WithField("test", "finalizer")
+	cfg := &config.Configuration{OnDelete: &config.OnDeleteConfig{Finalizer: &config.OnDeleteFinalizerConfig{Enabled: true}}}
+	deletionTimestamp := metav1.Now()
+	pv := &v1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{
+		Name:              "pv1",
+		Finalizers:        []string{CleanupFinalizer},
+		DeletionTimestamp: &deletionTimestamp,
+	}}
+	k8sClient := testclient.NewSimpleClientset(pv)
+	patcher := persistentVolumePatcher(ctx.Background(), k8sClient, pv.Name)
+
+	// Failed cleanup must be run again on delete event
+	err := context.runCleanup("pv/pv1", pv, patcher, cfg, log, func() error { return errors.New("fake") })
+	assert.NotNil(t, err)
+	assert.False(t, context.forgetCleanup("pv/pv1"))
+
+	err = context.runCleanup("pv/pv1", pv, patcher, cfg, log, func() error { return nil })
+	assert.Nil(t, err)
+	assert.True(t, context.forgetCleanup("pv/pv1"))
+	assert.False(t, context.forgetCleanup("pv/pv1"))
+
+	// Objects without finalizer have their actions run on delete event
+	pv = &v1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv2", DeletionTimestamp: &deletionTimestamp}}
+
+	err = context.runCleanup("pv/pv2", pv, nil, cfg, log, func() error { return errors.New("mustn't be called") })
+	assert.Nil(t, err)
+	assert.False(t, context.forgetCleanup("pv/pv2"))
+}
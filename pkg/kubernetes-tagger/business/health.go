@@ -0,0 +1,192 @@
+package business
+
+import (
+	ctx "context"
+	"errors"
+	"time"
+
+	"github.com/dimiro1/health"
+	providerclient "github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/providerClient"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Health checker names.
+const (
+	ConfigurationCheckerName       = "configuration"
+	LeaderCheckerName              = "leader"
+	InformersCheckerName           = "informers"
+	ProviderCredentialsCheckerName = "provider"
+)
+
+// ErrNoConfigurationLoaded No configuration loaded error.
+var ErrNoConfigurationLoaded = errors.New("no configuration loaded")
+
+// providerCredentialsCheckInterval Minimum interval between two provider credentials checks
+// to avoid calling the provider on each probe.
+const providerCredentialsCheckInterval = time.Minute
+
+// providerCredentialsCheckTimeout Maximum duration of a provider credentials check.
+// It must stay shorter than the readiness probe timeout of the Helm chart (5s).
+const providerCredentialsCheckTimeout = 3 * time.Second
+
+// Provider client creation, can be replaced in tests.
+var newProviderClient = providerclient.NewProviderClient
+
+// SetLeader Set if the current instance is the leader.
+func (context *Context) SetLeader(leader bool) {
+	context.healthMutex.Lock()
+	defer context.healthMutex.Unlock()
+
+	context.leader = leader
+}
+
+// IsLeader Check if the current instance is the leader.
+func (context *Context) IsLeader() bool {
+	context.healthMutex.RLock()
+	defer context.healthMutex.RUnlock()
+
+	return context.leader
+}
+
+// SetConfigurationError Save the last configuration reload error.
+// A nil error means that the last configuration loaded is valid.
+func (context *Context) SetConfigurationError(err error) {
+	context.healthMutex.Lock()
+	defer context.healthMutex.Unlock()
+
+	context.configurationError = err
+}
+
+// ConfigurationChecker Checker down when no configuration is loaded.
+// A failed reload keeps the checker up with the error as info because the previous configuration is still used.
+func (context *Context) ConfigurationChecker() health.Checker {
+	return health.CheckerFunc(func() health.Health {
+		h := health.NewHealth()
+
+		context.healthMutex.RLock()
+		err := context.configurationError
+		context.healthMutex.RUnlock()
+
+		if context.GetSnapshot() == nil {
+			h.Down().AddInfo("error", ErrNoConfigurationLoaded.Error())
+
+			if err != nil {
+				h.AddInfo("reloadError", err.Error())
+			}
+
+			return h
+		}
+
+		h.Up()
+
+		if err != nil {
+			h.AddInfo("reloadError", err.Error())
+		}
+
+		return h
+	})
+}
+
+// LeaderChecker Checker always up giving the leader status of the current instance.
+func (context *Context) LeaderChecker() health.Checker {
+	return health.CheckerFunc(func() health.Health {
+		h := health.NewHealth()
+		h.Up().AddInfo("leader", context.IsLeader())
+
+		return h
+	})
+}
+
+// InformersChecker Checker down when the current instance is the leader and informers caches aren't synced.
+// Instances that aren't leader don't watch objects and are always up.
+func (context *Context) InformersChecker() health.Checker {
+	return health.CheckerFunc(func() health.Health {
+		h := health.NewHealth()
+
+		if !context.IsLeader() {
+			h.Up().AddInfo("synced", false)
+
+			return h
+		}
+
+		context.informersMutex.RLock()
+		persistentVolumeInformer := context.persistentVolumeInformer
+		otherInformers := append(append([]cache.SharedIndexInformer{}, context.serviceInformers...), context.policyInformers...)
+
+		for _, informer := range context.podInformers {
+			otherInformers = append(otherInformers, informer)
+		}
+
+		for _, informer := range []cache.SharedIndexInformer{
+			context.namespaceInformer, context.storageClassInformer,
+		} {
+			if informer != nil {
+				otherInformers = append(otherInformers, informer)
+			}
+		}
+		context.informersMutex.RUnlock()
+
+		synced := persistentVolumeInformer != nil && persistentVolumeInformer.HasSynced()
+
+		for _, informer := range otherInformers {
+			synced = synced && informer.HasSynced()
+		}
+
+		if synced {
+			h.Up()
+		} else {
+			h.Down()
+		}
+
+		h.AddInfo("synced", synced)
+
+		return h
+	})
+}
+
+// ProviderCredentialsChecker Checker down when provider credentials are invalid.
+// The result is cached during providerCredentialsCheckInterval.
+func (context *Context) ProviderCredentialsChecker() health.Checker {
+	return health.CheckerFunc(func() health.Health {
+		h := health.NewHealth()
+
+		err := context.checkProviderCredentials()
+		if err != nil {
+			h.Down().AddInfo("error", err.Error())
+
+			return h
+		}
+
+		h.Up()
+
+		return h
+	})
+}
+
+func (context *Context) checkProviderCredentials() error {
+	context.providerCheckMutex.Lock()
+	defer context.providerCheckMutex.Unlock()
+
+	// Use last result if it is recent enough
+	if !context.providerCheckTime.IsZero() && time.Since(context.providerCheckTime) < providerCredentialsCheckInterval {
+		return context.providerCheckError
+	}
+
+	snapshot := context.GetSnapshot()
+	if snapshot == nil {
+		return ErrNoConfigurationLoaded
+	}
+
+	prcl, err := newProviderClient(snapshot.Configuration)
+	if err == nil {
+		checkCtx, cancel := ctx.WithTimeout(ctx.Background(), providerCredentialsCheckTimeout)
+		err = prcl.CheckCredentials(checkCtx)
+
+		cancel()
+	}
+
+	context.providerCheckTime = time.Now()
+	context.providerCheckError = err
+
+	return err
+}
@@ -0,0 +1,128 @@
+package business
+
+import (
+	ctx "context"
+	"errors"
+	"testing"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	providerclient "github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/providerClient"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/cache"
+)
+
+type fakeProviderClient struct {
+	providerclient.ProviderClient
+	calls int
+	err   error
+}
+
+func (fpc *fakeProviderClient) CheckCredentials(_ ctx.Context) error {
+	fpc.calls++
+
+	return fpc.err
+}
+
+type fakeInformer struct {
+	cache.SharedIndexInformer
+	synced bool
+}
+
+func (fi *fakeInformer) HasSynced() bool {
+	return fi.synced
+}
+
+func TestContext_ConfigurationChecker(t *testing.T) {
+	context := &Context{}
+	checker := context.ConfigurationChecker()
+
+	assert.True(t, checker.Check().IsDown())
+
+	context.SetSnapshot(&Snapshot{Configuration: &config.Configuration{}})
+	assert.True(t, checker.Check().IsUp())
+
+	// Previous configuration is still used after a failed reload
+	context.SetConfigurationError(errors.New("invalid"))
+	h := checker.Check()
+	assert.True(t, h.IsUp())
+	assert.Equal(t, "invalid", h.GetInfo("reloadError"))
+
+	context.SetConfigurationError(nil)
+	h = checker.Check()
+	assert.True(t, h.IsUp())
+	assert.Nil(t, h.GetInfo("reloadError"))
+
+	// Without configuration, checker is down
+	context = &Context{}
+	context.SetConfigurationError(errors.New("invalid"))
+	h = context.ConfigurationChecker().Check()
+	assert.True(t, h.IsDown())
+	assert.Equal(t, "invalid", h.GetInfo("reloadError"))
+}
+
+func TestContext_LeaderChecker(t *testing.T) {
+	context := &Context{}
+	checker := context.LeaderChecker()
+
+	h := checker.Check()
+	assert.True(t, h.IsUp())
+	assert.Equal(t, false, h.GetInfo("leader"))
+
+	context.SetLeader(true)
+	h = checker.Check()
+	assert.True(t, h.IsUp())
+	assert.Equal(t, true, h.GetInfo("leader"))
+}
+
+func TestContext_InformersChecker(t *testing.T) {
+	context := &Context{}
+	checker := context.InformersChecker()
+
+	// Not leader
+	assert.True(t, checker.Check().IsUp())
+
+	// Leader without informers started
+	context.SetLeader(true)
+	assert.True(t, checker.Check().IsDown())
+
+	serviceInformer := &fakeInformer{}
+	context.persistentVolumeInformer = &fakeInformer{synced: true}
+	context.serviceInformers = []cache.SharedIndexInformer{serviceInformer}
+	assert.True(t, checker.Check().IsDown())
+
+	serviceInformer.synced = true
+	assert.True(t, checker.Check().IsUp())
+}
+
+func TestContext_ProviderCredentialsChecker(t *testing.T) {
+	fake := &fakeProviderClient{err: errors.New("invalid credentials")}
+	previous := newProviderClient
+	newProviderClient = func(_ *config.Configuration) (providerclient.ProviderClient, error) {
+		return fake, nil
+	}
+
+	defer func() { newProviderClient = previous }()
+
+	context := &Context{}
+	checker := context.ProviderCredentialsChecker()
+
+	// No configuration
+	assert.True(t, checker.Check().IsDown())
+	assert.Equal(t, 0, fake.calls)
+
+	context.SetSnapshot(&Snapshot{Configuration: &config.Configuration{}})
+	h := checker.Check()
+	assert.True(t, h.IsDown())
+	assert.Equal(t, "invalid credentials", h.GetInfo("error"))
+	assert.Equal(t, 1, fake.calls)
+
+	// Result is cached
+	fake.err = nil
+	assert.True(t, checker.Check().IsDown())
+	assert.Equal(t, 1, fake.calls)
+
+	// Cache expired
+	context.providerCheckTime = context.providerCheckTime.Add(-providerCredentialsCheckInterval)
+	assert.True(t, checker.Check().IsUp())
+	assert.Equal(t, 2, fake.calls)
+}
@@ -1,32 +1,316 @@
 package business
 
 import (
+	ctx "context"
+	"sync"
 	"time"
 
-	"k8s.io/apimachinery/pkg/util/wait"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/resources"
+	"github.com/sirupsen/logrus"
 	kubeinformers "k8s.io/client-go/informers"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	storagelisters "k8s.io/client-go/listers/storage/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
 )
 
-// Watch Watch Kubernetes.
-func Watch(context *Context) {
-	informerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(context.KubernetesClient,
-		time.Minute, kubeinformers.WithNamespace(""))
+// defaultOwnerCacheTTL Duration owners are kept in cache when related objects resync is disabled.
+const defaultOwnerCacheTTL = 10 * time.Minute
 
-	persistentVolumeInformer := informerFactory.Core().V1().PersistentVolumes()
-	serviceInformer := informerFactory.Core().V1().Services()
+// Watch Watch Kubernetes until StopWatch is called or parent context is done.
+func Watch(parentCtx ctx.Context, context *Context) {
+	cfg := context.GetSnapshot().Configuration
+	watchCtx, stopWatch := ctx.WithCancel(parentCtx)
+	stopCh := watchCtx.Done()
+	// Work context isn't derived from parent one to let StopWatch drain in-flight calls
+	workCtx, stopWork := ctx.WithCancel(ctx.Background())
+	// Each watch has its own workers, workers of a previous watch may still run after StopWatch timeout
+	workers := &sync.WaitGroup{}
 
-	persistentVolumeInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+	context.informersMutex.Lock()
+	context.stopWatch = stopWatch
+	context.workCtx = workCtx
+	context.stopWork = stopWork
+	context.workers = workers
+	context.informersMutex.Unlock()
+
+	// Resync periods are checked by configuration validation
+	resyncPeriods, _ := cfg.Reconcile.GetResyncPeriods()
+
+	// Load tagging policies before watching objects
+	if cfg.TaggingPolicies && context.DynamicClient != nil {
+		context.watchPolicies(watchCtx, resyncPeriods.TaggingPolicies)
+	}
+
+	// Namespaces, pods and storage classes are used in available tag values
+	context.watchRelatedObjects(watchCtx, cfg, resyncPeriods.RelatedObjects)
+
+	// Objects events are managed by workers to limit concurrent cloud calls, failed ones are retried with a backoff
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "objects")
+	context.runWorkers(stopCh, workCtx, queue, workers, cfg.Reconcile.GetWorkers())
+
+	// Persistent volumes are cluster scoped, namespace filtering is done on claim
+	persistentVolumeInformerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(context.KubernetesClient,
+		resyncPeriods.PersistentVolumes, kubeinformers.WithTweakListOptions(persistentVolumeTweakListOptions(cfg)))
+
+	persistentVolumeInformer := persistentVolumeInformerFactory.Core().V1().PersistentVolumes().Informer()
+
+	persistentVolumeInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc:    context.handlePersistentVolumeAdd,
 		UpdateFunc: context.handlePersistentVolumeUpdate,
 		DeleteFunc: context.handlePersistentVolumeDelete,
 	})
 
-	serviceInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc:    context.handleServiceAdd,
-		UpdateFunc: context.handleServiceUpdate,
-		DeleteFunc: context.handleServiceDelete,
-	})
+	// Services need one informer per watched namespace
+	serviceInformerFactories := make([]kubeinformers.SharedInformerFactory, 0)
+	serviceInformers := make([]cache.SharedIndexInformer, 0)
+
+	for _, namespace := range getServiceNamespaces(cfg) {
+		serviceInformerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(context.KubernetesClient,
+			resyncPeriods.Services, kubeinformers.WithNamespace(namespace),
+			kubeinformers.WithTweakListOptions(serviceTweakListOptions(cfg)))
+
+		serviceInformer := serviceInformerFactory.Core().V1().Services().Informer()
+
+		serviceInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    context.handleServiceAdd,
+			UpdateFunc: context.handleServiceUpdate,
+			DeleteFunc: context.handleServiceDelete,
+		})
+
+		serviceInformerFactories = append(serviceInformerFactories, serviceInformerFactory)
+		serviceInformers = append(serviceInformers, serviceInformer)
+	}
+
+	// Save informers for full reconcile
+	context.informersMutex.Lock()
+	context.persistentVolumeInformer = persistentVolumeInformer
+	context.serviceInformers = serviceInformers
+	context.queue = queue
+	context.informersMutex.Unlock()
+
+	// Start informers
+	persistentVolumeInformerFactory.Start(stopCh)
+
+	for _, serviceInformerFactory := range serviceInformerFactories {
+		serviceInformerFactory.Start(stopCh)
+	}
+
+	// Cloud sweep checks its configuration on each run to follow reloads
+	workers.Add(1)
+
+	go func() {
+		defer workers.Done()
+
+		context.runSweeps(stopCh, workCtx)
+	}()
+}
+
+// StopWatch Stop informers, workers and sweeps started by Watch and forget state of watched objects.
+// In-flight reconciles and sweeps are waited until timeout, their cloud and Kubernetes calls are cancelled after it.
+// False is returned when they don't end in time.
+func (context *Context) StopWatch(timeout time.Duration) bool {
+	context.informersMutex.Lock()
+	stopWatch := context.stopWatch
+	stopWork := context.stopWork
+	queue := context.queue
+	workers := context.workers
+	context.stopWatch = nil
+	context.workCtx = nil
+	context.stopWork = nil
+	context.workers = nil
+	context.queue = nil
+	context.persistentVolumeInformer = nil
+	context.serviceInformers = nil
+	context.namespaceInformer = nil
+	context.podInformers = nil
+	context.ownerCache = nil
+	context.storageClassInformer = nil
+	context.policyInformers = nil
+	context.informersMutex.Unlock()
+
+	// Not watching
+	if stopWatch == nil {
+		return true
+	}
+
+	// Calls still running after timeout are cancelled
+	defer stopWork()
+
+	stopWatch()
+	queue.ShutDown()
+
+	// Objects may change before next watch
+	context.forgetAll()
+
+	done := make(chan struct{})
+
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// forgetAll Forget policies, compliance, unchanged objects and volumes scope state.
+func (context *Context) forgetAll() {
+	context.policiesMutex.Lock()
+	context.policies = nil
+	context.policiesMutex.Unlock()
+
+	context.complianceMutex.RLock()
+	references := make([]string, 0, len(context.compliance))
+
+	for reference := range context.compliance {
+		references = append(references, reference)
+	}
+	context.complianceMutex.RUnlock()
+
+	for _, reference := range references {
+		context.forgetCompliance(reference)
+	}
+
+	context.unchangedMutex.Lock()
+	context.unchanged = nil
+	context.unchangedMutex.Unlock()
+
+	context.scopeMutex.Lock()
+	context.volumesScope = nil
+	context.scopeMutex.Unlock()
+}
+
+// watchRelatedObjects Start namespaces, pods and storage classes informers and wait for their caches.
+// These objects are read from caches instead of Kubernetes API on each run.
+// Pods are only watched in watched namespaces because other ones aren't used.
+func (context *Context) watchRelatedObjects(watchCtx ctx.Context, cfg *config.Configuration, resyncPeriod time.Duration) {
+	factory := kubeinformers.NewSharedInformerFactory(context.KubernetesClient, resyncPeriod)
+	namespaceInformer := factory.Core().V1().Namespaces().Informer()
+	storageClassInformer := factory.Storage().V1().StorageClasses().Informer()
+	factories := []kubeinformers.SharedInformerFactory{factory}
+	podInformers := make(map[string]cache.SharedIndexInformer)
+
+	// Pods need one informer per watched namespace like services
+	for _, namespace := range getServiceNamespaces(cfg) {
+		podInformerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(context.KubernetesClient,
+			resyncPeriod, kubeinformers.WithNamespace(namespace),
+			kubeinformers.WithTweakListOptions(podTweakListOptions(cfg)))
+
+		podInformer := podInformerFactory.Core().V1().Pods().Informer()
+
+		// Pods are found by mounted claims for persistent volumes
+		err := podInformer.AddIndexers(cache.Indexers{resources.PodClaimIndex: resources.PodClaimIndexFunc})
+		if err != nil {
+			logrus.Fatalf("Cannot add pods index: %v", err)
+		}
+
+		factories = append(factories, podInformerFactory)
+		podInformers[namespace] = podInformer
+	}
+
+	for _, f := range factories {
+		f.Start(watchCtx.Done())
+	}
+
+	// Wait for related objects before managing objects to have all values from the start
+	for _, f := range factories {
+		for resource, synced := range f.WaitForCacheSync(watchCtx.Done()) {
+			if !synced {
+				logrus.WithField("resource", resource.String()).Error("Cannot sync related objects cache")
+			}
+		}
+	}
+
+	context.informersMutex.Lock()
+	context.namespaceInformer = namespaceInformer
+	context.podInformers = podInformers
+	context.storageClassInformer = storageClassInformer
+	// Owners aren't watched because only few of them are needed, they are kept during resync period
+	context.ownerCache = resources.NewOwnerCache(getOwnerCacheTTL(resyncPeriod))
+	context.informersMutex.Unlock()
+}
+
+// getOwnerCacheTTL Get duration owners are kept in cache, related objects resync period when it is enabled.
+func getOwnerCacheTTL(resyncPeriod time.Duration) time.Duration {
+	if resyncPeriod <= 0 {
+		return defaultOwnerCacheTTL
+	}
+
+	return resyncPeriod
+}
+
+// getObjectGetter Get object getter using informers caches when available or Kubernetes API otherwise.
+func (context *Context) getObjectGetter(runCtx ctx.Context) resources.ObjectGetter {
+	context.informersMutex.RLock()
+	namespaceInformer := context.namespaceInformer
+	podInformers := context.podInformers
+	storageClassInformer := context.storageClassInformer
+	ownerCache := context.ownerCache
+	context.informersMutex.RUnlock()
+
+	if namespaceInformer == nil || podInformers == nil || storageClassInformer == nil {
+		return resources.NewClientObjectGetter(runCtx, context.KubernetesClient)
+	}
+
+	podIndexers := make(map[string]cache.Indexer, len(podInformers))
+	for namespace, podInformer := range podInformers {
+		podIndexers[namespace] = podInformer.GetIndexer()
+	}
+
+	return resources.NewListerObjectGetter(
+		runCtx,
+		context.KubernetesClient,
+		corelisters.NewNamespaceLister(namespaceInformer.GetIndexer()),
+		podIndexers,
+		storagelisters.NewStorageClassLister(storageClassInformer.GetIndexer()),
+		ownerCache,
+	)
+}
+
+// getWorkContext Get context of calls done by watch handlers.
+// Background context is returned when not watching.
+func (context *Context) getWorkContext() ctx.Context {
+	context.informersMutex.RLock()
+	defer context.informersMutex.RUnlock()
+
+	if context.workCtx == nil {
+		return ctx.Background()
+	}
+
+	return context.workCtx
+}
+
+// Reconcile Run tags management on all watched objects with workers.
+// Nothing is done when objects aren't watched yet.
+func (context *Context) Reconcile() {
+	context.informersMutex.RLock()
+	persistentVolumeInformer := context.persistentVolumeInformer
+	serviceInformers := context.serviceInformers
+	context.informersMutex.RUnlock()
+
+	if persistentVolumeInformer == nil {
+		logrus.Debug("Objects aren't watched, reconcile ignored")
+
+		return
+	}
+
+	logrus.Info("Begin full reconcile")
+
+	for _, obj := range persistentVolumeInformer.GetStore().List() {
+		context.enqueue(persistentVolumeKind, obj)
+	}
+
+	for _, serviceInformer := range serviceInformers {
+		for _, obj := range serviceInformer.GetStore().List() {
+			context.enqueue(serviceKind, obj)
+		}
+	}
 
-	informerFactory.Start(wait.NeverStop)
+	logrus.Info("All objects queued for full reconcile")
 }
@@ -1,19 +1,102 @@
 package business
 
 import (
+	ctx "context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/audit"
 	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/metrics"
 	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/resources"
 	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/rules"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/tags"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/tracing"
 	"github.com/sirupsen/logrus"
+	oteltrace "go.opentelemetry.io/otel/trace"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// Kubernetes object kinds used in metrics.
+const (
+	persistentVolumeKind = "persistentvolume"
+	serviceKind          = "service"
 )
 
 // Context Business context.
 type Context struct {
 	KubernetesClient *kubernetes.Clientset
-	Configuration    *config.Configuration
-	Rules            []*rules.Rule
+	DynamicClient    dynamic.Interface
+	EventRecorder    record.EventRecorder
+	// Configuration snapshot, must be accessed with GetSnapshot and SetSnapshot
+	snapshot atomic.Value
+	// Informers created by Watch, used for full reconcile
+	informersMutex           sync.RWMutex
+	persistentVolumeInformer cache.SharedIndexInformer
+	serviceInformers         []cache.SharedIndexInformer
+	namespaceInformer        cache.SharedIndexInformer
+	podInformers             map[string]cache.SharedIndexInformer
+	storageClassInformer     cache.SharedIndexInformer
+	policyInformers          []cache.SharedIndexInformer
+	// Owners of pods got from Kubernetes API during watch
+	ownerCache *resources.OwnerCache
+	// Objects waiting for reconcile workers
+	queue workqueue.RateLimitingInterface
+	// Cancelled by StopWatch to stop informers, workers and sweeps
+	stopWatch ctx.CancelFunc
+	// Used by cloud and Kubernetes calls of workers, cancelled by StopWatch once in-flight work is drained
+	workCtx  ctx.Context
+	stopWork ctx.CancelFunc
+	// Workers and sweeps of the current watch, waited by StopWatch
+	workers *sync.WaitGroup
+	// Tagging policies by key, accessed with policies functions
+	policiesMutex sync.RWMutex
+	policies      map[string]*activePolicy
+	// Required tags compliance by Kubernetes object reference, accessed with compliance functions
+	complianceMutex sync.RWMutex
+	compliance      map[string]*complianceEntry
+	// Health status, accessed with health functions
+	healthMutex        sync.RWMutex
+	leader             bool
+	configurationError error
+	// Last provider credentials check result
+	providerCheckMutex sync.Mutex
+	providerCheckTime  time.Time
+	providerCheckError error
+	// Last cluster facts discovery result, accessed with getClusterFacts
+	clusterMutex      sync.Mutex
+	clusterFacts      *resources.ClusterFacts
+	clusterFactsTime  time.Time
+	clusterFactsError error
+	// Closed when running discovery ends, nil without running discovery
+	clusterDiscovery chan struct{}
+	// Incremented on invalidation to drop running discovery result
+	clusterFactsGeneration int
+	// Last cloud sweep report, accessed with GetSweepReport
+	sweepMutex  sync.RWMutex
+	sweepReport *SweepReport
+	// Deleted objects whose on delete actions have been run by finalizer, accessed with cleanup functions
+	cleanupMutex sync.Mutex
+	cleanedUp    map[string]bool
+	// Watch scope of persistent volumes at their last reconcile, accessed with scope functions
+	scopeMutex   sync.Mutex
+	volumesScope map[string]bool
+	// Objects hashes at last successful reconcile, accessed with unchanged functions
+	unchangedMutex sync.Mutex
+	unchanged      map[string]*unchangedEntry
+	rulesVersion   uint64
+	// Audit logger of the last audit configuration, accessed with getAuditLogger
+	auditMutex       sync.Mutex
+	auditConfig      *config.AuditConfig
+	auditLogger      *audit.Logger
+	auditLoggerError error
 }
 
 func (context *Context) handlePersistentVolumeAdd(obj interface{}) {
@@ -22,17 +105,30 @@ func (context *Context) handlePersistentVolumeAdd(obj interface{}) {
 
 	log.Debug("New persistent volume added detected")
 
-	err := context.runForPV(pv)
-	// Check error
-	if err != nil {
-		log.Errorf("Error managing persistent volume: %v", err)
-	}
+	context.enqueue(persistentVolumeKind, pv)
 }
 func (context *Context) handlePersistentVolumeDelete(obj interface{}) {
-	// Nothing to do
-	pv, _ := obj.(*v1.PersistentVolume)
+	pv, _ := getDeletedObject(obj).(*v1.PersistentVolume)
+	if pv == nil {
+		return
+	}
+
 	log := logrus.WithField("persistentVolumeName", pv.Name)
 	log.Debug("New persistent volume deleted detected")
+
+	context.forgetPolicyMatches(persistentVolumeReference(pv))
+	context.forgetCompliance(persistentVolumeReference(pv))
+	context.forgetUnchanged(persistentVolumeReference(pv))
+
+	// Actions have been run before deletion when persistent volume had the finalizer
+	if context.forgetCleanup(persistentVolumeReference(pv)) {
+		context.forgetVolumeScope(persistentVolumeReference(pv))
+
+		return
+	}
+
+	// Actions make cloud calls, they are run by workers to be retried on failure
+	context.enqueueDeleted(persistentVolumeKind, pv)
 }
 
 func (context *Context) handlePersistentVolumeUpdate(old, current interface{}) {
@@ -41,11 +137,7 @@ func (context *Context) handlePersistentVolumeUpdate(old, current interface{}) {
 
 	log.Debug("New persistent volume updated detected")
 
-	err := context.runForPV(currentPersistentVolume)
-	// Check error
-	if err != nil {
-		logrus.WithField("persistentVolumeName", currentPersistentVolume.Name).Errorf("Error managing persistent volume: %v", err)
-	}
+	context.enqueue(persistentVolumeKind, currentPersistentVolume)
 }
 
 func (context *Context) handleServiceAdd(obj interface{}) {
@@ -58,15 +150,13 @@ func (context *Context) handleServiceAdd(obj interface{}) {
 
 	log.Debug("New service added detected")
 
-	err := context.runForService(svc)
-	// Check error
-	if err != nil {
-		log.Errorf("Error managing service: %v", err)
-	}
+	context.enqueue(serviceKind, svc)
 }
 func (context *Context) handleServiceDelete(obj interface{}) {
-	// Nothing to do
-	svc, _ := obj.(*v1.Service)
+	svc, _ := getDeletedObject(obj).(*v1.Service)
+	if svc == nil {
+		return
+	}
 
 	log := logrus.WithFields(logrus.Fields{
 		"serviceName": svc.Name,
@@ -74,6 +164,18 @@ func (context *Context) handleServiceDelete(obj interface{}) {
 	})
 
 	log.Debug("New service deleted detected")
+
+	context.forgetPolicyMatches(serviceReference(svc))
+	context.forgetCompliance(serviceReference(svc))
+	context.forgetUnchanged(serviceReference(svc))
+
+	// Actions have been run before deletion when service had the finalizer
+	if context.forgetCleanup(serviceReference(svc)) {
+		return
+	}
+
+	// Actions make cloud calls, they are run by workers to be retried on failure
+	context.enqueueDeleted(serviceKind, svc)
 }
 
 func (context *Context) handleServiceUpdate(old, current interface{}) {
@@ -85,61 +187,287 @@ func (context *Context) handleServiceUpdate(old, current interface{}) {
 
 	log.Debug("New service updated detected")
 
-	err := context.runForService(currentService)
+	context.enqueue(serviceKind, currentService)
+}
+
+func (context *Context) runForPV(runCtx ctx.Context, pv *v1.PersistentVolume) error {
+	// Get configuration snapshot to use the same one during all the run
+	snapshot := context.GetSnapshot()
+	patcher := persistentVolumePatcher(runCtx, context.KubernetesClient, pv.Name)
+
+	// Deleting persistent volume only has on delete actions to run
+	if pv.DeletionTimestamp != nil {
+		log := logrus.WithField("persistentVolumeName", pv.Name)
+
+		return context.runCleanup(persistentVolumeReference(pv), pv, patcher, snapshot.Configuration, log, func() error {
+			return context.runOnDeleteForPV(runCtx, pv, snapshot)
+		})
+	}
+
+	// Skip cloud calls when nothing changed since last successful reconcile
+	hash, err := context.getPersistentVolumeHash(runCtx, pv, snapshot.Configuration)
+	if err != nil {
+		return err
+	}
+
+	if context.isUnchanged(persistentVolumeReference(pv), hash, snapshot.Configuration) {
+		logrus.WithField("persistentVolumeName", pv.Name).Debug("Persistent volume ignored because unchanged")
+		metrics.ObjectsProcessed.WithLabelValues(persistentVolumeKind, metrics.UnchangedResult).Inc()
+
+		return nil
+	}
+
+	// Check if persistent volume is in the watch scope
+	watched, err := isPersistentVolumeWatched(runCtx, context.KubernetesClient, pv, snapshot.Configuration)
 	// Check error
 	if err != nil {
-		logrus.WithField("serviceName", currentService.Name).Errorf("Error managing service: %v", err)
+		metrics.ObjectsProcessed.WithLabelValues(persistentVolumeKind, metrics.FailureResult).Inc()
+
+		return err
 	}
-}
 
-func (context *Context) runForPV(pv *v1.PersistentVolume) error {
-	resource, err := resources.NewFromPersistentVolume(context.KubernetesClient, pv, context.Configuration)
+	// Claim may be deleted before the persistent volume, scope is needed for on delete actions
+	context.recordVolumeScope(persistentVolumeReference(pv), watched)
+
+	if !watched {
+		logrus.WithField("persistentVolumeName", pv.Name).Debug("Persistent volume ignored because not in watch scope")
+		metrics.ObjectsProcessed.WithLabelValues(persistentVolumeKind, metrics.IgnoredResult).Inc()
+		// Persistent volume may have left the watch scope
+		context.forgetCompliance(persistentVolumeReference(pv))
+
+		return updateCleanupFinalizer(pv, patcher, false)
+	}
+
+	resource, err := resources.NewFromPersistentVolume(runCtx, context.KubernetesClient, context.getObjectGetter(runCtx), pv, snapshot.Configuration)
 	// Check error
 	if err != nil {
+		metrics.ObjectsProcessed.WithLabelValues(persistentVolumeKind, metrics.FailureResult).Inc()
+
 		return err
 	}
 
-	return context.runForResource(resource)
-}
+	// Add finalizer before managing tags to never miss the deletion
+	finalizerNeeded := resource != nil && snapshot.Configuration.OnDelete.IsFinalizerEnabled() &&
+		getVolumeOnDeleteActions(snapshot.Configuration, pv) != nil
+
+	err = updateCleanupFinalizer(pv, patcher, finalizerNeeded)
+	if err != nil {
+		return err
+	}
+
+	namespace := persistentVolumeNamespace(pv)
 
-func (context *Context) runForService(svc *v1.Service) error {
-	resource, err := resources.NewFromService(context.KubernetesClient, svc, context.Configuration)
+	unmanagedTags, err := context.runForResource(runCtx, persistentVolumeKind, persistentVolumeReference(pv), namespace, resource, snapshot)
 	// Check error
 	if err != nil {
 		return err
 	}
 
-	return context.runForResource(resource)
+	// Copy cloud tags to Kubernetes objects
+	err = reverseSyncPersistentVolume(runCtx, context.KubernetesClient, pv, snapshot.Configuration, unmanagedTags)
+	if err != nil {
+		return err
+	}
+
+	context.recordUnchanged(persistentVolumeReference(pv), hash, snapshot.Configuration)
+
+	return nil
 }
 
-func (context *Context) runForResource(resource resources.Resource) error {
-	if resource == nil {
-		// No resource available
+func (context *Context) runForService(runCtx ctx.Context, svc *v1.Service) error {
+	// Get configuration snapshot to use the same one during all the run
+	snapshot := context.GetSnapshot()
+	patcher := servicePatcher(runCtx, context.KubernetesClient, svc.Namespace, svc.Name)
+
+	// Deleting service only has on delete actions to run
+	if svc.DeletionTimestamp != nil {
+		log := logrus.WithFields(logrus.Fields{"serviceName": svc.Name, "namespace": svc.Namespace})
+
+		return context.runCleanup(serviceReference(svc), svc, patcher, snapshot.Configuration, log, func() error {
+			return context.runOnDeleteForService(runCtx, svc, snapshot)
+		})
+	}
+
+	// Skip cloud calls when nothing changed since last successful reconcile
+	hash, err := context.getServiceHash(runCtx, svc, snapshot.Configuration)
+	if err != nil {
+		return err
+	}
+
+	if context.isUnchanged(serviceReference(svc), hash, snapshot.Configuration) {
+		logrus.WithFields(logrus.Fields{"serviceName": svc.Name, "namespace": svc.Namespace}).Debug("Service ignored because unchanged")
+		metrics.ObjectsProcessed.WithLabelValues(serviceKind, metrics.UnchangedResult).Inc()
+
 		return nil
 	}
 
-	// Get actual tags
-	actualTags, err := resource.GetActualTags()
+	resource, err := resources.NewFromService(runCtx, context.KubernetesClient, context.getObjectGetter(runCtx), svc, snapshot.Configuration)
+	// Check error
 	if err != nil {
+		metrics.ObjectsProcessed.WithLabelValues(serviceKind, metrics.FailureResult).Inc()
+
 		return err
 	}
 
-	availableTagValues, err := resource.GetAvailableTagValues()
+	// Add finalizer before managing tags to never miss the deletion
+	finalizerNeeded := resource != nil && snapshot.Configuration.OnDelete.IsFinalizerEnabled() &&
+		getLoadBalancerOnDeleteActions(snapshot.Configuration) != nil
+
+	err = updateCleanupFinalizer(svc, patcher, finalizerNeeded)
 	if err != nil {
 		return err
 	}
 
-	delta, err := rules.CalculateTags(actualTags, availableTagValues, context.Rules)
+	unmanagedTags, err := context.runForResource(runCtx, serviceKind, serviceReference(svc), svc.Namespace, resource, snapshot)
 	// Check error
 	if err != nil {
 		return err
 	}
 
-	err = resource.ManageTags(delta)
-	// Check error
+	// Copy cloud tags to Kubernetes objects
+	err = reverseSyncService(runCtx, context.KubernetesClient, svc, snapshot.Configuration, unmanagedTags)
 	if err != nil {
 		return err
 	}
 
+	context.recordUnchanged(serviceReference(svc), hash, snapshot.Configuration)
+
 	return nil
 }
+
+// runForResource Manage resource tags and return tags on resource not managed by rules.
+func (context *Context) runForResource(
+	runCtx ctx.Context,
+	kind, reference, namespace string,
+	resource resources.Resource,
+	snapshot *Snapshot,
+) ([]*tags.Tag, error) {
+	if resource == nil {
+		// No resource available
+		metrics.ObjectsProcessed.WithLabelValues(kind, metrics.UnsupportedResult).Inc()
+
+		return nil, nil
+	}
+
+	// Observe run duration
+	start := time.Now()
+	defer func() {
+		metrics.ResourceRunDuration.WithLabelValues(resource.Type(), resource.Platform()).Observe(time.Since(start).Seconds())
+	}()
+
+	// Reconcile span is started by caller to have resource calls in it
+	setResourceSpanAttributes(runCtx, resource)
+
+	// Configuration rules with tagging policies rules
+	rulesList, origins := context.getRules(snapshot, resource.Type(), namespace)
+
+	clusterFacts := context.getClusterFacts(runCtx, snapshot.Configuration)
+
+	actualTags, delta, trace, err := calculateDelta(runCtx, resource, rulesList, snapshot.Configuration.Cluster, clusterFacts)
+	// Check error
+	if err != nil {
+		metrics.ObjectsProcessed.WithLabelValues(kind, metrics.FailureResult).Inc()
+
+		return nil, err
+	}
+
+	oteltrace.SpanFromContext(runCtx).SetAttributes(tracing.DeltaSizeKey.Int(delta.Size()))
+
+	context.recordPolicyMatches(reference, origins, trace)
+
+	auditRecord := newAuditRecord(audit.SourceReconcile, reference, resource, getAuditChanges(delta.Changes(actualTags), trace, origins))
+
+	err = context.manageTags(resource, delta, auditRecord, snapshot.Configuration)
+	// Check error
+	if err != nil {
+		metrics.ObjectsProcessed.WithLabelValues(kind, metrics.FailureResult).Inc()
+
+		return nil, err
+	}
+
+	metrics.ObjectsProcessed.WithLabelValues(kind, metrics.SuccessResult).Inc()
+
+	// Tags on resource after this run
+	resourceTags := delta.Apply(actualTags)
+
+	err = context.recordCompliance(reference, resource, snapshot.Configuration.Compliance, resourceTags)
+	// Check error
+	if err != nil {
+		return nil, err
+	}
+
+	// Tags on resource after this run that rules don't manage
+	return getUnmanagedTags(resourceTags, rulesList), nil
+}
+
+// calculateDelta Calculate tags delta for resource and return it with actual tags and evaluation trace.
+func calculateDelta(
+	runCtx ctx.Context,
+	resource resources.Resource,
+	rulesList []*rules.Rule,
+	clusterValues map[string]string,
+	clusterFacts *resources.ClusterFacts,
+) ([]*tags.Tag, *tags.TagDelta, *rules.Trace, error) {
+	// Get actual tags
+	actualTags, err := resource.GetActualTags()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Resource calls use the context of its creation, they are already children of the run span
+	availableTagValues, err := resource.GetAvailableTagValues()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	resources.AddClusterTagValues(availableTagValues, clusterValues, clusterFacts)
+
+	_, rulesSpan := tracing.StartSpan(runCtx, "rules evaluation", tracing.RulesCountKey.Int(len(rulesList)))
+	delta, trace, err := rules.CalculateTagsWithTrace(actualTags, availableTagValues, rulesList)
+
+	if err == nil {
+		rulesSpan.SetAttributes(tracing.DeltaSizeKey.Int(delta.Size()))
+	}
+
+	tracing.EndSpan(rulesSpan, err)
+
+	// Check error
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Count rules with matching conditions
+	for _, ruleTrace := range trace.Rules {
+		if ruleTrace.Reason == rules.TraceReasonConditionsNotMatched {
+			continue
+		}
+
+		ruleLabel := ruleTrace.Name
+		if ruleLabel == "" {
+			ruleLabel = strconv.Itoa(ruleTrace.Index)
+		}
+
+		metrics.RuleMatches.WithLabelValues(ruleLabel).Inc()
+	}
+
+	return actualTags, delta, trace, nil
+}
+
+// persistentVolumeReference Reference of persistent volume used in policies matches.
+func persistentVolumeReference(pv *v1.PersistentVolume) string {
+	return PersistentVolumeReferencePrefix + "/" + pv.Name
+}
+
+// persistentVolumeNamespace Namespace of persistent volume used to select its rules, the claim one.
+func persistentVolumeNamespace(pv *v1.PersistentVolume) string {
+	if pv.Spec.ClaimRef == nil {
+		return ""
+	}
+
+	return pv.Spec.ClaimRef.Namespace
+}
+
+// serviceReference Reference of service used in policies matches.
+func serviceReference(svc *v1.Service) string {
+	return ServiceReferencePrefix + "/" + svc.Namespace + "/" + svc.Name
+}
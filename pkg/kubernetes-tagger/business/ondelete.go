@@ -0,0 +1,270 @@
+package business
+
+import (
+	ctx "context"
+	"time"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/audit"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	providerclient "github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/providerClient"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/resources"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/rules"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/tags"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/tracing"
+	"github.com/sirupsen/logrus"
+	"github.com/thoas/go-funk"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// getDeletedObject Get deleted object from informer delete event.
+// Object can be a tombstone when delete is missed (after a watch disconnection for example).
+func getDeletedObject(obj interface{}) interface{} {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		return tombstone.Obj
+	}
+
+	return obj
+}
+
+// getVolumeOnDeleteActions Get on delete actions of persistent volume, nil when nothing must be done.
+func getVolumeOnDeleteActions(cfg *config.Configuration, pv *v1.PersistentVolume) *config.OnDeleteActionsConfig {
+	if cfg.OnDelete == nil {
+		return nil
+	}
+
+	// Cloud volume is deleted with the persistent volume
+	if pv.Spec.PersistentVolumeReclaimPolicy == v1.PersistentVolumeReclaimDelete {
+		return nil
+	}
+
+	return cfg.OnDelete.Volume
+}
+
+// getLoadBalancerOnDeleteActions Get on delete actions of load balancer services, nil when nothing must be done.
+func getLoadBalancerOnDeleteActions(cfg *config.Configuration) *config.OnDeleteActionsConfig {
+	if cfg.OnDelete == nil {
+		return nil
+	}
+
+	return cfg.OnDelete.LoadBalancer
+}
+
+// runOnDeleteItem Run on delete actions of a queued deleted object.
+// Missing cloud resources aren't retried because actions can't be applied anymore.
+func (context *Context) runOnDeleteItem(workCtx ctx.Context, item queueItem) error {
+	var (
+		runCtx ctx.Context
+		span   oteltrace.Span
+		err    error
+		log    *logrus.Entry
+	)
+
+	switch obj := item.deleted.(type) {
+	case *v1.PersistentVolume:
+		runCtx, span = startOnDeleteSpan(workCtx, persistentVolumeKind, persistentVolumeReference(obj))
+		err = context.runOnDeleteForPV(runCtx, obj, context.GetSnapshot())
+		log = logrus.WithContext(runCtx).WithField("persistentVolumeName", obj.Name)
+	case *v1.Service:
+		runCtx, span = startOnDeleteSpan(workCtx, serviceKind, serviceReference(obj))
+		err = context.runOnDeleteForService(runCtx, obj, context.GetSnapshot())
+		log = logrus.WithContext(runCtx).WithFields(logrus.Fields{"serviceName": obj.Name, "namespace": obj.Namespace})
+	default:
+		return nil
+	}
+
+	tracing.EndSpan(span, err)
+
+	switch {
+	case err == nil:
+	case providerclient.IsNotFoundError(err):
+		log.Infof("Cloud resource of deleted object not found, on delete actions are skipped: %v", err)
+	default:
+		log.Errorf("Error managing deleted object: %v", err)
+
+		return err
+	}
+
+	// Scope is used by on delete actions until they succeed
+	if pv, ok := item.deleted.(*v1.PersistentVolume); ok {
+		context.forgetVolumeScope(persistentVolumeReference(pv))
+	}
+
+	return nil
+}
+
+func (context *Context) runOnDeleteForPV(runCtx ctx.Context, pv *v1.PersistentVolume, snapshot *Snapshot) error {
+	actionsCfg := getVolumeOnDeleteActions(snapshot.Configuration, pv)
+	if actionsCfg == nil {
+		logrus.WithField("persistentVolumeName", pv.Name).Debug("No on delete actions for persistent volume")
+
+		return nil
+	}
+
+	// Check if persistent volume was in the watch scope
+	watched, err := context.wasPersistentVolumeWatched(runCtx, pv, snapshot.Configuration)
+	if err != nil {
+		return err
+	}
+
+	if !watched {
+		logrus.WithField("persistentVolumeName", pv.Name).Debug("No on delete actions for persistent volume not in watch scope")
+
+		return nil
+	}
+
+	resource, err := resources.NewFromPersistentVolume(runCtx, context.KubernetesClient, context.getObjectGetter(runCtx), pv, snapshot.Configuration)
+	// Check error
+	if err != nil {
+		return err
+	}
+
+	namespace := persistentVolumeNamespace(pv)
+
+	return context.runOnDeleteForResource(runCtx, resource, persistentVolumeReference(pv), namespace, getDeletionTime(&pv.ObjectMeta), actionsCfg, snapshot)
+}
+
+// wasPersistentVolumeWatched Checks if a deleted persistent volume was in the watch scope from its last known state.
+// Finalizer is only added on watched persistent volumes. Without it, scope recorded at last reconcile is used,
+// then claim is checked when it still exists.
+func (context *Context) wasPersistentVolumeWatched(runCtx ctx.Context, pv *v1.PersistentVolume, cfg *config.Configuration) (bool, error) {
+	if funk.ContainsString(pv.Finalizers, CleanupFinalizer) {
+		return true, nil
+	}
+
+	if watched, ok := context.getVolumeScope(persistentVolumeReference(pv)); ok {
+		return watched, nil
+	}
+
+	return isDeletedPersistentVolumeWatched(runCtx, context.KubernetesClient, pv, cfg)
+}
+
+// recordVolumeScope Save if a persistent volume is in the watch scope.
+func (context *Context) recordVolumeScope(reference string, watched bool) {
+	context.scopeMutex.Lock()
+	defer context.scopeMutex.Unlock()
+
+	if context.volumesScope == nil {
+		context.volumesScope = make(map[string]bool)
+	}
+
+	context.volumesScope[reference] = watched
+}
+
+// getVolumeScope Get if a persistent volume was in the watch scope at its last reconcile, false is returned when unknown.
+func (context *Context) getVolumeScope(reference string) (watched, ok bool) {
+	context.scopeMutex.Lock()
+	defer context.scopeMutex.Unlock()
+
+	watched, ok = context.volumesScope[reference]
+
+	return watched, ok
+}
+
+// forgetVolumeScope Remove the saved watch scope of a deleted persistent volume.
+func (context *Context) forgetVolumeScope(reference string) {
+	context.scopeMutex.Lock()
+	defer context.scopeMutex.Unlock()
+
+	delete(context.volumesScope, reference)
+}
+
+func (context *Context) runOnDeleteForService(runCtx ctx.Context, svc *v1.Service, snapshot *Snapshot) error {
+	actionsCfg := getLoadBalancerOnDeleteActions(snapshot.Configuration)
+	if actionsCfg == nil {
+		return nil
+	}
+
+	resource, err := resources.NewFromService(runCtx, context.KubernetesClient, context.getObjectGetter(runCtx), svc, snapshot.Configuration)
+	// Check error
+	if err != nil {
+		return err
+	}
+
+	return context.runOnDeleteForResource(runCtx, resource, serviceReference(svc), svc.Namespace, getDeletionTime(&svc.ObjectMeta), actionsCfg, snapshot)
+}
+
+// runOnDeleteForResource Apply on delete actions on resource of a deleted Kubernetes object.
+func (context *Context) runOnDeleteForResource(
+	runCtx ctx.Context,
+	resource resources.Resource,
+	reference, namespace string,
+	deletionTime time.Time,
+	actionsCfg *config.OnDeleteActionsConfig,
+	snapshot *Snapshot,
+) error {
+	if resource == nil {
+		// No resource available
+		return nil
+	}
+
+	// Run span is started by caller to have resource calls in it
+	setResourceSpanAttributes(runCtx, resource)
+
+	actualTags, err := resource.GetActualTags()
+	if err != nil {
+		return err
+	}
+
+	// Configuration rules with tagging policies rules
+	rulesList, _ := context.getRules(snapshot, resource.Type(), namespace)
+
+	delta := getOnDeleteDelta(actionsCfg, actualTags, rulesList, deletionTime)
+	oteltrace.SpanFromContext(runCtx).SetAttributes(tracing.DeltaSizeKey.Int(delta.Size()))
+
+	if len(delta.AddList) == 0 && len(delta.DeleteList) == 0 {
+		return nil
+	}
+
+	auditRecord := newAuditRecord(audit.SourceOnDelete, reference, resource, getAuditChanges(delta.Changes(actualTags), nil, nil))
+
+	return context.manageTags(resource, delta, auditRecord, snapshot.Configuration)
+}
+
+// getOnDeleteDelta Calculate tags delta from on delete actions.
+// Added tags aren't removed even if they are managed by rules.
+func getOnDeleteDelta(
+	actionsCfg *config.OnDeleteActionsConfig,
+	actualTags []*tags.Tag,
+	rulesList []*rules.Rule,
+	deletionTime time.Time,
+) *tags.TagDelta {
+	delta := &tags.TagDelta{AddList: make([]*tags.Tag, 0), DeleteList: make([]*tags.Tag, 0)}
+	addedKeys := make(map[string]bool)
+
+	for _, tag := range actionsCfg.AddTags {
+		delta.AddList = append(delta.AddList, &tags.Tag{Key: tag.Key, Value: tag.Value})
+		addedKeys[tag.Key] = true
+	}
+
+	if actionsCfg.TimestampTag != "" {
+		delta.AddList = append(delta.AddList, &tags.Tag{Key: actionsCfg.TimestampTag, Value: deletionTime.UTC().Format(time.RFC3339)})
+		addedKeys[actionsCfg.TimestampTag] = true
+	}
+
+	if actionsCfg.RemoveManagedTags {
+		managedKeys := make(map[string]bool)
+		for _, rule := range rulesList {
+			managedKeys[rule.Tag] = true
+		}
+
+		for _, tag := range actualTags {
+			if managedKeys[tag.Key] && !addedKeys[tag.Key] {
+				delta.DeleteList = append(delta.DeleteList, tag)
+			}
+		}
+	}
+
+	return delta
+}
+
+// getDeletionTime Get object deletion time, now when it isn't set.
+func getDeletionTime(objectMeta *metav1.ObjectMeta) time.Time {
+	if objectMeta.DeletionTimestamp != nil {
+		return objectMeta.DeletionTimestamp.Time
+	}
+
+	return time.Now()
+}
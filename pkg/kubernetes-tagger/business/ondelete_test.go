@@ -0,0 +1,135 @@
+package business
+
+import (
+	ctx "context"
+	"testing"
+	"time"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/rules"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/tags"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func TestGetDeletedObject(t *testing.T) {
+	pv := &v1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv1"}}
+
+	assert.Equal(t, pv, getDeletedObject(pv))
+	assert.Equal(t, pv, getDeletedObject(cache.DeletedFinalStateUnknown{Key: "pv1", Obj: pv}))
+}
+
+func TestContext_handleDeleteWithTombstone(t *testing.T) {
+	context := &Context{}
+	context.SetSnapshot(&Snapshot{Configuration: &config.Configuration{}})
+
+	// Tombstones and unknown objects mustn't panic
+	assert.NotPanics(t, func() {
+		context.handlePersistentVolumeDelete(cache.DeletedFinalStateUnknown{
+			Key: "pv1",
+			Obj: &v1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv1"}},
+		})
+		context.handleServiceDelete(cache.DeletedFinalStateUnknown{
+			Key: "default/web",
+			Obj: &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}},
+		})
+		context.handleServiceDelete(cache.DeletedFinalStateUnknown{Key: "default/web"})
+	})
+}
+
+func TestContext_handleDeleteEnqueue(t *testing.T) {
+	context := &Context{queue: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())}
+	context.SetSnapshot(&Snapshot{Configuration: &config.Configuration{}})
+
+	pv := &v1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv1"}}
+	context.recordVolumeScope(persistentVolumeReference(pv), true)
+
+	// Actions are run by workers with the deleted object
+	context.handlePersistentVolumeDelete(cache.DeletedFinalStateUnknown{Key: "pv1", Obj: pv})
+	assert.Equal(t, 1, context.queue.Len())
+
+	item, _ := context.queue.Get()
+	assert.Equal(t, queueItem{kind: persistentVolumeKind, key: "pv1", deleted: pv}, item)
+	context.queue.Done(item)
+
+	// Scope is kept until actions are run
+	_, ok := context.getVolumeScope(persistentVolumeReference(pv))
+	assert.True(t, ok)
+
+	context.queue.Add(item)
+	assert.True(t, context.processNextItem(make(chan struct{}), ctx.Background(), context.queue))
+	assert.Equal(t, 0, context.queue.Len())
+
+	_, ok = context.getVolumeScope(persistentVolumeReference(pv))
+	assert.False(t, ok)
+
+	// Actions have already been run by the finalizer
+	svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}
+	context.recordCleanup(serviceReference(svc))
+	context.handleServiceDelete(svc)
+	assert.Equal(t, 0, context.queue.Len())
+}
+
+func TestGetOnDeleteDelta(t *testing.T) {
+	deletionTime := time.Date(2021, 12, 1, 10, 0, 0, 0, time.FixedZone("CET", 3600))
+	actualTags := []*tags.Tag{
+		{Key: "application", Value: "db"},
+		{Key: "orphaned", Value: "false"},
+		{Key: "CostCenter", Value: "1234"},
+	}
+	rulesList := []*rules.Rule{
+		{Tag: "application", Action: rules.RuleActionAdd},
+		{Tag: "orphaned", Action: rules.RuleActionAdd},
+	}
+
+	delta := getOnDeleteDelta(&config.OnDeleteActionsConfig{
+		AddTags:           []*config.TagConfig{{Key: "orphaned", Value: "true"}},
+		TimestampTag:      "kubernetes-tagger/orphaned-at",
+		RemoveManagedTags: true,
+	}, actualTags, rulesList, deletionTime)
+	assert.Equal(t, &tags.TagDelta{
+		AddList: []*tags.Tag{
+			{Key: "orphaned", Value: "true"},
+			{Key: "kubernetes-tagger/orphaned-at", Value: "2021-12-01T09:00:00Z"},
+		},
+		DeleteList: []*tags.Tag{{Key: "application", Value: "db"}},
+	}, delta)
+
+	// Nothing to do
+	delta = getOnDeleteDelta(&config.OnDeleteActionsConfig{}, actualTags, rulesList, deletionTime)
+	assert.Empty(t, delta.AddList)
+	assert.Empty(t, delta.DeleteList)
+}
+
+func TestContext_wasPersistentVolumeWatched(t *testing.T) {
+	context := &Context{}
+	cfg := &config.Configuration{LabelSelectors: &config.LabelSelectorsConfig{PersistentVolumeClaims: "team=a"}}
+
+	// Finalizer is only added on watched persistent volumes
+	pv := &v1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv1", Finalizers: []string{CleanupFinalizer}}}
+	watched, err := context.wasPersistentVolumeWatched(ctx.Background(), pv, cfg)
+	assert.Nil(t, err)
+	assert.True(t, watched)
+
+	// Scope of last reconcile is used
+	pv = &v1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv1"}}
+	context.recordVolumeScope(persistentVolumeReference(pv), false)
+
+	watched, err = context.wasPersistentVolumeWatched(ctx.Background(), pv, cfg)
+	assert.Nil(t, err)
+	assert.False(t, watched)
+
+	context.recordVolumeScope(persistentVolumeReference(pv), true)
+
+	watched, err = context.wasPersistentVolumeWatched(ctx.Background(), pv, cfg)
+	assert.Nil(t, err)
+	assert.True(t, watched)
+
+	context.forgetVolumeScope(persistentVolumeReference(pv))
+
+	_, ok := context.getVolumeScope(persistentVolumeReference(pv))
+	assert.False(t, ok)
+}
@@ -0,0 +1,175 @@
+package business
+
+import (
+	ctx "context"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/resources"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/tags"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// PersistentVolumeReferencePrefix Persistent volume reference prefix.
+const PersistentVolumeReferencePrefix = "pv"
+
+// ServiceReferencePrefix Service reference prefix.
+const ServiceReferencePrefix = "svc"
+
+// ResourcePlan Tag changes planned on a resource.
+type ResourcePlan struct {
+	// Kubernetes object reference like pv/<name> or svc/<namespace>/<name>
+	Reference string         `json:"reference"`
+	Type      string         `json:"type"`
+	Platform  string         `json:"platform"`
+	Changes   []*tags.Change `json:"changes"`
+	Error     string         `json:"error,omitempty"`
+}
+
+// Plan Calculate tag changes for all watched objects without applying them.
+// Rules are the ones applied by reconciles, with tagging policies ones.
+func Plan(runCtx ctx.Context, k8sClient kubernetes.Interface, snapshot *Snapshot, getRules RulesGetter) ([]*ResourcePlan, error) {
+	// Discovery errors are logged, missing facts are empty
+	clusterFacts, _ := discoverClusterFacts(runCtx, k8sClient, resources.NewClientObjectGetter(runCtx, k8sClient), snapshot.Configuration)
+
+	pvPlans, err := planPersistentVolumes(runCtx, k8sClient, snapshot, getRules, clusterFacts)
+	if err != nil {
+		return nil, err
+	}
+
+	svcPlans, err := planServices(runCtx, k8sClient, snapshot, getRules, clusterFacts)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(pvPlans, svcPlans...), nil
+}
+
+func planPersistentVolumes(
+	runCtx ctx.Context,
+	k8sClient kubernetes.Interface,
+	snapshot *Snapshot,
+	getRules RulesGetter,
+	clusterFacts *resources.ClusterFacts,
+) ([]*ResourcePlan, error) {
+	plans := make([]*ResourcePlan, 0)
+	options := listOptions(persistentVolumeTweakListOptions(snapshot.Configuration))
+
+	pvList, err := k8sClient.CoreV1().PersistentVolumes().List(runCtx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range pvList.Items {
+		pv := &pvList.Items[i]
+
+		var watched bool
+
+		watched, err = isPersistentVolumeWatched(runCtx, k8sClient, pv, snapshot.Configuration)
+		if err != nil {
+			return nil, err
+		}
+
+		if !watched {
+			continue
+		}
+
+		var resource resources.Resource
+
+		resource, err = resources.NewFromPersistentVolume(runCtx, k8sClient, resources.NewClientObjectGetter(runCtx, k8sClient), pv, snapshot.Configuration)
+
+		plan := planResource(runCtx, persistentVolumeReference(pv), persistentVolumeNamespace(pv), resource, err, snapshot, getRules, clusterFacts)
+		if plan != nil {
+			plans = append(plans, plan)
+		}
+	}
+
+	return plans, nil
+}
+
+func planServices(
+	runCtx ctx.Context,
+	k8sClient kubernetes.Interface,
+	snapshot *Snapshot,
+	getRules RulesGetter,
+	clusterFacts *resources.ClusterFacts,
+) ([]*ResourcePlan, error) {
+	plans := make([]*ResourcePlan, 0)
+	options := listOptions(serviceTweakListOptions(snapshot.Configuration))
+
+	for _, namespace := range getServiceNamespaces(snapshot.Configuration) {
+		svcList, err := k8sClient.CoreV1().Services(namespace).List(runCtx, options)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range svcList.Items {
+			svc := &svcList.Items[i]
+
+			var resource resources.Resource
+
+			resource, err = resources.NewFromService(runCtx, k8sClient, resources.NewClientObjectGetter(runCtx, k8sClient), svc, snapshot.Configuration)
+
+			plan := planResource(runCtx, serviceReference(svc), svc.Namespace, resource, err, snapshot, getRules, clusterFacts)
+			if plan != nil {
+				plans = append(plans, plan)
+			}
+		}
+	}
+
+	return plans, nil
+}
+
+// planResource Calculate tag changes for a resource.
+// Nil is returned when object isn't a supported resource.
+func planResource(
+	runCtx ctx.Context,
+	reference, namespace string,
+	resource resources.Resource,
+	resourceErr error,
+	snapshot *Snapshot,
+	getRules RulesGetter,
+	clusterFacts *resources.ClusterFacts,
+) *ResourcePlan {
+	plan := &ResourcePlan{Reference: reference, Changes: make([]*tags.Change, 0)}
+
+	// Check error
+	if resourceErr != nil {
+		plan.Error = resourceErr.Error()
+
+		return plan
+	}
+
+	if resource == nil {
+		return nil
+	}
+
+	plan.Type = resource.Type()
+	plan.Platform = resource.Platform()
+
+	rulesList, err := getRules(runCtx, resource.Type(), namespace)
+	// Check error
+	if err != nil {
+		plan.Error = err.Error()
+
+		return plan
+	}
+
+	actualTags, delta, _, err := calculateDelta(runCtx, resource, rulesList, snapshot.Configuration.Cluster, clusterFacts)
+	// Check error
+	if err != nil {
+		plan.Error = err.Error()
+
+		return plan
+	}
+
+	plan.Changes = delta.Changes(actualTags)
+
+	return plan
+}
+
+func listOptions(tweak func(*metav1.ListOptions)) metav1.ListOptions {
+	options := metav1.ListOptions{}
+	tweak(&options)
+
+	return options
+}
@@ -0,0 +1,37 @@
+package business
+
+import (
+	ctx "context"
+	"errors"
+	"testing"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclient "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPlanWithoutSupportedResources(t *testing.T) {
+	pv := &v1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv"}}
+	svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "default"}}
+	client := testclient.NewSimpleClientset(pv, svc)
+	snapshot := &Snapshot{Configuration: &config.Configuration{
+		Provider: config.AWSProviderName,
+		AWS:      &config.AWSConfig{Region: "eu-west-1"},
+	}}
+
+	plans, err := Plan(ctx.Background(), client, snapshot, NewRulesGetter(nil, snapshot))
+
+	assert.Nil(t, err)
+	assert.Empty(t, plans)
+}
+
+func TestPlanResourceWithError(t *testing.T) {
+	plan := planResource(ctx.Background(), "pv/test", "", nil, errors.New("fake"), &Snapshot{}, nil, nil)
+
+	assert.Equal(t, &ResourcePlan{Reference: "pv/test", Changes: plan.Changes, Error: "fake"}, plan)
+	assert.Empty(t, plan.Changes)
+
+	assert.Nil(t, planResource(ctx.Background(), "pv/test", "", nil, nil, &Snapshot{}, nil, nil))
+}
@@ -0,0 +1,393 @@
+package business
+
+import (
+	ctx "context"
+	"time"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/policies"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/rules"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// policyStatusInterval Interval between two policies status updates.
+const policyStatusInterval = time.Minute
+
+// activePolicy Policy watched with its rules and matching information.
+type activePolicy struct {
+	policy *policies.Policy
+	// Rules generated from policy, nil when policy is invalid
+	rules []*rules.Rule
+	errs  []string
+	// Kubernetes objects references with at least one rule matching
+	matched           map[string]bool
+	lastReconcileTime *metav1.Time
+}
+
+// watchPolicies Watch tagging policies and wait for caches to be synced.
+func (context *Context) watchPolicies(watchCtx ctx.Context, resyncPeriod time.Duration) {
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(context.DynamicClient, resyncPeriod)
+	handlers := cache.ResourceEventHandlerFuncs{
+		AddFunc:    context.handlePolicyAdd,
+		UpdateFunc: context.handlePolicyUpdate,
+		DeleteFunc: context.handlePolicyDelete,
+	}
+
+	policyInformers := make([]cache.SharedIndexInformer, 0)
+
+	for _, resource := range []schema.GroupVersionResource{
+		policies.TaggingPolicyResource,
+		policies.NamespaceTaggingPolicyResource,
+	} {
+		informer := factory.ForResource(resource).Informer()
+		informer.AddEventHandler(handlers)
+		policyInformers = append(policyInformers, informer)
+	}
+
+	context.informersMutex.Lock()
+	context.policyInformers = policyInformers
+	context.informersMutex.Unlock()
+
+	factory.Start(watchCtx.Done())
+
+	// Wait for policies before managing objects to apply all rules from the start
+	for resource, synced := range factory.WaitForCacheSync(watchCtx.Done()) {
+		if !synced {
+			logrus.WithField("resource", resource.String()).Error("Cannot sync tagging policies cache")
+		}
+	}
+
+	// Update policies status periodically
+	go wait.UntilWithContext(watchCtx, context.updatePoliciesStatus, policyStatusInterval)
+}
+
+func (context *Context) handlePolicyAdd(obj interface{}) {
+	context.upsertPolicy(obj)
+	context.invalidateUnchanged()
+}
+
+func (context *Context) handlePolicyUpdate(old, current interface{}) {
+	context.upsertPolicy(current)
+
+	// Resyncs and status updates (like the periodic ones of the tagger) don't change rules
+	oldPolicy, _ := old.(*unstructured.Unstructured)
+	currentPolicy, _ := current.(*unstructured.Unstructured)
+
+	if oldPolicy == nil || currentPolicy == nil || !equality.Semantic.DeepEqual(oldPolicy.Object["spec"], currentPolicy.Object["spec"]) {
+		context.invalidateUnchanged()
+	}
+}
+
+func (context *Context) handlePolicyDelete(obj interface{}) {
+	u, _ := getDeletedObject(obj).(*unstructured.Unstructured)
+	if u == nil {
+		return
+	}
+
+	policy, err := policies.FromUnstructured(u)
+	if err != nil {
+		logrus.Error(err)
+
+		return
+	}
+
+	logrus.WithField("policy", policy.Key()).Info("Tagging policy deleted")
+
+	context.policiesMutex.Lock()
+	delete(context.policies, policy.Key())
+	context.policiesMutex.Unlock()
+
+	context.invalidateUnchanged()
+}
+
+func (context *Context) upsertPolicy(obj interface{}) {
+	u, _ := obj.(*unstructured.Unstructured)
+	if u == nil {
+		return
+	}
+
+	policy, err := policies.FromUnstructured(u)
+	if err != nil {
+		logrus.Error(err)
+
+		return
+	}
+
+	log := logrus.WithField("policy", policy.Key())
+
+	// Validate policy rules
+	errs := policy.Validate()
+
+	var rulesList []*rules.Rule
+
+	if len(errs) == 0 {
+		rulesList, err = policy.Rules()
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) != 0 {
+		log.Warnf("Invalid tagging policy ignored: %v", errs)
+	} else {
+		log.Debug("Tagging policy loaded")
+	}
+
+	context.policiesMutex.Lock()
+
+	if context.policies == nil {
+		context.policies = make(map[string]*activePolicy)
+	}
+
+	// Keep matching information from previous version
+	ap := &activePolicy{policy: policy, rules: rulesList, errs: errs, matched: make(map[string]bool)}
+	if previous, ok := context.policies[policy.Key()]; ok {
+		ap.matched = previous.matched
+		ap.lastReconcileTime = previous.lastReconcileTime
+	}
+
+	context.policies[policy.Key()] = ap
+	context.policiesMutex.Unlock()
+
+	context.updatePolicyStatus(context.getWorkContext(), policy.Key())
+}
+
+// getRules Get rules to apply on a resource type in a namespace.
+// Configuration rules are first, then valid policies rules in policies evaluation order.
+// Returned origins give the policy key of each rule, empty for configuration rules.
+func (context *Context) getRules(snapshot *Snapshot, resourceType, namespace string) ([]*rules.Rule, []string) {
+	rulesList := make([]*rules.Rule, 0, len(snapshot.Rules))
+	origins := make([]string, 0, len(snapshot.Rules))
+
+	for _, rule := range snapshot.Rules {
+		rulesList = append(rulesList, rule)
+		origins = append(origins, "")
+	}
+
+	context.policiesMutex.RLock()
+	defer context.policiesMutex.RUnlock()
+
+	policiesList := make([]*policies.Policy, 0)
+
+	for _, ap := range context.policies {
+		if ap.rules != nil && ap.policy.AppliesTo(resourceType, namespace) {
+			policiesList = append(policiesList, ap.policy)
+		}
+	}
+
+	policies.Sort(policiesList)
+
+	for _, policy := range policiesList {
+		for _, rule := range context.policies[policy.Key()].rules {
+			rulesList = append(rulesList, rule)
+			origins = append(origins, policy.Key())
+		}
+	}
+
+	return rulesList, origins
+}
+
+// RulesGetter Get rules to apply on a resource type in a namespace, configuration rules first then tagging policies ones.
+type RulesGetter func(runCtx ctx.Context, resourceType, namespace string) ([]*rules.Rule, error)
+
+// GetAdmissionRules Get rules to apply on a resource type in a namespace to review objects on admission or explain them.
+// Policies are read from cache when this instance watches them, otherwise they are listed
+// because webhook and debug endpoints are served by all instances.
+func (context *Context) GetAdmissionRules(admissionCtx ctx.Context, resourceType, namespace string) ([]*rules.Rule, error) {
+	snapshot := context.GetSnapshot()
+
+	context.informersMutex.RLock()
+	watched := context.policyInformers != nil
+	context.informersMutex.RUnlock()
+
+	if watched || !snapshot.Configuration.TaggingPolicies || context.DynamicClient == nil {
+		rulesList, _ := context.getRules(snapshot, resourceType, namespace)
+
+		return rulesList, nil
+	}
+
+	return context.listRules(admissionCtx, snapshot, resourceType, namespace)
+}
+
+// NewRulesGetter Get rules getter for commands run outside of the tagger, tagging policies are listed with the dynamic client.
+// Only configuration rules are used when tagging policies are disabled.
+// Rules are listed once by resource type and namespace.
+func NewRulesGetter(dynamicClient dynamic.Interface, snapshot *Snapshot) RulesGetter {
+	if !snapshot.Configuration.TaggingPolicies || dynamicClient == nil {
+		return func(_ ctx.Context, _, _ string) ([]*rules.Rule, error) {
+			return snapshot.Rules, nil
+		}
+	}
+
+	context := &Context{DynamicClient: dynamicClient}
+	listed := make(map[string][]*rules.Rule)
+
+	return func(runCtx ctx.Context, resourceType, namespace string) ([]*rules.Rule, error) {
+		key := resourceType + "/" + namespace
+		if rulesList, ok := listed[key]; ok {
+			return rulesList, nil
+		}
+
+		rulesList, err := context.listRules(runCtx, snapshot, resourceType, namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		listed[key] = rulesList
+
+		return rulesList, nil
+	}
+}
+
+// listRules Get rules to apply on a resource type in a namespace from listed policies.
+// Invalid policies are ignored like watched ones.
+func (context *Context) listRules(
+	listCtx ctx.Context,
+	snapshot *Snapshot,
+	resourceType, namespace string,
+) ([]*rules.Rule, error) {
+	rulesList := append(make([]*rules.Rule, 0, len(snapshot.Rules)), snapshot.Rules...)
+	policiesList := make([]*policies.Policy, 0)
+	policiesRules := make(map[string][]*rules.Rule)
+
+	for _, resourceClient := range []dynamic.ResourceInterface{
+		context.DynamicClient.Resource(policies.TaggingPolicyResource),
+		context.DynamicClient.Resource(policies.NamespaceTaggingPolicyResource).Namespace(namespace),
+	} {
+		list, err := resourceClient.List(listCtx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range list.Items {
+			policy, err := policies.FromUnstructured(&list.Items[i])
+			if err != nil || len(policy.Validate()) != 0 || !policy.AppliesTo(resourceType, namespace) {
+				continue
+			}
+
+			policyRules, err := policy.Rules()
+			if err != nil {
+				continue
+			}
+
+			policiesList = append(policiesList, policy)
+			policiesRules[policy.Key()] = policyRules
+		}
+	}
+
+	policies.Sort(policiesList)
+
+	for _, policy := range policiesList {
+		rulesList = append(rulesList, policiesRules[policy.Key()]...)
+	}
+
+	return rulesList, nil
+}
+
+// recordPolicyMatches Save which policies matched a Kubernetes object from rules evaluation trace.
+func (context *Context) recordPolicyMatches(reference string, origins []string, trace *rules.Trace) {
+	matched := make(map[string]bool)
+
+	for i, origin := range origins {
+		if origin == "" {
+			continue
+		}
+
+		matched[origin] = matched[origin] || trace.Rules[i].Reason != rules.TraceReasonConditionsNotMatched
+	}
+
+	// Status times are stored with second precision
+	now := metav1.NewTime(time.Now().Truncate(time.Second))
+
+	context.policiesMutex.Lock()
+	defer context.policiesMutex.Unlock()
+
+	for key, isMatched := range matched {
+		ap, ok := context.policies[key]
+		if !ok {
+			continue
+		}
+
+		if isMatched {
+			ap.matched[reference] = true
+		} else {
+			delete(ap.matched, reference)
+		}
+
+		ap.lastReconcileTime = &now
+	}
+}
+
+// forgetPolicyMatches Remove a deleted Kubernetes object from policies matches.
+func (context *Context) forgetPolicyMatches(reference string) {
+	context.policiesMutex.Lock()
+	defer context.policiesMutex.Unlock()
+
+	for _, ap := range context.policies {
+		delete(ap.matched, reference)
+	}
+}
+
+func (context *Context) updatePoliciesStatus(statusCtx ctx.Context) {
+	context.policiesMutex.RLock()
+
+	keys := make([]string, 0, len(context.policies))
+	for key := range context.policies {
+		keys = append(keys, key)
+	}
+
+	context.policiesMutex.RUnlock()
+
+	for _, key := range keys {
+		context.updatePolicyStatus(statusCtx, key)
+	}
+}
+
+// updatePolicyStatus Update policy status in Kubernetes when it changed.
+func (context *Context) updatePolicyStatus(statusCtx ctx.Context, key string) {
+	context.policiesMutex.RLock()
+
+	ap, ok := context.policies[key]
+	if !ok {
+		context.policiesMutex.RUnlock()
+
+		return
+	}
+
+	policy := ap.policy
+	status := policy.NewStatus(ap.errs, len(ap.matched), ap.lastReconcileTime)
+	context.policiesMutex.RUnlock()
+
+	// Avoid useless updates, they would trigger a new policy update event
+	if equality.Semantic.DeepEqual(status, policy.Status) {
+		return
+	}
+
+	updated := *policy
+	updated.Status = status
+
+	u, err := updated.ToUnstructured()
+	if err != nil {
+		logrus.WithField("policy", key).Errorf("Cannot update tagging policy status: %v", err)
+
+		return
+	}
+
+	resource := policies.TaggingPolicyResource
+	if policy.IsNamespaced() {
+		resource = policies.NamespaceTaggingPolicyResource
+	}
+
+	_, err = context.DynamicClient.Resource(resource).Namespace(policy.Namespace).
+		UpdateStatus(statusCtx, u, metav1.UpdateOptions{})
+	if err != nil {
+		logrus.WithField("policy", key).Errorf("Cannot update tagging policy status: %v", err)
+	}
+}
@@ -0,0 +1,191 @@
+package business
+
+import (
+	ctx "context"
+	"testing"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/policies"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/rules"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func newPolicyObject(kind, namespace, name string, rulesList ...interface{}) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": policies.Group + "/" + policies.Version,
+		"kind":       kind,
+		"metadata":   map[string]interface{}{"name": name, "namespace": namespace},
+		"spec":       map[string]interface{}{"rules": rulesList},
+	}}
+
+	return u
+}
+
+func newPoliciesContext(objects ...runtime.Object) *Context {
+	scheme := runtime.NewScheme()
+	listKinds := map[schema.GroupVersionResource]string{
+		policies.TaggingPolicyResource:          policies.TaggingPolicyKind + "List",
+		policies.NamespaceTaggingPolicyResource: policies.NamespaceTaggingPolicyKind + "List",
+	}
+
+	return &Context{DynamicClient: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme, listKinds, objects...)}
+}
+
+func TestContext_upsertPolicy(t *testing.T) {
+	valid := newPolicyObject(policies.TaggingPolicyKind, "", "valid",
+		map[string]interface{}{"tag": "team", "value": "a", "action": "add"})
+	invalid := newPolicyObject(policies.NamespaceTaggingPolicyKind, "team-a", "invalid",
+		map[string]interface{}{"tag": "team", "action": "add"})
+
+	context := newPoliciesContext(valid, invalid)
+
+	context.upsertPolicy(valid)
+	context.upsertPolicy(invalid)
+
+	assert.Len(t, context.policies, 2)
+	assert.Len(t, context.policies["TaggingPolicy/valid"].rules, 1)
+	assert.Nil(t, context.policies["NamespaceTaggingPolicy/team-a/invalid"].rules)
+
+	// Status is updated in Kubernetes
+	u, err := context.DynamicClient.Resource(policies.NamespaceTaggingPolicyResource).Namespace("team-a").
+		Get(ctx.TODO(), "invalid", metav1.GetOptions{})
+	assert.Nil(t, err)
+
+	policy, err := policies.FromUnstructured(u)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"spec.rules[0].query: query and value mustn't be empty for add case"}, policy.Status.Errors)
+	assert.Equal(t, metav1.ConditionFalse, policy.Status.Conditions[0].Status)
+
+	// Delete
+	context.handlePolicyDelete(invalid)
+	assert.Len(t, context.policies, 1)
+}
+
+func TestContext_handlePolicyUpdate(t *testing.T) {
+	old := newPolicyObject(policies.TaggingPolicyKind, "", "policy",
+		map[string]interface{}{"tag": "team", "value": "a", "action": "add"})
+	old.SetResourceVersion("1")
+
+	context := newPoliciesContext(old)
+	context.upsertPolicy(old)
+
+	// Status update keeps unchanged cache
+	current := old.DeepCopy()
+	current.SetResourceVersion("2")
+	current.Object["status"] = map[string]interface{}{"matchedResources": int64(3)}
+
+	context.handlePolicyUpdate(old, current)
+	assert.Equal(t, uint64(0), context.rulesVersion)
+
+	// Spec update invalidates it
+	updated := current.DeepCopy()
+	updated.SetResourceVersion("3")
+	updated.Object["spec"] = map[string]interface{}{"rules": []interface{}{
+		map[string]interface{}{"tag": "team", "value": "b", "action": "add"},
+	}}
+
+	context.handlePolicyUpdate(current, updated)
+	assert.Equal(t, uint64(1), context.rulesVersion)
+}
+
+func TestContext_getRules(t *testing.T) {
+	context := newPoliciesContext()
+	context.upsertPolicy(newPolicyObject(policies.NamespaceTaggingPolicyKind, "team-a", "ns",
+		map[string]interface{}{"tag": "ns", "value": "a", "action": "add"}))
+	context.upsertPolicy(newPolicyObject(policies.TaggingPolicyKind, "", "cluster",
+		map[string]interface{}{"tag": "cluster", "value": "a", "action": "add"}))
+
+	snapshot := &Snapshot{Rules: []*rules.Rule{{Tag: "file", Value: "a", Action: rules.RuleActionAdd}}}
+
+	rulesList, origins := context.getRules(snapshot, "volume", "team-a")
+
+	assert.Len(t, rulesList, 3)
+	assert.Equal(t, "file", rulesList[0].Tag)
+	assert.Equal(t, "cluster", rulesList[1].Tag)
+	assert.Equal(t, "ns", rulesList[2].Tag)
+	assert.Equal(t, []string{"", "TaggingPolicy/cluster", "NamespaceTaggingPolicy/team-a/ns"}, origins)
+
+	// Namespace policy isn't applied in other namespaces
+	rulesList, origins = context.getRules(snapshot, "volume", "team-b")
+
+	assert.Len(t, rulesList, 2)
+	assert.Equal(t, []string{"", "TaggingPolicy/cluster"}, origins)
+
+	// Matches
+	trace := &rules.Trace{Rules: []*rules.RuleTrace{
+		{Reason: rules.TraceReasonTagAlreadyPresent},
+		{Reason: rules.TraceReasonConditionsNotMatched},
+	}}
+	context.recordPolicyMatches("pv/pv1", origins, trace)
+
+	assert.Empty(t, context.policies["TaggingPolicy/cluster"].matched)
+	assert.NotNil(t, context.policies["TaggingPolicy/cluster"].lastReconcileTime)
+
+	trace.Rules[1].Reason = rules.TraceReasonTagAlreadyPresent
+	context.recordPolicyMatches("pv/pv1", origins, trace)
+
+	assert.Equal(t, map[string]bool{"pv/pv1": true}, context.policies["TaggingPolicy/cluster"].matched)
+
+	context.forgetPolicyMatches("pv/pv1")
+	assert.Empty(t, context.policies["TaggingPolicy/cluster"].matched)
+}
+
+func TestContext_GetAdmissionRules(t *testing.T) {
+	context := newPoliciesContext(
+		newPolicyObject(policies.NamespaceTaggingPolicyKind, "team-a", "ns",
+			map[string]interface{}{"tag": "ns", "value": "a", "action": "add"}),
+		newPolicyObject(policies.NamespaceTaggingPolicyKind, "team-a", "invalid",
+			map[string]interface{}{"tag": "invalid", "action": "add"}),
+		newPolicyObject(policies.TaggingPolicyKind, "", "cluster",
+			map[string]interface{}{"tag": "cluster", "value": "a", "action": "add"}),
+	)
+	context.SetSnapshot(&Snapshot{
+		Configuration: &config.Configuration{TaggingPolicies: true},
+		Rules:         []*rules.Rule{{Tag: "file", Value: "a", Action: rules.RuleActionAdd}},
+	})
+
+	// Policies aren't watched, they are listed
+	rulesList, err := context.GetAdmissionRules(ctx.TODO(), "volume", "team-a")
+
+	assert.Nil(t, err)
+	assert.Len(t, rulesList, 3)
+	assert.Equal(t, "file", rulesList[0].Tag)
+	assert.Equal(t, "cluster", rulesList[1].Tag)
+	assert.Equal(t, "ns", rulesList[2].Tag)
+
+	rulesList, err = context.GetAdmissionRules(ctx.TODO(), "volume", "team-b")
+
+	assert.Nil(t, err)
+	assert.Len(t, rulesList, 2)
+}
+
+func TestNewRulesGetter(t *testing.T) {
+	configRules, err := rules.New([]*config.RuleConfig{{Action: "add", Tag: "env", Value: "prod"}})
+	assert.NoError(t, err)
+
+	snapshot := &Snapshot{Configuration: &config.Configuration{}, Rules: configRules}
+	teamA := newPolicyObject(policies.NamespaceTaggingPolicyKind, "team-a", "team",
+		map[string]interface{}{"tag": "team", "value": "a", "action": "add"})
+	dynamicClient := newPoliciesContext(teamA).DynamicClient
+
+	// Policies aren't listed when disabled
+	rulesList, err := NewRulesGetter(dynamicClient, snapshot)(ctx.Background(), "volume", "team-a")
+	assert.NoError(t, err)
+	assert.Len(t, rulesList, 1)
+
+	snapshot.Configuration.TaggingPolicies = true
+	getRules := NewRulesGetter(dynamicClient, snapshot)
+
+	rulesList, err = getRules(ctx.Background(), "volume", "team-a")
+	assert.NoError(t, err)
+	assert.Len(t, rulesList, 2)
+
+	rulesList, err = getRules(ctx.Background(), "volume", "team-b")
+	assert.NoError(t, err)
+	assert.Len(t, rulesList, 1)
+}
@@ -0,0 +1,188 @@
+package business
+
+import (
+	ctx "context"
+	"sync"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/tracing"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// queueItem Kubernetes object waiting for reconcile or on delete actions.
+// Objects are read from informers caches when processed to always use their last version.
+type queueItem struct {
+	kind string
+	key  string
+	// Deleted object carried until its on delete actions succeed because it isn't in informers caches anymore
+	deleted interface{}
+}
+
+// enqueue Add object to reconcile queue, an object already waiting is only added once.
+func (context *Context) enqueue(kind string, obj interface{}) {
+	context.addToQueue(kind, obj, nil)
+}
+
+// enqueueDeleted Add deleted object to queue to run its on delete actions with workers.
+func (context *Context) enqueueDeleted(kind string, obj interface{}) {
+	context.addToQueue(kind, obj, obj)
+}
+
+func (context *Context) addToQueue(kind string, obj, deleted interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		logrus.Errorf("Cannot get object key: %v", err)
+
+		return
+	}
+
+	context.informersMutex.RLock()
+	queue := context.queue
+	context.informersMutex.RUnlock()
+
+	if queue == nil {
+		return
+	}
+
+	queue.Add(queueItem{kind: kind, key: key, deleted: deleted})
+}
+
+// runWorkers Start reconcile workers processing the queue until stop.
+// Reconciles use the work context to finish in-flight calls after stop.
+func (context *Context) runWorkers(
+	stopCh <-chan struct{},
+	workCtx ctx.Context,
+	queue workqueue.RateLimitingInterface,
+	workers *sync.WaitGroup,
+	count int,
+) {
+	workers.Add(count)
+
+	for i := 0; i < count; i++ {
+		go func() {
+			defer workers.Done()
+
+			for context.processNextItem(stopCh, workCtx, queue) {
+			}
+		}()
+	}
+}
+
+// processNextItem Reconcile next object of queue, false is returned when queue is shut down or on stop.
+// Failed objects are queued again with a backoff, it is reset once they succeed.
+func (context *Context) processNextItem(stopCh <-chan struct{}, workCtx ctx.Context, queue workqueue.RateLimitingInterface) bool {
+	obj, shutdown := queue.Get()
+	if shutdown {
+		return false
+	}
+
+	defer queue.Done(obj)
+
+	// Queued objects aren't managed anymore after stop
+	select {
+	case <-stopCh:
+		return false
+	default:
+	}
+
+	item, _ := obj.(queueItem)
+
+	err := context.reconcileItem(workCtx, item)
+	// Check error
+	if err != nil {
+		queue.AddRateLimited(obj)
+
+		return true
+	}
+
+	queue.Forget(obj)
+
+	return true
+}
+
+// reconcileItem Reconcile queued object or run on delete actions of deleted one.
+// Objects deleted since they were queued for reconcile are ignored.
+func (context *Context) reconcileItem(workCtx ctx.Context, item queueItem) error {
+	if item.deleted != nil {
+		return context.runOnDeleteItem(workCtx, item)
+	}
+
+	switch item.kind {
+	case persistentVolumeKind:
+		pv := context.getQueuedPersistentVolume(item.key)
+		if pv == nil {
+			return nil
+		}
+
+		runCtx, span := startReconcileSpan(workCtx, persistentVolumeKind, persistentVolumeReference(pv))
+		err := context.runForPV(runCtx, pv)
+		tracing.EndSpan(span, err)
+		// Check error
+		if err != nil {
+			logrus.WithContext(runCtx).WithField("persistentVolumeName", pv.Name).Errorf("Error managing persistent volume: %v", err)
+		}
+
+		return err
+	case serviceKind:
+		svc := context.getQueuedService(item.key)
+		if svc == nil {
+			return nil
+		}
+
+		runCtx, span := startReconcileSpan(workCtx, serviceKind, serviceReference(svc))
+		err := context.runForService(runCtx, svc)
+		tracing.EndSpan(span, err)
+		// Check error
+		if err != nil {
+			logrus.WithContext(runCtx).WithFields(logrus.Fields{
+				"serviceName": svc.Name,
+				"namespace":   svc.Namespace,
+			}).Errorf("Error managing service: %v", err)
+		}
+
+		return err
+	default:
+		return nil
+	}
+}
+
+func (context *Context) getQueuedPersistentVolume(key string) *v1.PersistentVolume {
+	context.informersMutex.RLock()
+	persistentVolumeInformer := context.persistentVolumeInformer
+	context.informersMutex.RUnlock()
+
+	if persistentVolumeInformer == nil {
+		return nil
+	}
+
+	obj, exists, err := persistentVolumeInformer.GetStore().GetByKey(key)
+	if err != nil || !exists {
+		return nil
+	}
+
+	pv, _ := obj.(*v1.PersistentVolume)
+
+	return pv
+}
+
+func (context *Context) getQueuedService(key string) *v1.Service {
+	context.informersMutex.RLock()
+	serviceInformers := context.serviceInformers
+	context.informersMutex.RUnlock()
+
+	// Service is in one of the namespaced informers
+	for _, serviceInformer := range serviceInformers {
+		obj, exists, err := serviceInformer.GetStore().GetByKey(key)
+		if err != nil || !exists {
+			continue
+		}
+
+		svc, _ := obj.(*v1.Service)
+
+		return svc
+	}
+
+	return nil
+}
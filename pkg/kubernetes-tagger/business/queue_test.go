@@ -0,0 +1,158 @@
+package business
+
+import (
+	ctx "context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func TestContext_enqueue(t *testing.T) {
+	context := &Context{}
+	pv := &v1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv1"}}
+	svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}
+
+	// Nothing is done before watch
+	context.enqueue(persistentVolumeKind, pv)
+
+	context.queue = workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	context.enqueue(persistentVolumeKind, pv)
+	context.enqueue(persistentVolumeKind, pv)
+	context.enqueue(serviceKind, svc)
+
+	// Waiting objects are only added once
+	assert.Equal(t, 2, context.queue.Len())
+
+	item, _ := context.queue.Get()
+	assert.Equal(t, queueItem{kind: persistentVolumeKind, key: "pv1"}, item)
+
+	item, _ = context.queue.Get()
+	assert.Equal(t, queueItem{kind: serviceKind, key: "default/web"}, item)
+}
+
+func TestContext_getQueuedObjects(t *testing.T) {
+	context := &Context{}
+
+	assert.Nil(t, context.getQueuedPersistentVolume("pv1"))
+	assert.Nil(t, context.getQueuedService("default/web"))
+
+	persistentVolumeInformer := cache.NewSharedIndexInformer(&cache.ListWatch{}, &v1.PersistentVolume{}, 0, cache.Indexers{})
+	pv := &v1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv1"}}
+	assert.NoError(t, persistentVolumeInformer.GetStore().Add(pv))
+
+	serviceInformers := []cache.SharedIndexInformer{
+		cache.NewSharedIndexInformer(&cache.ListWatch{}, &v1.Service{}, 0, cache.Indexers{}),
+		cache.NewSharedIndexInformer(&cache.ListWatch{}, &v1.Service{}, 0, cache.Indexers{}),
+	}
+	svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}
+	assert.NoError(t, serviceInformers[1].GetStore().Add(svc))
+
+	context.persistentVolumeInformer = persistentVolumeInformer
+	context.serviceInformers = serviceInformers
+
+	assert.Equal(t, pv, context.getQueuedPersistentVolume("pv1"))
+	assert.Nil(t, context.getQueuedPersistentVolume("pv2"))
+	assert.Equal(t, svc, context.getQueuedService("default/web"))
+	assert.Nil(t, context.getQueuedService("other/web"))
+}
+
+func TestContext_processNextItem(t *testing.T) {
+	context := &Context{
+		persistentVolumeInformer: cache.NewSharedIndexInformer(&cache.ListWatch{}, &v1.PersistentVolume{}, 0, cache.Indexers{}),
+	}
+	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	stopCh := make(chan struct{})
+
+	// Deleted objects are ignored
+	queue.Add(queueItem{kind: persistentVolumeKind, key: "deleted"})
+	queue.Add(queueItem{kind: serviceKind, key: "default/deleted"})
+	assert.True(t, context.processNextItem(stopCh, ctx.Background(), queue))
+	assert.True(t, context.processNextItem(stopCh, ctx.Background(), queue))
+	assert.Equal(t, 0, queue.Len())
+
+	// Backoff of retried objects is reset on success
+	retried := queueItem{kind: persistentVolumeKind, key: "deleted"}
+	queue.AddRateLimited(retried)
+	assert.Equal(t, 1, queue.NumRequeues(retried))
+	assert.True(t, context.processNextItem(stopCh, ctx.Background(), queue))
+	assert.Equal(t, 0, queue.NumRequeues(retried))
+
+	// Queued objects are ignored after stop
+	queue.Add(queueItem{kind: persistentVolumeKind, key: "deleted"})
+	close(stopCh)
+	assert.False(t, context.processNextItem(stopCh, ctx.Background(), queue))
+
+	queue.ShutDown()
+	assert.False(t, context.processNextItem(stopCh, ctx.Background(), queue))
+}
+
+func TestContext_StopWatch(t *testing.T) {
+	context := &Context{}
+
+	// Nothing to stop
+	assert.True(t, context.StopWatch(time.Second))
+
+	watchCtx, stopWatch := ctx.WithCancel(ctx.Background())
+	workCtx, stopWork := ctx.WithCancel(ctx.Background())
+	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	context.stopWatch = stopWatch
+	context.workCtx = workCtx
+	context.stopWork = stopWork
+	context.queue = queue
+	context.persistentVolumeInformer = cache.NewSharedIndexInformer(&cache.ListWatch{}, &v1.PersistentVolume{}, 0, cache.Indexers{})
+	context.unchanged = map[string]*unchangedEntry{"persistentvolume/pv1": {hash: "hash"}}
+	context.workers = &sync.WaitGroup{}
+	context.runWorkers(watchCtx.Done(), workCtx, queue, context.workers, 2)
+
+	assert.True(t, context.StopWatch(time.Second))
+	assert.True(t, queue.ShuttingDown())
+	assert.Nil(t, context.persistentVolumeInformer)
+	assert.Nil(t, context.unchanged)
+
+	assert.Error(t, watchCtx.Err())
+	// Work is cancelled once workers are drained
+	assert.Error(t, workCtx.Err())
+	assert.Equal(t, ctx.Background(), context.getWorkContext())
+
+	// Objects aren't queued after stop
+	context.enqueue(persistentVolumeKind, &v1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv1"}})
+	assert.Equal(t, 0, queue.Len())
+}
+
+func TestContext_StopWatchTimeout(t *testing.T) {
+	context := &Context{}
+
+	// Worker of first watch never ends
+	stuckWorkers := &sync.WaitGroup{}
+	stuckWorkers.Add(1)
+
+	_, stopWatch := ctx.WithCancel(ctx.Background())
+	_, stopWork := ctx.WithCancel(ctx.Background())
+	context.stopWatch = stopWatch
+	context.stopWork = stopWork
+	context.workers = stuckWorkers
+	context.queue = workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+
+	assert.False(t, context.StopWatch(10*time.Millisecond))
+	assert.Nil(t, context.workers)
+
+	// Next watch workers don't depend on stuck ones
+	watchCtx, stopWatch := ctx.WithCancel(ctx.Background())
+	workCtx, stopWork := ctx.WithCancel(ctx.Background())
+	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	context.stopWatch = stopWatch
+	context.stopWork = stopWork
+	context.workers = &sync.WaitGroup{}
+	context.queue = queue
+	context.runWorkers(watchCtx.Done(), workCtx, queue, context.workers, 2)
+
+	assert.True(t, context.StopWatch(time.Second))
+
+	stuckWorkers.Done()
+}
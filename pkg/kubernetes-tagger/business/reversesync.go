@@ -0,0 +1,178 @@
+package business
+
+import (
+	ctx "context"
+	"encoding/json"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/rules"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/tags"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// getUnmanagedTags Get tags on resource not managed by rules.
+// Only those tags can be copied to Kubernetes objects, this avoids loops with rules.
+func getUnmanagedTags(resourceTags []*tags.Tag, rulesList []*rules.Rule) []*tags.Tag {
+	managedKeys := make(map[string]bool)
+	for _, rule := range rulesList {
+		managedKeys[rule.Tag] = true
+	}
+
+	result := make([]*tags.Tag, 0)
+
+	for _, tag := range resourceTags {
+		if !managedKeys[tag.Key] {
+			result = append(result, tag)
+		}
+	}
+
+	return result
+}
+
+// getReverseSyncAnnotations Get annotations to patch on a Kubernetes object target from resource tags.
+// Annotations already up to date aren't returned.
+func getReverseSyncAnnotations(
+	reverseSyncConfig *config.ReverseSyncConfig,
+	target string,
+	annotations map[string]string,
+	resourceTags []*tags.Tag,
+) map[string]string {
+	result := make(map[string]string)
+
+	// Index tags values by key
+	tagValues := make(map[string]string)
+	for _, tag := range resourceTags {
+		tagValues[tag.Key] = tag.Value
+	}
+
+	for _, rule := range reverseSyncConfig.Rules {
+		if rule.Target != target {
+			continue
+		}
+
+		value, exists := tagValues[rule.Tag]
+		if !exists {
+			continue
+		}
+
+		key := reverseSyncConfig.GetAnnotationKey(rule)
+
+		actualValue, annotationExists := annotations[key]
+		if annotationExists && actualValue == value {
+			continue
+		}
+
+		if annotationExists && rule.Conflict == config.ReverseSyncConflictSkip {
+			logrus.WithField("annotation", key).Debug("Annotation already exists with a different value -> skipping")
+
+			continue
+		}
+
+		result[key] = value
+	}
+
+	return result
+}
+
+// getAnnotationsPatch Create strategic merge patch adding annotations.
+func getAnnotationsPatch(annotations map[string]string) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": annotations},
+	})
+}
+
+// reverseSyncPersistentVolume Copy resource tags to persistent volume and claim annotations.
+func reverseSyncPersistentVolume(
+	runCtx ctx.Context,
+	k8sClient kubernetes.Interface,
+	pv *v1.PersistentVolume,
+	cfg *config.Configuration,
+	resourceTags []*tags.Tag,
+) error {
+	if cfg.ReverseSync == nil || len(resourceTags) == 0 {
+		return nil
+	}
+
+	annotations := getReverseSyncAnnotations(cfg.ReverseSync, config.ReverseSyncTargetPersistentVolume, pv.Annotations, resourceTags)
+	if len(annotations) != 0 {
+		patch, err := getAnnotationsPatch(annotations)
+		if err != nil {
+			return err
+		}
+
+		logrus.WithField("persistentVolumeName", pv.Name).Infof("Copy tags to persistent volume annotations: %v", annotations)
+
+		_, err = k8sClient.CoreV1().PersistentVolumes().Patch(runCtx, pv.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Check if persistent volume is linked to a claim
+	claimRef := pv.Spec.ClaimRef
+	if claimRef == nil {
+		return nil
+	}
+
+	pvc, err := k8sClient.CoreV1().PersistentVolumeClaims(claimRef.Namespace).Get(runCtx, claimRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	annotations = getReverseSyncAnnotations(cfg.ReverseSync, config.ReverseSyncTargetPersistentVolumeClaim, pvc.Annotations, resourceTags)
+	if len(annotations) == 0 {
+		return nil
+	}
+
+	patch, err := getAnnotationsPatch(annotations)
+	if err != nil {
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"persistentVolumeClaimName": pvc.Name,
+		"namespace":                 pvc.Namespace,
+	}).Infof("Copy tags to persistent volume claim annotations: %v", annotations)
+
+	_, err = k8sClient.CoreV1().PersistentVolumeClaims(pvc.Namespace).
+		Patch(runCtx, pvc.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+
+	return err
+}
+
+// reverseSyncService Copy resource tags to service annotations.
+func reverseSyncService(
+	runCtx ctx.Context,
+	k8sClient kubernetes.Interface,
+	svc *v1.Service,
+	cfg *config.Configuration,
+	resourceTags []*tags.Tag,
+) error {
+	if cfg.ReverseSync == nil || len(resourceTags) == 0 {
+		return nil
+	}
+
+	annotations := getReverseSyncAnnotations(cfg.ReverseSync, config.ReverseSyncTargetService, svc.Annotations, resourceTags)
+	if len(annotations) == 0 {
+		return nil
+	}
+
+	patch, err := getAnnotationsPatch(annotations)
+	if err != nil {
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"serviceName": svc.Name,
+		"namespace":   svc.Namespace,
+	}).Infof("Copy tags to service annotations: %v", annotations)
+
+	_, err = k8sClient.CoreV1().Services(svc.Namespace).
+		Patch(runCtx, svc.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+
+	return err
+}
@@ -0,0 +1,108 @@
+package business
+
+import (
+	ctx "context"
+	"testing"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/rules"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/tags"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclient "k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_getUnmanagedTags(t *testing.T) {
+	resourceTags := []*tags.Tag{{Key: "managed", Value: "value"}, {Key: "CostCenter", Value: "1234"}}
+	rulesList := []*rules.Rule{{Tag: "managed", Value: "value", Action: rules.RuleActionAdd}}
+
+	assert.Equal(t, []*tags.Tag{{Key: "CostCenter", Value: "1234"}}, getUnmanagedTags(resourceTags, rulesList))
+}
+
+func Test_getReverseSyncAnnotations(t *testing.T) {
+	reverseSyncConfig := &config.ReverseSyncConfig{
+		AnnotationPrefix: "example.com/",
+		Rules: []*config.ReverseSyncRuleConfig{
+			{Tag: "CostCenter", Annotation: "cost-center", Target: config.ReverseSyncTargetService},
+			{Tag: "Owner", Target: config.ReverseSyncTargetService, Conflict: config.ReverseSyncConflictSkip},
+			{Tag: "Team", Target: config.ReverseSyncTargetService},
+			{Tag: "Missing", Target: config.ReverseSyncTargetService},
+			{Tag: "Other", Target: config.ReverseSyncTargetPersistentVolume},
+		},
+	}
+	resourceTags := []*tags.Tag{
+		{Key: "CostCenter", Value: "1234"},
+		{Key: "Owner", Value: "finops"},
+		{Key: "Team", Value: "a"},
+		{Key: "Other", Value: "value"},
+	}
+	annotations := map[string]string{
+		"example.com/Owner": "team-a",
+		"example.com/Team":  "a",
+	}
+
+	assert.Equal(t, map[string]string{
+		"example.com/cost-center": "1234",
+	}, getReverseSyncAnnotations(reverseSyncConfig, config.ReverseSyncTargetService, annotations, resourceTags))
+
+	// Overwrite is the default conflict mode
+	annotations["example.com/cost-center"] = "old"
+	assert.Equal(t, map[string]string{
+		"example.com/cost-center": "1234",
+	}, getReverseSyncAnnotations(reverseSyncConfig, config.ReverseSyncTargetService, annotations, resourceTags))
+}
+
+func Test_reverseSyncPersistentVolume(t *testing.T) {
+	pv := &v1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv1"},
+		Spec: v1.PersistentVolumeSpec{
+			ClaimRef: &v1.ObjectReference{Namespace: "default", Name: "pvc1"},
+		},
+	}
+	pvc := &v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pvc1"}}
+	k8sClient := testclient.NewSimpleClientset(pv, pvc)
+	cfg := &config.Configuration{ReverseSync: &config.ReverseSyncConfig{Rules: []*config.ReverseSyncRuleConfig{
+		{Tag: "CostCenter", Target: config.ReverseSyncTargetPersistentVolume},
+		{Tag: "CostCenter", Target: config.ReverseSyncTargetPersistentVolumeClaim},
+	}}}
+
+	err := reverseSyncPersistentVolume(ctx.Background(), k8sClient, pv, cfg, []*tags.Tag{{Key: "CostCenter", Value: "1234"}})
+	assert.Nil(t, err)
+
+	pv, _ = k8sClient.CoreV1().PersistentVolumes().Get(ctx.TODO(), "pv1", metav1.GetOptions{})
+	assert.Equal(t, "1234", pv.Annotations[config.DefaultReverseSyncAnnotationPrefix+"CostCenter"])
+
+	pvc, _ = k8sClient.CoreV1().PersistentVolumeClaims("default").Get(ctx.TODO(), "pvc1", metav1.GetOptions{})
+	assert.Equal(t, "1234", pvc.Annotations[config.DefaultReverseSyncAnnotationPrefix+"CostCenter"])
+
+	// Nothing is patched when annotations are up to date
+	k8sClient.ClearActions()
+
+	err = reverseSyncPersistentVolume(ctx.Background(), k8sClient, pv, cfg, []*tags.Tag{{Key: "CostCenter", Value: "1234"}})
+	assert.Nil(t, err)
+
+	for _, action := range k8sClient.Actions() {
+		assert.NotEqual(t, "patch", action.GetVerb())
+	}
+}
+
+func Test_reverseSyncService(t *testing.T) {
+	svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "svc1"}}
+	k8sClient := testclient.NewSimpleClientset(svc)
+
+	// No reverse sync configuration
+	err := reverseSyncService(ctx.Background(), k8sClient, svc, &config.Configuration{}, []*tags.Tag{{Key: "CostCenter", Value: "1234"}})
+	assert.Nil(t, err)
+	assert.Empty(t, k8sClient.Actions())
+
+	cfg := &config.Configuration{ReverseSync: &config.ReverseSyncConfig{Rules: []*config.ReverseSyncRuleConfig{
+		{Tag: "CostCenter", Annotation: "cost-center", Target: config.ReverseSyncTargetService},
+	}}}
+
+	err = reverseSyncService(ctx.Background(), k8sClient, svc, cfg, []*tags.Tag{{Key: "CostCenter", Value: "1234"}})
+	assert.Nil(t, err)
+
+	svc, _ = k8sClient.CoreV1().Services("default").Get(ctx.TODO(), "svc1", metav1.GetOptions{})
+	assert.Equal(t, "1234", svc.Annotations[config.DefaultReverseSyncAnnotationPrefix+"cost-center"])
+}
@@ -0,0 +1,73 @@
+package business
+
+import (
+	"reflect"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/rules"
+	"github.com/sirupsen/logrus"
+)
+
+// Snapshot Immutable configuration snapshot with rules generated from it.
+type Snapshot struct {
+	Configuration *config.Configuration
+	Rules         []*rules.Rule
+}
+
+// NewSnapshot Create a validated snapshot from configuration.
+func NewSnapshot(cfg *config.Configuration) (*Snapshot, error) {
+	// Check if the configuration is valid
+	err := cfg.IsValid()
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate rules from rules declared in configuration
+	rulesList, err := rules.New(cfg.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Snapshot{Configuration: cfg, Rules: rulesList}, nil
+}
+
+// GetSnapshot Get current configuration snapshot.
+func (context *Context) GetSnapshot() *Snapshot {
+	snapshot, _ := context.snapshot.Load().(*Snapshot)
+
+	return snapshot
+}
+
+// SetSnapshot Atomically replace the current configuration snapshot.
+func (context *Context) SetSnapshot(snapshot *Snapshot) {
+	previous := context.GetSnapshot()
+	context.snapshot.Store(snapshot)
+	// Rules and cluster values may have changed
+	context.invalidateUnchanged()
+	// Provider region or credentials may have changed
+	context.invalidateClusterFacts()
+
+	// Watch scope is only applied when informers are created
+	if previous != nil && !isSameWatchScope(previous.Configuration, snapshot.Configuration) {
+		logrus.Warn("Watch scope configuration changed, a restart is needed to apply it")
+	}
+
+	// Informers and workers are only configured when they are created
+	if previous != nil && !isSameInformersConfiguration(previous.Configuration, snapshot.Configuration) {
+		logrus.Warn("Reconcile workers or resync configuration changed, a restart is needed to apply it")
+	}
+}
+
+func isSameWatchScope(cfg1, cfg2 *config.Configuration) bool {
+	return reflect.DeepEqual(cfg1.WatchNamespaces, cfg2.WatchNamespaces) &&
+		reflect.DeepEqual(cfg1.ExcludeNamespaces, cfg2.ExcludeNamespaces) &&
+		reflect.DeepEqual(cfg1.LabelSelectors, cfg2.LabelSelectors) &&
+		cfg1.TaggingPolicies == cfg2.TaggingPolicies
+}
+
+func isSameInformersConfiguration(cfg1, cfg2 *config.Configuration) bool {
+	resyncPeriods1, _ := cfg1.Reconcile.GetResyncPeriods()
+	resyncPeriods2, _ := cfg2.Reconcile.GetResyncPeriods()
+
+	return cfg1.Reconcile.GetWorkers() == cfg2.Reconcile.GetWorkers() && reflect.DeepEqual(resyncPeriods1, resyncPeriods2)
+}
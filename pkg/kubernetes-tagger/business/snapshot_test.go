@@ -0,0 +1,53 @@
+package business
+
+import (
+	"testing"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/rules"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSnapshot(t *testing.T) {
+	validConfig := &config.Configuration{
+		Provider: config.AWSProviderName,
+		AWS:      &config.AWSConfig{Region: "eu-west-1"},
+		Rules:    []*config.RuleConfig{{Tag: "tag", Value: "value", Action: "add"}},
+	}
+
+	snapshot, err := NewSnapshot(validConfig)
+
+	assert.Nil(t, err)
+	assert.Equal(t, validConfig, snapshot.Configuration)
+	assert.Len(t, snapshot.Rules, 1)
+
+	// Invalid configuration
+	snapshot, err = NewSnapshot(&config.Configuration{})
+
+	assert.Nil(t, snapshot)
+	assert.Equal(t, config.ErrNoProviderSelected, err)
+
+	// Invalid rules
+	snapshot, err = NewSnapshot(&config.Configuration{
+		Provider: config.AWSProviderName,
+		AWS:      &config.AWSConfig{Region: "eu-west-1"},
+		Rules:    []*config.RuleConfig{{Tag: "tag", Action: "add"}},
+	})
+
+	assert.Nil(t, snapshot)
+	assert.ErrorIs(t, err, rules.ErrRuleQueryAndValueEmptyForAddCase)
+}
+
+func TestContext_SetSnapshot(t *testing.T) {
+	context := &Context{}
+
+	assert.Nil(t, context.GetSnapshot())
+
+	snapshot := &Snapshot{Configuration: &config.Configuration{}}
+	context.SetSnapshot(snapshot)
+
+	assert.Equal(t, snapshot, context.GetSnapshot())
+
+	// Reconcile without watched objects does nothing
+	context.Reconcile()
+}
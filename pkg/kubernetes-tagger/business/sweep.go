@@ -0,0 +1,468 @@
+package business
+
+import (
+	ctx "context"
+	"errors"
+	"time"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/audit"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/metrics"
+	providerclient "github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/providerClient"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/tags"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	networkingv1client "k8s.io/client-go/kubernetes/typed/networking/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// errSweepStopped Sweep stopped before informers caches are synced error.
+var errSweepStopped = errors.New("sweep stopped before informers caches are synced")
+
+// sweepDisabledCheckInterval Interval between two checks of sweep activation by configuration reloads.
+const sweepDisabledCheckInterval = time.Minute
+
+// SweepReport Result of a cloud sweep.
+type SweepReport struct {
+	ClusterName string    `json:"clusterName"`
+	StartTime   time.Time `json:"startTime"`
+	EndTime     time.Time `json:"endTime"`
+	// Number of cloud resources having the cluster tag
+	Resources int `json:"resources"`
+	// Cloud resources without Kubernetes object
+	Orphans []*providerclient.CloudResource `json:"orphans"`
+	// Number of orphans tagged during this sweep
+	TaggedOrphans int `json:"taggedOrphans"`
+	// Number of cloud resources without Kubernetes object created during grace period
+	RecentResources int `json:"recentResources"`
+	// Number of resources having a Kubernetes object again whose orphan tags were removed during this sweep
+	AdoptedResources int `json:"adoptedResources"`
+	// Cloud resources that couldn't be tagged or untagged, other resources are still swept
+	ResourceErrors []*SweepResourceError `json:"resourceErrors,omitempty"`
+	Error          string                `json:"error,omitempty"`
+}
+
+// SweepResourceError Error on a cloud resource during a sweep.
+type SweepResourceError struct {
+	Type string `json:"type"`
+	// Empty when cloud resource of a Kubernetes object can't be found
+	ID string `json:"id,omitempty"`
+	// Kubernetes object reference like pv/<name>, empty for cloud resources errors
+	ObjectReference string `json:"objectReference,omitempty"`
+	Error           string `json:"error"`
+}
+
+// GetSweepReport Get last cloud sweep report, nil when no sweep was done.
+func (context *Context) GetSweepReport() *SweepReport {
+	context.sweepMutex.RLock()
+	defer context.sweepMutex.RUnlock()
+
+	return context.sweepReport
+}
+
+// runSweeps Run cloud sweeps periodically when enabled in configuration until stop.
+// Configuration is read on each iteration to follow reloads.
+func (context *Context) runSweeps(stopCh <-chan struct{}, workCtx ctx.Context) {
+	for {
+		cfg := context.GetSnapshot().Configuration
+		interval := sweepDisabledCheckInterval
+
+		if cfg.Sweep.IsSweepEnabled() {
+			sweepCtx, span := startSweepSpan(workCtx, cfg.Sweep.ClusterName)
+			report := context.sweep(stopCh, sweepCtx, cfg)
+			endSweepSpan(span, report)
+
+			context.sweepMutex.Lock()
+			context.sweepReport = report
+			context.sweepMutex.Unlock()
+
+			// Interval is checked by configuration validation
+			interval, _ = cfg.Sweep.GetInterval()
+		}
+
+		select {
+		case <-stopCh:
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// sweep Find cloud resources having the cluster tag without Kubernetes object and tag them.
+func (context *Context) sweep(stopCh <-chan struct{}, workCtx ctx.Context, cfg *config.Configuration) *SweepReport {
+	log := logrus.WithContext(workCtx).WithField("clusterName", cfg.Sweep.ClusterName)
+	log.Info("Begin cloud sweep")
+
+	report := &SweepReport{
+		ClusterName: cfg.Sweep.ClusterName,
+		StartTime:   time.Now(),
+		Orphans:     make([]*providerclient.CloudResource, 0),
+	}
+
+	defer func() { report.EndTime = time.Now() }()
+
+	pvs, svcs, err := context.listSweepObjects(stopCh, workCtx, cfg)
+	if err != nil {
+		log.Errorf("Cannot list Kubernetes objects for cloud sweep: %v", err)
+		report.Error = err.Error()
+
+		return report
+	}
+
+	// Ingresses aren't watched, they are always listed from Kubernetes API
+	ingresses, err := listSweepIngresses(workCtx, context.KubernetesClient.NetworkingV1())
+	if err != nil {
+		log.Errorf("Cannot list Kubernetes objects for cloud sweep: %v", err)
+		report.Error = err.Error()
+
+		return report
+	}
+
+	prcl, err := newProviderClient(cfg)
+	if err != nil {
+		log.Errorf("Cannot create provider client for cloud sweep: %v", err)
+		report.Error = err.Error()
+
+		return report
+	}
+
+	err = context.sweepCloudResources(workCtx, prcl, cfg, &sweepObjects{pvs: pvs, svcs: svcs, ingresses: ingresses}, report)
+	if err != nil {
+		log.Errorf("Cloud sweep failed: %v", err)
+		report.Error = err.Error()
+
+		return report
+	}
+
+	// Count orphans by type
+	orphansByType := map[string]float64{
+		providerclient.CloudResourceTypeVolume:       0,
+		providerclient.CloudResourceTypeLoadBalancer: 0,
+	}
+
+	for _, orphan := range report.Orphans {
+		orphansByType[orphan.Type]++
+
+		log.WithFields(logrus.Fields{
+			"type": orphan.Type,
+			"id":   orphan.ID,
+		}).Warn("Cloud resource has the cluster tag without Kubernetes object")
+	}
+
+	for resourceType, count := range orphansByType {
+		metrics.OrphanResources.WithLabelValues(resourceType, cfg.Provider).Set(count)
+	}
+
+	for _, resourceError := range report.ResourceErrors {
+		log.WithFields(logrus.Fields{
+			"type":            resourceError.Type,
+			"id":              resourceError.ID,
+			"objectReference": resourceError.ObjectReference,
+		}).Errorf("Cannot sweep cloud resource: %s", resourceError.Error)
+	}
+
+	log.Infof("Cloud sweep done, %d orphan resources found on %d resources", len(report.Orphans), report.Resources)
+
+	return report
+}
+
+// listSweepObjects List all persistent volumes and services of the cluster.
+// Informers caches are used when they contain all objects, otherwise objects are listed from Kubernetes API.
+func (context *Context) listSweepObjects(stopCh <-chan struct{}, workCtx ctx.Context, cfg *config.Configuration) ([]*v1.PersistentVolume, []*v1.Service, error) {
+	context.informersMutex.RLock()
+	persistentVolumeInformer := context.persistentVolumeInformer
+	serviceInformers := context.serviceInformers
+	context.informersMutex.RUnlock()
+
+	if persistentVolumeInformer == nil || !isWatchScopeUnfiltered(cfg) {
+		return listSweepObjectsFromAPI(workCtx, context.KubernetesClient.CoreV1())
+	}
+
+	// Orphans mustn't be found on partial caches
+	hasSynced := []cache.InformerSynced{persistentVolumeInformer.HasSynced}
+	for _, serviceInformer := range serviceInformers {
+		hasSynced = append(hasSynced, serviceInformer.HasSynced)
+	}
+
+	if !cache.WaitForCacheSync(stopCh, hasSynced...) {
+		return nil, nil, errSweepStopped
+	}
+
+	pvs := make([]*v1.PersistentVolume, 0)
+
+	for _, obj := range persistentVolumeInformer.GetStore().List() {
+		pv, _ := obj.(*v1.PersistentVolume)
+		pvs = append(pvs, pv)
+	}
+
+	svcs := make([]*v1.Service, 0)
+
+	for _, serviceInformer := range serviceInformers {
+		for _, obj := range serviceInformer.GetStore().List() {
+			svc, _ := obj.(*v1.Service)
+			svcs = append(svcs, svc)
+		}
+	}
+
+	return pvs, svcs, nil
+}
+
+// coreV1Lister Part of Kubernetes core client used to list objects.
+type coreV1Lister interface {
+	PersistentVolumes() corev1client.PersistentVolumeInterface
+	Services(namespace string) corev1client.ServiceInterface
+}
+
+func listSweepObjectsFromAPI(workCtx ctx.Context, client coreV1Lister) ([]*v1.PersistentVolume, []*v1.Service, error) {
+	pvList, err := client.PersistentVolumes().List(workCtx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	svcList, err := client.Services(metav1.NamespaceAll).List(workCtx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pvs := make([]*v1.PersistentVolume, 0, len(pvList.Items))
+	for i := range pvList.Items {
+		pvs = append(pvs, &pvList.Items[i])
+	}
+
+	svcs := make([]*v1.Service, 0, len(svcList.Items))
+	for i := range svcList.Items {
+		svcs = append(svcs, &svcList.Items[i])
+	}
+
+	return pvs, svcs, nil
+}
+
+// listSweepIngresses List all ingresses of the cluster, nothing is returned when ingress API isn't served.
+func listSweepIngresses(workCtx ctx.Context, client networkingv1client.IngressesGetter) ([]*networkingv1.Ingress, error) {
+	ingList, err := client.Ingresses(metav1.NamespaceAll).List(workCtx, metav1.ListOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	ingresses := make([]*networkingv1.Ingress, 0, len(ingList.Items))
+	for i := range ingList.Items {
+		ingresses = append(ingresses, &ingList.Items[i])
+	}
+
+	return ingresses, nil
+}
+
+// isWatchScopeUnfiltered Checks if informers watch all persistent volumes and services.
+func isWatchScopeUnfiltered(cfg *config.Configuration) bool {
+	if len(cfg.WatchNamespaces) != 0 || len(cfg.ExcludeNamespaces) != 0 {
+		return false
+	}
+
+	return cfg.LabelSelectors == nil || (cfg.LabelSelectors.PersistentVolumes == "" && cfg.LabelSelectors.Services == "")
+}
+
+// sweepObjects Kubernetes objects referencing cloud resources.
+type sweepObjects struct {
+	pvs       []*v1.PersistentVolume
+	svcs      []*v1.Service
+	ingresses []*networkingv1.Ingress
+}
+
+// sweepCloudResources Find cloud resources without Kubernetes object and tag them in report.
+// Orphan tags are removed from resources having a Kubernetes object again.
+// Errors on a resource are saved in report and other resources are still swept.
+func (context *Context) sweepCloudResources(
+	workCtx ctx.Context,
+	prcl providerclient.ProviderClient,
+	cfg *config.Configuration,
+	objects *sweepObjects,
+	report *SweepReport,
+) error {
+	knownResources := getKnownCloudResources(prcl, objects, report)
+
+	cloudResources, err := prcl.ListClusterResources(workCtx, cfg.Sweep.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	report.Resources = len(cloudResources)
+
+	// Grace period is checked by configuration validation
+	gracePeriod, _ := cfg.Sweep.GetGracePeriod()
+
+	for _, cloudResource := range cloudResources {
+		if knownResources[cloudResource.Type+"/"+cloudResource.ID] {
+			delta := &tags.TagDelta{DeleteList: getSweepRemovedTags(cfg.Sweep, cloudResource.Tags)}
+			if context.applySweepDelta(workCtx, prcl, cfg, cloudResource, delta, report) {
+				report.AdoptedResources++
+			}
+
+			continue
+		}
+
+		// Kubernetes object may not reference a just created resource yet
+		if report.StartTime.Sub(cloudResource.CreationTime) < gracePeriod {
+			report.RecentResources++
+
+			continue
+		}
+
+		report.Orphans = append(report.Orphans, cloudResource)
+
+		delta := &tags.TagDelta{AddList: getSweepTags(cfg.Sweep, cloudResource.Tags, report.StartTime)}
+		if context.applySweepDelta(workCtx, prcl, cfg, cloudResource, delta, report) {
+			report.TaggedOrphans++
+		}
+	}
+
+	return nil
+}
+
+// getKnownCloudResources Get cloud resources referenced by Kubernetes objects by type and ID.
+// Objects without a valid cloud resource ID are saved in report errors and other objects are still used.
+func getKnownCloudResources(prcl providerclient.ProviderClient, objects *sweepObjects, report *SweepReport) map[string]bool {
+	knownResources := make(map[string]bool)
+
+	for _, pv := range objects.pvs {
+		id, err := prcl.GetPersistentVolumeResourceID(pv)
+		if err != nil {
+			report.ResourceErrors = append(report.ResourceErrors, &SweepResourceError{
+				Type:            providerclient.CloudResourceTypeVolume,
+				ObjectReference: persistentVolumeReference(pv),
+				Error:           err.Error(),
+			})
+
+			continue
+		}
+
+		if id != "" {
+			knownResources[providerclient.CloudResourceTypeVolume+"/"+id] = true
+		}
+	}
+
+	for _, svc := range objects.svcs {
+		if svc.Spec.Type != v1.ServiceTypeLoadBalancer {
+			continue
+		}
+
+		id := prcl.GetServiceResourceID(svc)
+		if id != "" {
+			knownResources[providerclient.CloudResourceTypeLoadBalancer+"/"+id] = true
+		}
+	}
+
+	// Load balancers of ingresses, several ingresses can share one
+	for _, ing := range objects.ingresses {
+		id := prcl.GetIngressResourceID(ing)
+		if id != "" {
+			knownResources[providerclient.CloudResourceTypeLoadBalancer+"/"+id] = true
+		}
+	}
+
+	return knownResources
+}
+
+// applySweepDelta Apply sweep tags delta on a cloud resource and return if tags were changed.
+// Error is saved in report.
+func (context *Context) applySweepDelta(
+	workCtx ctx.Context,
+	prcl providerclient.ProviderClient,
+	cfg *config.Configuration,
+	cloudResource *providerclient.CloudResource,
+	delta *tags.TagDelta,
+	report *SweepReport,
+) bool {
+	if len(delta.AddList) == 0 && len(delta.DeleteList) == 0 {
+		return false
+	}
+
+	auditRecord := &audit.Record{
+		Timestamp:    time.Now(),
+		Source:       audit.SourceSweep,
+		ResourceType: cloudResource.Type,
+		Platform:     cfg.Provider,
+		ResourceID:   cloudResource.ID,
+		Changes:      getAuditChanges(delta.Changes(cloudResource.Tags), nil, nil),
+	}
+
+	var err error
+	if len(delta.AddList) != 0 {
+		err = prcl.AddTagsToCloudResource(workCtx, cloudResource, delta.AddList)
+	} else {
+		err = prcl.DeleteTagsFromCloudResource(workCtx, cloudResource, delta.DeleteList)
+	}
+
+	completeAuditRecord(auditRecord, err)
+	context.writeAudit(cfg, auditRecord)
+
+	if err != nil {
+		report.ResourceErrors = append(report.ResourceErrors, &SweepResourceError{
+			Type:  cloudResource.Type,
+			ID:    cloudResource.ID,
+			Error: err.Error(),
+		})
+
+		return false
+	}
+
+	for _, tag := range delta.AddList {
+		metrics.TagChanges.WithLabelValues(string(tags.ChangeActionAdd), tag.Key).Inc()
+	}
+
+	for _, tag := range delta.DeleteList {
+		metrics.TagChanges.WithLabelValues(string(tags.ChangeActionDelete), tag.Key).Inc()
+	}
+
+	return true
+}
+
+// getSweepTags Get tags to add on an orphan cloud resource.
+// Timestamp tag is only added once to keep the first detection time.
+func getSweepTags(sweepCfg *config.SweepConfig, actualTags []*tags.Tag, detectionTime time.Time) []*tags.Tag {
+	actualValues := make(map[string]string)
+	for _, tag := range actualTags {
+		actualValues[tag.Key] = tag.Value
+	}
+
+	result := make([]*tags.Tag, 0)
+
+	for _, tag := range sweepCfg.AddTags {
+		if value, ok := actualValues[tag.Key]; !ok || value != tag.Value {
+			result = append(result, &tags.Tag{Key: tag.Key, Value: tag.Value})
+		}
+	}
+
+	if _, ok := actualValues[sweepCfg.TimestampTag]; sweepCfg.TimestampTag != "" && !ok {
+		result = append(result, &tags.Tag{Key: sweepCfg.TimestampTag, Value: detectionTime.UTC().Format(time.RFC3339)})
+	}
+
+	return result
+}
+
+// getSweepRemovedTags Get orphan tags to remove from a cloud resource having a Kubernetes object again.
+// Added tags are only removed when they still have the sweep value.
+func getSweepRemovedTags(sweepCfg *config.SweepConfig, actualTags []*tags.Tag) []*tags.Tag {
+	sweepValues := make(map[string]string)
+	for _, tag := range sweepCfg.AddTags {
+		sweepValues[tag.Key] = tag.Value
+	}
+
+	result := make([]*tags.Tag, 0)
+
+	for _, tag := range actualTags {
+		value, ok := sweepValues[tag.Key]
+		if (ok && value == tag.Value) || (sweepCfg.TimestampTag != "" && tag.Key == sweepCfg.TimestampTag) {
+			result = append(result, tag)
+		}
+	}
+
+	return result
+}
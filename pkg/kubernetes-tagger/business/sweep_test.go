@@ -0,0 +1,241 @@
+package business
+
+import (
+	ctx "context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	providerclient "github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/providerClient"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/tags"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type fakeSweepProviderClient struct {
+	providerclient.ProviderClient
+	resources []*providerclient.CloudResource
+	added     map[string][]*tags.Tag
+	deleted   map[string][]*tags.Tag
+	addErr    error
+}
+
+func (fspc *fakeSweepProviderClient) ListClusterResources(_ ctx.Context, clusterName string) ([]*providerclient.CloudResource, error) {
+	return fspc.resources, nil
+}
+
+func (fspc *fakeSweepProviderClient) AddTagsToCloudResource(_ ctx.Context, resource *providerclient.CloudResource, tagsList []*tags.Tag) error {
+	if fspc.addErr != nil {
+		return fspc.addErr
+	}
+
+	fspc.added[resource.ID] = tagsList
+
+	return nil
+}
+
+func (fspc *fakeSweepProviderClient) DeleteTagsFromCloudResource(_ ctx.Context, resource *providerclient.CloudResource, tagsList []*tags.Tag) error {
+	fspc.deleted[resource.ID] = tagsList
+
+	return nil
+}
+
+func (fspc *fakeSweepProviderClient) GetIngressResourceID(ing *networkingv1.Ingress) string {
+	if len(ing.Status.LoadBalancer.Ingress) == 0 {
+		return ""
+	}
+
+	return ing.Status.LoadBalancer.Ingress[0].Hostname
+}
+
+func (fspc *fakeSweepProviderClient) GetPersistentVolumeResourceID(pv *v1.PersistentVolume) (string, error) {
+	if pv.Spec.CSI == nil {
+		return "", nil
+	}
+
+	if pv.Spec.CSI.VolumeHandle == "" {
+		return "", errors.New("invalid volume handle")
+	}
+
+	return pv.Spec.CSI.VolumeHandle, nil
+}
+
+func (fspc *fakeSweepProviderClient) GetServiceResourceID(svc *v1.Service) string {
+	return svc.Name
+}
+
+func Test_sweepCloudResources(t *testing.T) {
+	startTime := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	prcl := &fakeSweepProviderClient{
+		resources: []*providerclient.CloudResource{
+			{Type: providerclient.CloudResourceTypeVolume, ID: "vol-known"},
+			{Type: providerclient.CloudResourceTypeVolume, ID: "vol-orphan"},
+			// Persistent volume isn't created yet
+			{Type: providerclient.CloudResourceTypeVolume, ID: "vol-new", CreationTime: startTime.Add(-time.Minute)},
+			{Type: providerclient.CloudResourceTypeLoadBalancer, ID: "lb-known"},
+			{Type: providerclient.CloudResourceTypeLoadBalancer, ID: "lb-ingress"},
+			{
+				Type: providerclient.CloudResourceTypeLoadBalancer,
+				ID:   "lb-orphan",
+				Tags: []*tags.Tag{{Key: "orphan", Value: "true"}, {Key: "orphan-since", Value: "2020-01-01T00:00:00Z"}},
+			},
+			{
+				Type: providerclient.CloudResourceTypeLoadBalancer,
+				ID:   "lb-adopted",
+				Tags: []*tags.Tag{{Key: "orphan", Value: "true"}, {Key: "orphan-since", Value: "2020-01-01T00:00:00Z"}, {Key: "team", Value: "a"}},
+			},
+			// Service isn't a load balancer anymore
+			{Type: providerclient.CloudResourceTypeLoadBalancer, ID: "cluster-ip"},
+		},
+		added:   make(map[string][]*tags.Tag),
+		deleted: make(map[string][]*tags.Tag),
+	}
+	objects := &sweepObjects{
+		pvs: []*v1.PersistentVolume{
+			{Spec: v1.PersistentVolumeSpec{PersistentVolumeSource: v1.PersistentVolumeSource{
+				CSI: &v1.CSIPersistentVolumeSource{VolumeHandle: "vol-known"},
+			}}},
+			{Spec: v1.PersistentVolumeSpec{PersistentVolumeSource: v1.PersistentVolumeSource{
+				HostPath: &v1.HostPathVolumeSource{Path: "/data"},
+			}}},
+			// Invalid persistent volume doesn't stop the sweep
+			{ObjectMeta: metav1.ObjectMeta{Name: "invalid"}, Spec: v1.PersistentVolumeSpec{PersistentVolumeSource: v1.PersistentVolumeSource{
+				CSI: &v1.CSIPersistentVolumeSource{},
+			}}},
+		},
+		svcs: []*v1.Service{
+			{ObjectMeta: metav1.ObjectMeta{Name: "lb-known"}, Spec: v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "lb-adopted"}, Spec: v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "cluster-ip"}, Spec: v1.ServiceSpec{Type: v1.ServiceTypeClusterIP}},
+		},
+		ingresses: []*networkingv1.Ingress{
+			{Status: networkingv1.IngressStatus{LoadBalancer: v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{{Hostname: "lb-ingress"}}}}},
+			{},
+		},
+	}
+	sweepCfg := &config.SweepConfig{
+		ClusterName:  "prod",
+		AddTags:      []*config.TagConfig{{Key: "orphan", Value: "true"}},
+		TimestampTag: "orphan-since",
+	}
+	cfg := &config.Configuration{Provider: config.AWSProviderName, Sweep: sweepCfg}
+	report := &SweepReport{
+		StartTime: startTime,
+		Orphans:   make([]*providerclient.CloudResource, 0),
+	}
+	context := &Context{}
+
+	err := context.sweepCloudResources(ctx.Background(), prcl, cfg, objects, report)
+	assert.NoError(t, err)
+	assert.Equal(t, 8, report.Resources)
+
+	orphanIDs := make([]string, 0)
+	for _, orphan := range report.Orphans {
+		orphanIDs = append(orphanIDs, orphan.ID)
+	}
+
+	assert.Equal(t, []string{"vol-orphan", "lb-orphan", "cluster-ip"}, orphanIDs)
+	assert.Equal(t, 1, report.RecentResources)
+	// Already tagged orphan isn't tagged again
+	assert.Equal(t, 2, report.TaggedOrphans)
+	assert.Equal(t, []*tags.Tag{
+		{Key: "orphan", Value: "true"},
+		{Key: "orphan-since", Value: "2021-06-01T10:00:00Z"},
+	}, prcl.added["vol-orphan"])
+	assert.NotContains(t, prcl.added, "lb-orphan")
+	// Orphan tags are removed from adopted resources
+	assert.Equal(t, 1, report.AdoptedResources)
+	assert.Equal(t, map[string][]*tags.Tag{
+		"lb-adopted": {{Key: "orphan", Value: "true"}, {Key: "orphan-since", Value: "2020-01-01T00:00:00Z"}},
+	}, prcl.deleted)
+	assert.Equal(t, []*SweepResourceError{
+		{Type: providerclient.CloudResourceTypeVolume, ObjectReference: "pv/invalid", Error: "invalid volume handle"},
+	}, report.ResourceErrors)
+
+	// Tagging errors are reported by resource without stopping the sweep
+	prcl.added = make(map[string][]*tags.Tag)
+	prcl.deleted = make(map[string][]*tags.Tag)
+	prcl.addErr = errors.New("access denied")
+	report = &SweepReport{StartTime: startTime, Orphans: make([]*providerclient.CloudResource, 0)}
+	err = context.sweepCloudResources(ctx.Background(), prcl, cfg, objects, report)
+	assert.NoError(t, err)
+	assert.Len(t, report.Orphans, 3)
+	assert.Equal(t, 0, report.TaggedOrphans)
+	assert.Equal(t, 1, report.AdoptedResources)
+	assert.Equal(t, []*SweepResourceError{
+		{Type: providerclient.CloudResourceTypeVolume, ObjectReference: "pv/invalid", Error: "invalid volume handle"},
+		{Type: providerclient.CloudResourceTypeVolume, ID: "vol-orphan", Error: "access denied"},
+		{Type: providerclient.CloudResourceTypeLoadBalancer, ID: "cluster-ip", Error: "access denied"},
+	}, report.ResourceErrors)
+}
+
+func Test_getSweepRemovedTags(t *testing.T) {
+	sweepCfg := &config.SweepConfig{AddTags: []*config.TagConfig{{Key: "orphan", Value: "true"}}, TimestampTag: "orphan-since"}
+
+	// Tag with a value not set by sweep is kept
+	got := getSweepRemovedTags(sweepCfg, []*tags.Tag{
+		{Key: "orphan", Value: "false"},
+		{Key: "orphan-since", Value: "2020-01-01T00:00:00Z"},
+		{Key: "team", Value: "a"},
+	})
+	assert.Equal(t, []*tags.Tag{{Key: "orphan-since", Value: "2020-01-01T00:00:00Z"}}, got)
+
+	// Report only mode
+	got = getSweepRemovedTags(&config.SweepConfig{}, []*tags.Tag{{Key: "orphan", Value: "true"}})
+	assert.Empty(t, got)
+}
+
+func Test_getSweepTags(t *testing.T) {
+	detectionTime := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	sweepCfg := &config.SweepConfig{AddTags: []*config.TagConfig{{Key: "orphan", Value: "true"}}, TimestampTag: "orphan-since"}
+
+	// Changed value is set again, timestamp is kept
+	got := getSweepTags(sweepCfg, []*tags.Tag{
+		{Key: "orphan", Value: "false"},
+		{Key: "orphan-since", Value: "2020-01-01T00:00:00Z"},
+	}, detectionTime)
+	assert.Equal(t, []*tags.Tag{{Key: "orphan", Value: "true"}}, got)
+
+	// Report only mode
+	got = getSweepTags(&config.SweepConfig{}, nil, detectionTime)
+	assert.Empty(t, got)
+}
+
+func Test_isWatchScopeUnfiltered(t *testing.T) {
+	assert.True(t, isWatchScopeUnfiltered(&config.Configuration{}))
+	assert.True(t, isWatchScopeUnfiltered(&config.Configuration{
+		LabelSelectors: &config.LabelSelectorsConfig{PersistentVolumeClaims: "team=a"},
+	}))
+	assert.False(t, isWatchScopeUnfiltered(&config.Configuration{WatchNamespaces: []string{"default"}}))
+	assert.False(t, isWatchScopeUnfiltered(&config.Configuration{ExcludeNamespaces: []string{"kube-system"}}))
+	assert.False(t, isWatchScopeUnfiltered(&config.Configuration{
+		LabelSelectors: &config.LabelSelectorsConfig{Services: "team=a"},
+	}))
+}
+
+func Test_listSweepObjectsFromAPI(t *testing.T) {
+	k8sClient := fake.NewSimpleClientset(
+		&v1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv1"}},
+		&v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "svc1", Namespace: "a"}},
+		&v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "svc2", Namespace: "b"}},
+	)
+
+	pvs, svcs, err := listSweepObjectsFromAPI(ctx.Background(), k8sClient.CoreV1())
+	assert.NoError(t, err)
+	assert.Len(t, pvs, 1)
+	assert.Len(t, svcs, 2)
+}
+
+func Test_listSweepIngresses(t *testing.T) {
+	k8sClient := fake.NewSimpleClientset(
+		&networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "a"}},
+	)
+
+	ingresses, err := listSweepIngresses(ctx.Background(), k8sClient.NetworkingV1())
+	assert.NoError(t, err)
+	assert.Len(t, ingresses, 1)
+}
@@ -0,0 +1,53 @@
+package business
+
+import (
+	ctx "context"
+	"errors"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/resources"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/tracing"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// startReconcileSpan Start span of an object reconcile.
+// It is started before resource creation to have all Kubernetes and cloud calls of the run in it,
+// runForResource adds resource attributes.
+func startReconcileSpan(workCtx ctx.Context, kind, reference string) (ctx.Context, oteltrace.Span) {
+	return tracing.StartSpan(workCtx, "reconcile "+kind, tracing.ObjectReferenceKey.String(reference))
+}
+
+// startOnDeleteSpan Start span of on delete actions run for a deleted object without cleanup finalizer.
+// Actions run by the finalizer are in the reconcile span.
+func startOnDeleteSpan(workCtx ctx.Context, kind, reference string) (ctx.Context, oteltrace.Span) {
+	return tracing.StartSpan(workCtx, "on delete "+kind, tracing.ObjectReferenceKey.String(reference))
+}
+
+// startSweepSpan Start span of a cloud sweep, tagging calls of all cloud resources are in it.
+func startSweepSpan(workCtx ctx.Context, clusterName string) (ctx.Context, oteltrace.Span) {
+	return tracing.StartSpan(workCtx, "sweep", tracing.ClusterNameKey.String(clusterName))
+}
+
+// endSweepSpan End span of a cloud sweep with its report results.
+func endSweepSpan(span oteltrace.Span, report *SweepReport) {
+	span.SetAttributes(
+		tracing.SweepResourcesKey.Int(report.Resources),
+		tracing.SweepOrphansKey.Int(len(report.Orphans)),
+		tracing.SweepResourceErrorsKey.Int(len(report.ResourceErrors)),
+	)
+
+	var err error
+	if report.Error != "" {
+		err = errors.New(report.Error)
+	}
+
+	tracing.EndSpan(span, err)
+}
+
+// setResourceSpanAttributes Add resource attributes to span of run context.
+func setResourceSpanAttributes(runCtx ctx.Context, resource resources.Resource) {
+	oteltrace.SpanFromContext(runCtx).SetAttributes(
+		tracing.ResourceTypeKey.String(resource.Type()),
+		tracing.ResourcePlatformKey.String(resource.Platform()),
+		tracing.ResourceIDKey.String(resource.ID()),
+	)
+}
@@ -0,0 +1,80 @@
+package business
+
+import (
+	ctx "context"
+	"testing"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	providerclient "github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/providerClient"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/rules"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/tracing"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func Test_calculateDeltaSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(oteltrace.NewNoopTracerProvider())
+
+	rulesList, err := rules.New([]*config.RuleConfig{{Action: "add", Tag: "team", Value: "a"}})
+	assert.NoError(t, err)
+
+	runCtx, span := startReconcileSpan(ctx.Background(), persistentVolumeKind, "pv/pv1")
+	_, delta, _, err := calculateDelta(runCtx, &fakeResource{resourceType: "volume"}, rulesList, nil, nil)
+	span.End()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, delta.Size())
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "rules evaluation", spans[0].Name())
+	assert.Equal(t, "reconcile persistentvolume", spans[1].Name())
+	assert.Contains(t, spans[1].Attributes(), tracing.ObjectReferenceKey.String("pv/pv1"))
+	assert.Equal(t, span.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Contains(t, spans[0].Attributes(), tracing.RulesCountKey.Int(1))
+	assert.Contains(t, spans[0].Attributes(), tracing.DeltaSizeKey.Int(1))
+}
+
+func Test_startOnDeleteSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(oteltrace.NewNoopTracerProvider())
+
+	runCtx, span := startOnDeleteSpan(ctx.Background(), serviceKind, "svc/ns1/svc1")
+	setResourceSpanAttributes(runCtx, &fakeResource{resourceType: "loadbalancer"})
+	span.End()
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "on delete service", spans[0].Name())
+	assert.Contains(t, spans[0].Attributes(), tracing.ObjectReferenceKey.String("svc/ns1/svc1"))
+	assert.Contains(t, spans[0].Attributes(), tracing.ResourceTypeKey.String("loadbalancer"))
+}
+
+func Test_sweepSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(oteltrace.NewNoopTracerProvider())
+
+	_, span := startSweepSpan(ctx.Background(), "cluster1")
+	endSweepSpan(span, &SweepReport{
+		Resources:      3,
+		Orphans:        []*providerclient.CloudResource{{ID: "vol-1"}},
+		ResourceErrors: []*SweepResourceError{},
+		Error:          "access denied",
+	})
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "sweep", spans[0].Name())
+	assert.Contains(t, spans[0].Attributes(), tracing.ClusterNameKey.String("cluster1"))
+	assert.Contains(t, spans[0].Attributes(), tracing.SweepResourcesKey.Int(3))
+	assert.Contains(t, spans[0].Attributes(), tracing.SweepOrphansKey.Int(1))
+	assert.Contains(t, spans[0].Attributes(), tracing.SweepResourceErrorsKey.Int(0))
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+}
@@ -0,0 +1,131 @@
+package business
+
+import (
+	ctx "context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync/atomic"
+	"time"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/resources"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// unchangedEntry Hash of an object at its last successful reconcile.
+type unchangedEntry struct {
+	hash string
+	time time.Time
+}
+
+// invalidateUnchanged Change rules version so that all objects are reconciled again.
+// It must be called when rules applied to objects may have changed.
+func (context *Context) invalidateUnchanged() {
+	atomic.AddUint64(&context.rulesVersion, 1)
+}
+
+// getObjectHash Hash object fields used in available tag values with the current rules version.
+// Related objects versions and cluster facts are included so that their changes are reconciled.
+// Finalizers and resource version are ignored because they change without impact on tags.
+func (context *Context) getObjectHash(
+	objectMeta *metav1.ObjectMeta,
+	spec, status interface{},
+	relatedVersions map[string]string,
+	clusterFacts *resources.ClusterFacts,
+) (string, error) {
+	content, err := json.Marshal(struct {
+		RulesVersion    uint64
+		Labels          map[string]string
+		Annotations     map[string]string
+		Spec            interface{}
+		Status          interface{}
+		RelatedVersions map[string]string
+		ClusterFacts    *resources.ClusterFacts
+	}{
+		RulesVersion:    atomic.LoadUint64(&context.rulesVersion),
+		Labels:          objectMeta.Labels,
+		Annotations:     objectMeta.Annotations,
+		Spec:            spec,
+		Status:          status,
+		RelatedVersions: relatedVersions,
+		ClusterFacts:    clusterFacts,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(content)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// getPersistentVolumeHash Hash persistent volume with its related objects, empty when unchanged objects aren't skipped.
+func (context *Context) getPersistentVolumeHash(runCtx ctx.Context, pv *v1.PersistentVolume, cfg *config.Configuration) (string, error) {
+	if !cfg.Reconcile.IsSkipUnchangedEnabled() {
+		return "", nil
+	}
+
+	relatedVersions, err := resources.GetPersistentVolumeRelatedVersions(runCtx, context.KubernetesClient, context.getObjectGetter(runCtx), pv)
+	if err != nil {
+		return "", err
+	}
+
+	return context.getObjectHash(&pv.ObjectMeta, pv.Spec, pv.Status, relatedVersions, context.getClusterFacts(runCtx, cfg))
+}
+
+// getServiceHash Hash service with its related objects, empty when unchanged objects aren't skipped.
+func (context *Context) getServiceHash(runCtx ctx.Context, svc *v1.Service, cfg *config.Configuration) (string, error) {
+	if !cfg.Reconcile.IsSkipUnchangedEnabled() {
+		return "", nil
+	}
+
+	relatedVersions, err := resources.GetServiceRelatedVersions(context.getObjectGetter(runCtx), svc)
+	if err != nil {
+		return "", err
+	}
+
+	return context.getObjectHash(&svc.ObjectMeta, svc.Spec, svc.Status, relatedVersions, context.getClusterFacts(runCtx, cfg))
+}
+
+// isUnchanged Checks if object has the same hash than at its last successful reconcile not older than max age.
+func (context *Context) isUnchanged(reference, hash string, cfg *config.Configuration) bool {
+	if !cfg.Reconcile.IsSkipUnchangedEnabled() {
+		return false
+	}
+
+	// Max age is checked by configuration validation
+	maxAge, _ := cfg.Reconcile.SkipUnchanged.GetMaxAge()
+
+	context.unchangedMutex.Lock()
+	defer context.unchangedMutex.Unlock()
+
+	entry, ok := context.unchanged[reference]
+
+	return ok && entry.hash == hash && time.Since(entry.time) < maxAge
+}
+
+// recordUnchanged Save object hash after a successful reconcile.
+func (context *Context) recordUnchanged(reference, hash string, cfg *config.Configuration) {
+	if !cfg.Reconcile.IsSkipUnchangedEnabled() {
+		return
+	}
+
+	context.unchangedMutex.Lock()
+	defer context.unchangedMutex.Unlock()
+
+	if context.unchanged == nil {
+		context.unchanged = make(map[string]*unchangedEntry)
+	}
+
+	context.unchanged[reference] = &unchangedEntry{hash: hash, time: time.Now()}
+}
+
+// forgetUnchanged Remove object hash so that object is reconciled on next run.
+func (context *Context) forgetUnchanged(reference string) {
+	context.unchangedMutex.Lock()
+	defer context.unchangedMutex.Unlock()
+
+	delete(context.unchanged, reference)
+}
@@ -0,0 +1,72 @@
+package business
+
+import (
+	"testing"
+	"time"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/resources"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestContext_getObjectHash(t *testing.T) {
+	context := &Context{}
+	pv := &v1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv1", Labels: map[string]string{"team": "a"}, ResourceVersion: "1"},
+	}
+
+	hash, err := context.getObjectHash(&pv.ObjectMeta, pv.Spec, pv.Status, nil, nil)
+	assert.NoError(t, err)
+
+	// Resource version and finalizers are ignored
+	pv.ResourceVersion = "2"
+	pv.Finalizers = []string{CleanupFinalizer}
+	got, _ := context.getObjectHash(&pv.ObjectMeta, pv.Spec, pv.Status, nil, nil)
+	assert.Equal(t, hash, got)
+
+	pv.Labels["team"] = "b"
+	got, _ = context.getObjectHash(&pv.ObjectMeta, pv.Spec, pv.Status, nil, nil)
+	assert.NotEqual(t, hash, got)
+
+	// Rules changes invalidate hashes
+	hash = got
+	context.invalidateUnchanged()
+	got, _ = context.getObjectHash(&pv.ObjectMeta, pv.Spec, pv.Status, nil, nil)
+	assert.NotEqual(t, hash, got)
+
+	// Related objects and cluster facts changes too
+	hash = got
+	got, _ = context.getObjectHash(&pv.ObjectMeta, pv.Spec, pv.Status, map[string]string{"namespace": "1"}, nil)
+	assert.NotEqual(t, hash, got)
+
+	hash = got
+	got, _ = context.getObjectHash(&pv.ObjectMeta, pv.Spec, pv.Status, map[string]string{"namespace": "1"}, &resources.ClusterFacts{ID: "a"})
+	assert.NotEqual(t, hash, got)
+}
+
+func TestContext_isUnchanged(t *testing.T) {
+	context := &Context{}
+	disabledCfg := &config.Configuration{}
+	cfg := &config.Configuration{Reconcile: &config.ReconcileConfig{
+		SkipUnchanged: &config.SkipUnchangedConfig{Enabled: true, MaxAge: "1h"},
+	}}
+
+	// Nothing is recorded when disabled
+	context.recordUnchanged("persistentvolume/pv1", "hash1", disabledCfg)
+	assert.False(t, context.isUnchanged("persistentvolume/pv1", "hash1", cfg))
+
+	context.recordUnchanged("persistentvolume/pv1", "hash1", cfg)
+	assert.True(t, context.isUnchanged("persistentvolume/pv1", "hash1", cfg))
+	assert.False(t, context.isUnchanged("persistentvolume/pv1", "hash2", cfg))
+	assert.False(t, context.isUnchanged("persistentvolume/pv1", "hash1", disabledCfg))
+
+	// Old entries are reconciled anyway
+	context.unchanged["persistentvolume/pv1"].time = time.Now().Add(-2 * time.Hour)
+	assert.False(t, context.isUnchanged("persistentvolume/pv1", "hash1", cfg))
+
+	context.recordUnchanged("persistentvolume/pv1", "hash1", cfg)
+	context.forgetUnchanged("persistentvolume/pv1")
+	assert.False(t, context.isUnchanged("persistentvolume/pv1", "hash1", cfg))
+}
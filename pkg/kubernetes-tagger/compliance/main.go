@@ -0,0 +1,69 @@
+package compliance
+
+import (
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/tags"
+	"github.com/thoas/go-funk"
+)
+
+// ViolationReason Reason of a compliance violation.
+type ViolationReason string
+
+// ViolationReasonMissing Required tag is missing.
+const ViolationReasonMissing = ViolationReason("missing")
+
+// ViolationReasonInvalidValue Required tag value doesn't match the allowed pattern.
+const ViolationReasonInvalidValue = ViolationReason("invalid-value")
+
+// Violation Required tag violation on a resource.
+type Violation struct {
+	Key     string          `json:"key"`
+	Reason  ViolationReason `json:"reason"`
+	Value   string          `json:"value,omitempty"`
+	Pattern string          `json:"pattern,omitempty"`
+}
+
+// Check Get required tags violations for a resource type with its tags.
+func Check(complianceConfig *config.ComplianceConfig, resourceType string, resourceTags []*tags.Tag) ([]*Violation, error) {
+	violations := make([]*Violation, 0)
+
+	if complianceConfig == nil {
+		return violations, nil
+	}
+
+	// Index tags values by key
+	tagValues := make(map[string]string)
+	for _, tag := range resourceTags {
+		tagValues[tag.Key] = tag.Value
+	}
+
+	for _, requiredTag := range complianceConfig.RequiredTags {
+		// Check if tag is required for this resource type
+		if len(requiredTag.ResourceTypes) != 0 && !funk.ContainsString(requiredTag.ResourceTypes, resourceType) {
+			continue
+		}
+
+		value, exists := tagValues[requiredTag.Key]
+		if !exists {
+			violations = append(violations, &Violation{Key: requiredTag.Key, Reason: ViolationReasonMissing})
+
+			continue
+		}
+
+		pattern, err := requiredTag.GetPatternRegexp()
+		if err != nil {
+			return nil, err
+		}
+
+		if pattern != nil && !pattern.MatchString(value) {
+			violations = append(violations, &Violation{
+				Key:     requiredTag.Key,
+				Reason:  ViolationReasonInvalidValue,
+				Value:   value,
+				Pattern: requiredTag.Pattern,
+			})
+		}
+	}
+
+	return violations, nil
+}
@@ -0,0 +1,77 @@
+package compliance
+
+import (
+	"testing"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/tags"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	complianceConfig := &config.ComplianceConfig{RequiredTags: []*config.RequiredTagConfig{
+		{Key: "CostCenter", Pattern: "[0-9]{4}"},
+		{Key: "Owner"},
+		{Key: "Environment", Pattern: "dev|prod", ResourceTypes: []string{"volume"}},
+	}}
+
+	tests := []struct {
+		name         string
+		resourceType string
+		tags         []*tags.Tag
+		want         []*Violation
+	}{
+		{
+			name:         "compliant",
+			resourceType: "volume",
+			tags: []*tags.Tag{
+				{Key: "CostCenter", Value: "1234"},
+				{Key: "Owner", Value: "team-a"},
+				{Key: "Environment", Value: "prod"},
+			},
+			want: []*Violation{},
+		},
+		{
+			name:         "missing tags",
+			resourceType: "volume",
+			tags:         []*tags.Tag{{Key: "CostCenter", Value: "1234"}},
+			want: []*Violation{
+				{Key: "Owner", Reason: ViolationReasonMissing},
+				{Key: "Environment", Reason: ViolationReasonMissing},
+			},
+		},
+		{
+			name:         "invalid values must match the whole pattern",
+			resourceType: "volume",
+			tags: []*tags.Tag{
+				{Key: "CostCenter", Value: "12345"},
+				{Key: "Owner", Value: ""},
+				{Key: "Environment", Value: "production"},
+			},
+			want: []*Violation{
+				{Key: "CostCenter", Reason: ViolationReasonInvalidValue, Value: "12345", Pattern: "[0-9]{4}"},
+				{Key: "Environment", Reason: ViolationReasonInvalidValue, Value: "production", Pattern: "dev|prod"},
+			},
+		},
+		{
+			name:         "tag not required for resource type",
+			resourceType: "loadbalancer",
+			tags:         []*tags.Tag{{Key: "CostCenter", Value: "1234"}, {Key: "Owner", Value: "team-a"}},
+			want:         []*Violation{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Check(complianceConfig, tt.resourceType, tt.tags)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	// No configuration
+	got, err := Check(nil, "volume", nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []*Violation{}, got)
+}
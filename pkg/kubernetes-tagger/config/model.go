@@ -2,8 +2,15 @@ package config
 
 import (
 	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/thoas/go-funk"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
 )
 
 // RecommendedConfigFileName Recommended Configuration File Name.
@@ -27,16 +34,677 @@ var ErrEmptyAWSConfiguration = errors.New("aws configuration is empty")
 // ErrEmptyAWSRegionConfiguration Error Empty AWS Region Configuration.
 var ErrEmptyAWSRegionConfiguration = errors.New("aws region is empty in configuration")
 
+// ErrInvalidLabelSelector Invalid label selector error.
+var ErrInvalidLabelSelector = errors.New("invalid label selector")
+
+// ErrEmptyWebhookTLSFiles Error Empty webhook TLS files.
+var ErrEmptyWebhookTLSFiles = errors.New("webhook certificate and key files mustn't be empty")
+
+// ErrWebhookMissingLabelsActionNotSupported Webhook missing labels action not supported error.
+var ErrWebhookMissingLabelsActionNotSupported = errors.New("webhook missing labels action not supported")
+
+// WebhookMissingLabelsActionReject Reject objects with missing labels.
+const WebhookMissingLabelsActionReject = "reject"
+
+// WebhookMissingLabelsActionWarn Accept objects with missing labels with a warning.
+const WebhookMissingLabelsActionWarn = "warn"
+
+// ErrEmptyListEntry Empty list entry error.
+var ErrEmptyListEntry = errors.New("list entry mustn't be empty")
+
+// ErrReverseSyncEmptyTag Reverse sync rule empty tag error.
+var ErrReverseSyncEmptyTag = errors.New("reverse sync rule tag mustn't be empty")
+
+// ErrReverseSyncTargetNotSupported Reverse sync rule target not supported error.
+var ErrReverseSyncTargetNotSupported = errors.New("reverse sync rule target not supported")
+
+// ErrReverseSyncConflictNotSupported Reverse sync rule conflict mode not supported error.
+var ErrReverseSyncConflictNotSupported = errors.New("reverse sync rule conflict mode not supported")
+
+// ErrReverseSyncInvalidAnnotation Reverse sync rule invalid annotation error.
+var ErrReverseSyncInvalidAnnotation = errors.New("reverse sync rule annotation is invalid")
+
+// ErrComplianceEmptyRequiredTagKey Compliance required tag empty key error.
+var ErrComplianceEmptyRequiredTagKey = errors.New("compliance required tag key mustn't be empty")
+
+// ErrComplianceInvalidPattern Compliance required tag invalid pattern error.
+var ErrComplianceInvalidPattern = errors.New("compliance required tag pattern is invalid")
+
+// ErrOnDeleteEmptyTagKey On delete added tag empty key error.
+var ErrOnDeleteEmptyTagKey = errors.New("on delete tag key mustn't be empty")
+
+// ErrOnDeleteInvalidFinalizerTimeout On delete finalizer invalid timeout error.
+var ErrOnDeleteInvalidFinalizerTimeout = errors.New("on delete finalizer timeout is invalid")
+
+// DefaultOnDeleteFinalizerTimeout Default maximum duration of on delete actions retries with finalizer.
+const DefaultOnDeleteFinalizerTimeout = 10 * time.Minute
+
+// ErrSweepEmptyClusterName Sweep empty cluster name error.
+var ErrSweepEmptyClusterName = errors.New("sweep cluster name mustn't be empty")
+
+// ErrSweepInvalidInterval Sweep invalid interval error.
+var ErrSweepInvalidInterval = errors.New("sweep interval is invalid")
+
+// ErrSweepEmptyTagKey Sweep added tag empty key error.
+var ErrSweepEmptyTagKey = errors.New("sweep tag key mustn't be empty")
+
+// ErrSweepInvalidGracePeriod Sweep invalid grace period error.
+var ErrSweepInvalidGracePeriod = errors.New("sweep grace period is invalid")
+
+// DefaultSweepInterval Default duration between two cloud sweeps.
+const DefaultSweepInterval = time.Hour
+
+// DefaultSweepGracePeriod Default age of cloud resources before they can be orphans.
+const DefaultSweepGracePeriod = 15 * time.Minute
+
+// ErrReconcileInvalidWorkers Reconcile invalid workers number error.
+var ErrReconcileInvalidWorkers = errors.New("reconcile workers number mustn't be negative")
+
+// ErrReconcileInvalidResyncPeriod Reconcile invalid resync period error.
+var ErrReconcileInvalidResyncPeriod = errors.New("reconcile resync period is invalid")
+
+// ErrReconcileInvalidSkipUnchangedMaxAge Reconcile invalid skip unchanged max age error.
+var ErrReconcileInvalidSkipUnchangedMaxAge = errors.New("reconcile skip unchanged max age is invalid")
+
+// DefaultReconcileWorkers Default number of concurrent reconcile workers.
+const DefaultReconcileWorkers = 2
+
+// DefaultResyncPeriod Default informers resync period.
+const DefaultResyncPeriod = time.Minute
+
+// DefaultSkipUnchangedMaxAge Default duration after which unchanged objects are reconciled anyway.
+const DefaultSkipUnchangedMaxAge = time.Hour
+
+// ErrLeaderElectionLockTypeNotSupported Leader election lock type not supported error.
+var ErrLeaderElectionLockTypeNotSupported = errors.New("leader election lock type not supported")
+
+// ErrLeaderElectionInvalidDuration Leader election invalid duration error.
+var ErrLeaderElectionInvalidDuration = errors.New("leader election duration is invalid")
+
+// Default values for leader election.
+const (
+	DefaultLeaderElectionLockType      = resourcelock.LeasesResourceLock
+	DefaultLeaderElectionLockName      = "kubernetes-tagger"
+	DefaultLeaderElectionLeaseDuration = 15 * time.Second
+	DefaultLeaderElectionRenewDeadline = 10 * time.Second
+	DefaultLeaderElectionRetryPeriod   = 2 * time.Second
+)
+
+// SupportedLeaderElectionLockTypes Leader election lock types supported.
+// Endpoints and config maps leases locks are only used to migrate from deprecated locks.
+var SupportedLeaderElectionLockTypes = []string{
+	resourcelock.LeasesResourceLock,
+	resourcelock.EndpointsLeasesResourceLock,
+	resourcelock.ConfigMapsLeasesResourceLock,
+}
+
+// ErrAuditSinkNotSupported Audit sink not supported error.
+var ErrAuditSinkNotSupported = errors.New("audit sink not supported")
+
+// ErrAuditEmptyFilePath Audit file sink empty path error.
+var ErrAuditEmptyFilePath = errors.New("audit file path mustn't be empty")
+
+// ErrAuditInvalidFileRotation Audit file sink invalid rotation error.
+var ErrAuditInvalidFileRotation = errors.New("audit file rotation is invalid")
+
+// ErrAuditEmptyWebhookURL Audit webhook sink empty URL error.
+var ErrAuditEmptyWebhookURL = errors.New("audit webhook url mustn't be empty")
+
+// ErrAuditInvalidWebhookTimeout Audit webhook sink invalid timeout error.
+var ErrAuditInvalidWebhookTimeout = errors.New("audit webhook timeout is invalid")
+
+// ErrAuditInvalidRedactPattern Audit redact key pattern invalid error.
+var ErrAuditInvalidRedactPattern = errors.New("audit redact key pattern is invalid")
+
+// ErrTracingExporterNotSupported Tracing exporter not supported error.
+var ErrTracingExporterNotSupported = errors.New("tracing exporter not supported")
+
+// ErrTracingInvalidSampleRatio Tracing invalid sample ratio error.
+var ErrTracingInvalidSampleRatio = errors.New("tracing sample ratio must be between 0 and 1")
+
+// Tracing exporters.
+const (
+	TracingExporterOTLP   = "otlp"
+	TracingExporterStdout = "stdout"
+)
+
+// SupportedTracingExporters Tracing exporters supported.
+var SupportedTracingExporters = []string{TracingExporterOTLP, TracingExporterStdout}
+
+// DefaultTracingServiceName Default service name of spans.
+const DefaultTracingServiceName = "kubernetes-tagger"
+
+// Audit sinks.
+const (
+	AuditSinkStdout  = "stdout"
+	AuditSinkFile    = "file"
+	AuditSinkWebhook = "webhook"
+)
+
+// SupportedAuditSinks Audit sinks supported.
+var SupportedAuditSinks = []string{AuditSinkStdout, AuditSinkFile, AuditSinkWebhook}
+
+// Default values for audit sinks.
+const (
+	DefaultAuditFileMaxSize    = 100
+	DefaultAuditFileMaxBackups = 5
+	DefaultAuditWebhookTimeout = 5 * time.Second
+)
+
+// DefaultReverseSyncAnnotationPrefix Default prefix of annotations set by reverse sync.
+const DefaultReverseSyncAnnotationPrefix = "kubernetes-tagger.oxyno-zeta.com/"
+
+// Reverse sync targets.
+const (
+	ReverseSyncTargetPersistentVolume      = "persistentvolume"
+	ReverseSyncTargetPersistentVolumeClaim = "persistentvolumeclaim"
+	ReverseSyncTargetService               = "service"
+)
+
+// ReverseSyncConflictOverwrite Overwrite annotations with a different value.
+const ReverseSyncConflictOverwrite = "overwrite"
+
+// ReverseSyncConflictSkip Keep annotations with a different value.
+const ReverseSyncConflictSkip = "skip"
+
 // Configuration configuration.
 type Configuration struct {
-	Namespace  string        `mapstructure:"namespace"`
-	Kubeconfig string        `mapstructure:"kubeconfig"`
-	Address    string        `mapstructure:"address"`
-	LogLevel   string        `mapstructure:"loglevel"`
-	LogFormat  string        `mapstructure:"logformat"`
-	AWS        *AWSConfig    `mapstructure:"aws"`
-	Rules      []*RuleConfig `mapstructure:"rules"`
-	Provider   string        `mapstructure:"provider"`
+	Namespace         string                `mapstructure:"namespace"`
+	Kubeconfig        string                `mapstructure:"kubeconfig"`
+	Address           string                `mapstructure:"address"`
+	LogLevel          string                `mapstructure:"loglevel"`
+	LogFormat         string                `mapstructure:"logformat"`
+	AWS               *AWSConfig            `mapstructure:"aws"`
+	Rules             []*RuleConfig         `mapstructure:"rules"`
+	Provider          string                `mapstructure:"provider"`
+	WatchNamespaces   []string              `mapstructure:"watchNamespaces"`
+	ExcludeNamespaces []string              `mapstructure:"excludeNamespaces"`
+	LabelSelectors    *LabelSelectorsConfig `mapstructure:"labelSelectors"`
+	DebugEndpoints    bool                  `mapstructure:"debugEndpoints"`
+	TaggingPolicies   bool                  `mapstructure:"taggingPolicies"`
+	Webhook           *WebhookConfig        `mapstructure:"webhook"`
+	ReverseSync       *ReverseSyncConfig    `mapstructure:"reverseSync"`
+	Compliance        *ComplianceConfig     `mapstructure:"compliance"`
+	// Static cluster values by key added to discovered ones in available tag values
+	Cluster  map[string]string `mapstructure:"cluster"`
+	OnDelete *OnDeleteConfig   `mapstructure:"onDelete"`
+	Sweep    *SweepConfig      `mapstructure:"sweep"`
+	// Workers, informers resync and unchanged objects tuning (needs a restart except skipUnchanged)
+	Reconcile *ReconcileConfig `mapstructure:"reconcile"`
+	// Leader election (needs a restart)
+	LeaderElection *LeaderElectionConfig `mapstructure:"leaderElection"`
+	Audit          *AuditConfig          `mapstructure:"audit"`
+	// OpenTelemetry tracing (needs a restart)
+	Tracing *TracingConfig `mapstructure:"tracing"`
+}
+
+// TracingConfig OpenTelemetry tracing of reconciles with their Kubernetes and cloud calls.
+type TracingConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Exporter of spans (otlp or stdout), otlp when empty
+	Exporter string             `mapstructure:"exporter"`
+	OTLP     *TracingOTLPConfig `mapstructure:"otlp"`
+	// Service name of spans (DefaultTracingServiceName when empty)
+	ServiceName string `mapstructure:"serviceName"`
+	// Ratio of traced runs between 0 and 1 (1 when empty)
+	SampleRatio float64 `mapstructure:"sampleRatio"`
+}
+
+// TracingOTLPConfig OTLP over HTTP exporter, OTEL_EXPORTER_OTLP_* environment variables are used for empty values.
+type TracingOTLPConfig struct {
+	// Collector host and port (ex: otel-collector:4318)
+	Endpoint string            `mapstructure:"endpoint"`
+	Insecure bool              `mapstructure:"insecure"`
+	Headers  map[string]string `mapstructure:"headers"`
+}
+
+// IsTracingEnabled Checks if tracing is enabled.
+func (tc *TracingConfig) IsTracingEnabled() bool {
+	return tc != nil && tc.Enabled
+}
+
+// GetExporter Get spans exporter.
+func (tc *TracingConfig) GetExporter() string {
+	if tc.Exporter == "" {
+		return TracingExporterOTLP
+	}
+
+	return tc.Exporter
+}
+
+// GetServiceName Get service name of spans.
+func (tc *TracingConfig) GetServiceName() string {
+	if tc.ServiceName == "" {
+		return DefaultTracingServiceName
+	}
+
+	return tc.ServiceName
+}
+
+// GetSampleRatio Get ratio of traced runs.
+func (tc *TracingConfig) GetSampleRatio() float64 {
+	if tc.SampleRatio == 0 {
+		return 1
+	}
+
+	return tc.SampleRatio
+}
+
+// AuditConfig Audit log with one record per cloud tag mutation.
+type AuditConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Sink receiving records (stdout, file or webhook), stdout when empty
+	Sink    string              `mapstructure:"sink"`
+	File    *AuditFileConfig    `mapstructure:"file"`
+	Webhook *AuditWebhookConfig `mapstructure:"webhook"`
+	// Regular expressions on tag keys, values of matching tags are redacted
+	RedactKeyPatterns []string `mapstructure:"redactKeyPatterns"`
+}
+
+// AuditFileConfig Audit file sink rotated by size, defaults are used for empty values.
+type AuditFileConfig struct {
+	Path string `mapstructure:"path"`
+	// Maximum size in megabytes before rotation
+	MaxSize int `mapstructure:"maxSize"`
+	// Number of rotated files kept (5 when empty), at least one rotated file is always kept
+	MaxBackups int `mapstructure:"maxBackups"`
+}
+
+// AuditWebhookConfig Audit HTTP webhook sink receiving records with POST requests.
+type AuditWebhookConfig struct {
+	URL string `mapstructure:"url"`
+	// Request timeout (DefaultAuditWebhookTimeout when empty)
+	Timeout string            `mapstructure:"timeout"`
+	Headers map[string]string `mapstructure:"headers"`
+}
+
+// IsAuditEnabled Checks if audit log is enabled.
+func (ac *AuditConfig) IsAuditEnabled() bool {
+	return ac != nil && ac.Enabled
+}
+
+// GetSink Get audit sink.
+func (ac *AuditConfig) GetSink() string {
+	if ac.Sink == "" {
+		return AuditSinkStdout
+	}
+
+	return ac.Sink
+}
+
+// GetRedactKeyRegexps Get regular expressions of tag keys with redacted values.
+func (ac *AuditConfig) GetRedactKeyRegexps() ([]*regexp.Regexp, error) {
+	if ac == nil {
+		return []*regexp.Regexp{}, nil
+	}
+
+	result := make([]*regexp.Regexp, 0, len(ac.RedactKeyPatterns))
+
+	for i, pattern := range ac.RedactKeyPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("audit.redactKeyPatterns[%d]: %w: %v", i, ErrAuditInvalidRedactPattern, err)
+		}
+
+		result = append(result, re)
+	}
+
+	return result, nil
+}
+
+// GetMaxSize Get maximum file size in megabytes.
+func (afc *AuditFileConfig) GetMaxSize() int {
+	if afc.MaxSize == 0 {
+		return DefaultAuditFileMaxSize
+	}
+
+	return afc.MaxSize
+}
+
+// GetMaxBackups Get number of rotated files kept.
+func (afc *AuditFileConfig) GetMaxBackups() int {
+	if afc.MaxBackups == 0 {
+		return DefaultAuditFileMaxBackups
+	}
+
+	return afc.MaxBackups
+}
+
+// GetTimeout Get webhook request timeout.
+func (awc *AuditWebhookConfig) GetTimeout() (time.Duration, error) {
+	if awc.Timeout == "" {
+		return DefaultAuditWebhookTimeout, nil
+	}
+
+	return time.ParseDuration(awc.Timeout)
+}
+
+// LeaderElectionConfig Leader election configuration, defaults are used for empty values.
+type LeaderElectionConfig struct {
+	// Instance is always the leader when disabled, only one instance must run (enabled when not set)
+	Enabled       *bool  `mapstructure:"enabled"`
+	LockType      string `mapstructure:"lockType"`
+	LockName      string `mapstructure:"lockName"`
+	LockNamespace string `mapstructure:"lockNamespace"`
+	LeaseDuration string `mapstructure:"leaseDuration"`
+	RenewDeadline string `mapstructure:"renewDeadline"`
+	RetryPeriod   string `mapstructure:"retryPeriod"`
+}
+
+// LeaderElectionDurations Parsed leader election durations.
+type LeaderElectionDurations struct {
+	LeaseDuration time.Duration
+	RenewDeadline time.Duration
+	RetryPeriod   time.Duration
+}
+
+// IsLeaderElectionEnabled Checks if leader election is enabled, it is by default.
+// Block set without enabled value, only to change the lock for example, keeps it enabled.
+func (lec *LeaderElectionConfig) IsLeaderElectionEnabled() bool {
+	return lec == nil || lec.Enabled == nil || *lec.Enabled
+}
+
+// GetLockType Get leader election lock type.
+func (lec *LeaderElectionConfig) GetLockType() string {
+	if lec == nil || lec.LockType == "" {
+		return DefaultLeaderElectionLockType
+	}
+
+	return lec.LockType
+}
+
+// GetLockName Get leader election lock name.
+func (lec *LeaderElectionConfig) GetLockName() string {
+	if lec == nil || lec.LockName == "" {
+		return DefaultLeaderElectionLockName
+	}
+
+	return lec.LockName
+}
+
+// GetLockNamespace Get leader election lock namespace, default namespace is the deployment one.
+func (lec *LeaderElectionConfig) GetLockNamespace(defaultNamespace string) string {
+	if lec == nil || lec.LockNamespace == "" {
+		return defaultNamespace
+	}
+
+	return lec.LockNamespace
+}
+
+// GetDurations Get leader election durations.
+func (lec *LeaderElectionConfig) GetDurations() (*LeaderElectionDurations, error) {
+	leCfg := &LeaderElectionConfig{}
+	if lec != nil {
+		leCfg = lec
+	}
+
+	durations := &LeaderElectionDurations{}
+
+	for _, item := range []struct {
+		name         string
+		value        string
+		defaultValue time.Duration
+		duration     *time.Duration
+	}{
+		{"leaseDuration", leCfg.LeaseDuration, DefaultLeaderElectionLeaseDuration, &durations.LeaseDuration},
+		{"renewDeadline", leCfg.RenewDeadline, DefaultLeaderElectionRenewDeadline, &durations.RenewDeadline},
+		{"retryPeriod", leCfg.RetryPeriod, DefaultLeaderElectionRetryPeriod, &durations.RetryPeriod},
+	} {
+		*item.duration = item.defaultValue
+
+		if item.value == "" {
+			continue
+		}
+
+		duration, err := time.ParseDuration(item.value)
+		if err != nil {
+			return nil, fmt.Errorf("leaderElection.%s: %w: %v", item.name, ErrLeaderElectionInvalidDuration, err)
+		}
+
+		if duration <= 0 {
+			return nil, fmt.Errorf("leaderElection.%s: %w: must be positive", item.name, ErrLeaderElectionInvalidDuration)
+		}
+
+		*item.duration = duration
+	}
+
+	return durations, nil
+}
+
+// ReconcileConfig Reconcile tuning configuration.
+type ReconcileConfig struct {
+	// Number of concurrent reconcile workers (DefaultReconcileWorkers when 0)
+	Workers       int                  `mapstructure:"workers"`
+	Resync        *ResyncConfig        `mapstructure:"resync"`
+	SkipUnchanged *SkipUnchangedConfig `mapstructure:"skipUnchanged"`
+}
+
+// ResyncConfig Informers resync periods by resource type (DefaultResyncPeriod when empty, "0" disables resync).
+type ResyncConfig struct {
+	PersistentVolumes string `mapstructure:"persistentVolumes"`
+	Services          string `mapstructure:"services"`
+	// Namespaces, pods and storage classes
+	RelatedObjects  string `mapstructure:"relatedObjects"`
+	TaggingPolicies string `mapstructure:"taggingPolicies"`
+}
+
+// ResyncPeriods Parsed informers resync periods by resource type.
+type ResyncPeriods struct {
+	PersistentVolumes time.Duration
+	Services          time.Duration
+	RelatedObjects    time.Duration
+	TaggingPolicies   time.Duration
+}
+
+// SkipUnchangedConfig Skip cloud calls for objects unchanged since their last successful reconcile.
+type SkipUnchangedConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Duration after which unchanged objects are reconciled anyway (DefaultSkipUnchangedMaxAge when empty)
+	MaxAge string `mapstructure:"maxAge"`
+}
+
+// GetWorkers Get number of concurrent reconcile workers.
+func (rc *ReconcileConfig) GetWorkers() int {
+	if rc == nil || rc.Workers == 0 {
+		return DefaultReconcileWorkers
+	}
+
+	return rc.Workers
+}
+
+// GetResyncPeriods Get informers resync periods.
+func (rc *ReconcileConfig) GetResyncPeriods() (*ResyncPeriods, error) {
+	resyncCfg := &ResyncConfig{}
+	if rc != nil && rc.Resync != nil {
+		resyncCfg = rc.Resync
+	}
+
+	periods := &ResyncPeriods{}
+
+	for _, item := range []struct {
+		name   string
+		value  string
+		period *time.Duration
+	}{
+		{"persistentVolumes", resyncCfg.PersistentVolumes, &periods.PersistentVolumes},
+		{"services", resyncCfg.Services, &periods.Services},
+		{"relatedObjects", resyncCfg.RelatedObjects, &periods.RelatedObjects},
+		{"taggingPolicies", resyncCfg.TaggingPolicies, &periods.TaggingPolicies},
+	} {
+		period, err := parseResyncPeriod(item.value)
+		if err != nil {
+			return nil, fmt.Errorf("reconcile.resync.%s: %w: %v", item.name, ErrReconcileInvalidResyncPeriod, err)
+		}
+
+		*item.period = period
+	}
+
+	return periods, nil
+}
+
+// IsSkipUnchangedEnabled Checks if unchanged objects are skipped.
+func (rc *ReconcileConfig) IsSkipUnchangedEnabled() bool {
+	return rc != nil && rc.SkipUnchanged != nil && rc.SkipUnchanged.Enabled
+}
+
+// GetMaxAge Get duration after which unchanged objects are reconciled anyway.
+func (suc *SkipUnchangedConfig) GetMaxAge() (time.Duration, error) {
+	if suc.MaxAge == "" {
+		return DefaultSkipUnchangedMaxAge, nil
+	}
+
+	return time.ParseDuration(suc.MaxAge)
+}
+
+func parseResyncPeriod(value string) (time.Duration, error) {
+	if value == "" {
+		return DefaultResyncPeriod, nil
+	}
+
+	period, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+
+	if period < 0 {
+		return 0, errors.New("must not be negative")
+	}
+
+	return period, nil
+}
+
+// SweepConfig Periodic sweep of cloud resources having the cluster tag without Kubernetes object.
+type SweepConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Cluster name in kubernetes.io/cluster/<name> tag
+	ClusterName string `mapstructure:"clusterName"`
+	// Duration between two sweeps (DefaultSweepInterval when empty)
+	Interval string `mapstructure:"interval"`
+	// Cloud resources created since less than this duration aren't orphans (DefaultSweepGracePeriod when empty)
+	GracePeriod string `mapstructure:"gracePeriod"`
+	// Tags added to orphan cloud resources, nothing is tagged when empty
+	AddTags []*TagConfig `mapstructure:"addTags"`
+	// Tag key added with the first detection time (RFC 3339) as value
+	TimestampTag string `mapstructure:"timestampTag"`
+}
+
+// IsSweepEnabled Checks if cloud sweep is enabled.
+func (sc *SweepConfig) IsSweepEnabled() bool {
+	return sc != nil && sc.Enabled
+}
+
+// GetInterval Get sweep interval.
+func (sc *SweepConfig) GetInterval() (time.Duration, error) {
+	if sc.Interval == "" {
+		return DefaultSweepInterval, nil
+	}
+
+	return time.ParseDuration(sc.Interval)
+}
+
+// GetGracePeriod Get sweep grace period.
+func (sc *SweepConfig) GetGracePeriod() (time.Duration, error) {
+	if sc.GracePeriod == "" {
+		return DefaultSweepGracePeriod, nil
+	}
+
+	return time.ParseDuration(sc.GracePeriod)
+}
+
+// OnDeleteConfig Actions on cloud resources when Kubernetes objects are deleted by resource type.
+// Nothing is done for a resource type without actions.
+type OnDeleteConfig struct {
+	Volume       *OnDeleteActionsConfig `mapstructure:"volume"`
+	LoadBalancer *OnDeleteActionsConfig `mapstructure:"loadBalancer"`
+	// Run actions before objects are deleted with a finalizer
+	Finalizer *OnDeleteFinalizerConfig `mapstructure:"finalizer"`
+}
+
+// OnDeleteFinalizerConfig On delete finalizer configuration.
+type OnDeleteFinalizerConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Duration after deletion when finalizer is removed even if actions fail (DefaultOnDeleteFinalizerTimeout when empty)
+	Timeout string `mapstructure:"timeout"`
+}
+
+// IsFinalizerEnabled Checks if on delete actions are run with a finalizer.
+func (odc *OnDeleteConfig) IsFinalizerEnabled() bool {
+	return odc != nil && odc.Finalizer != nil && odc.Finalizer.Enabled
+}
+
+// GetTimeout Get finalizer timeout.
+func (odfc *OnDeleteFinalizerConfig) GetTimeout() (time.Duration, error) {
+	if odfc.Timeout == "" {
+		return DefaultOnDeleteFinalizerTimeout, nil
+	}
+
+	return time.ParseDuration(odfc.Timeout)
+}
+
+// OnDeleteActionsConfig Actions on a cloud resource when its Kubernetes object is deleted.
+type OnDeleteActionsConfig struct {
+	// Tags added to the cloud resource
+	AddTags []*TagConfig `mapstructure:"addTags"`
+	// Tag key added with the deletion time (RFC 3339) as value
+	TimestampTag string `mapstructure:"timestampTag"`
+	// Remove tags managed by rules
+	RemoveManagedTags bool `mapstructure:"removeManagedTags"`
+}
+
+// TagConfig Tag configuration.
+type TagConfig struct {
+	Key   string `mapstructure:"key"`
+	Value string `mapstructure:"value"`
+}
+
+// ComplianceConfig Compliance configuration.
+type ComplianceConfig struct {
+	RequiredTags []*RequiredTagConfig `mapstructure:"requiredTags"`
+}
+
+// RequiredTagConfig Tag required on resources.
+type RequiredTagConfig struct {
+	Key string `mapstructure:"key"`
+	// Regular expression that the whole value must match, any value when empty
+	Pattern string `mapstructure:"pattern"`
+	// Resource types where the tag is required, all types when empty
+	ResourceTypes []string `mapstructure:"resourceTypes"`
+}
+
+// ReverseSyncConfig Configuration of cloud tags copied to Kubernetes annotations.
+type ReverseSyncConfig struct {
+	// Prefix of annotation keys, DefaultReverseSyncAnnotationPrefix when empty
+	AnnotationPrefix string                   `mapstructure:"annotationPrefix"`
+	Rules            []*ReverseSyncRuleConfig `mapstructure:"rules"`
+}
+
+// ReverseSyncRuleConfig Reverse sync rule configuration.
+type ReverseSyncRuleConfig struct {
+	// Cloud tag key
+	Tag string `mapstructure:"tag"`
+	// Annotation name added after prefix, tag key when empty
+	Annotation string `mapstructure:"annotation"`
+	// Kubernetes object patched (persistentvolume, persistentvolumeclaim or service)
+	Target string `mapstructure:"target"`
+	// Behavior when annotation already exists with a different value (overwrite or skip)
+	Conflict string `mapstructure:"conflict"`
+}
+
+// WebhookConfig Admission webhook configuration.
+type WebhookConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	Address  string `mapstructure:"address"`
+	CertFile string `mapstructure:"certFile"`
+	KeyFile  string `mapstructure:"keyFile"`
+	// Action on objects missing labels required by rules (reject or warn)
+	MissingLabelsAction string `mapstructure:"missingLabelsAction"`
+}
+
+// LabelSelectorsConfig Label selectors used to filter watched objects.
+type LabelSelectorsConfig struct {
+	Services               string `mapstructure:"services"`
+	PersistentVolumes      string `mapstructure:"persistentVolumes"`
+	PersistentVolumeClaims string `mapstructure:"persistentVolumeClaims"`
 }
 
 // AWSConfig AWS Configuration.
@@ -46,6 +714,7 @@ type AWSConfig struct {
 
 // RuleConfig Rule Configuration.
 type RuleConfig struct {
+	Name   string             `mapstructure:"name"`
 	Tag    string             `mapstructure:"tag"`
 	Query  string             `mapstructure:"query"`
 	Value  string             `mapstructure:"value"`
@@ -60,29 +729,430 @@ type ConditionConfig struct {
 	Operator  string `mapstructure:"operator"`
 }
 
+// ValidationErrors All errors of an invalid configuration.
+type ValidationErrors []error
+
+// Error Get all errors messages.
+func (ve ValidationErrors) Error() string {
+	messages := make([]string, 0, len(ve))
+
+	for _, err := range ve {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Is Checks if one of the errors matches target.
+func (ve ValidationErrors) Is(target error) bool {
+	for _, err := range ve {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // IsValid Checks if the configuration is valid.
+// Several errors are returned together in ValidationErrors.
 func (cfg *Configuration) IsValid() error {
+	errs := cfg.Validate()
+
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return ValidationErrors(errs)
+	}
+}
+
+// Validate Get all configuration errors, empty when configuration is valid.
+func (cfg *Configuration) Validate() []error {
+	errs := make([]error, 0)
+
 	// Check if provider is empty
 	if cfg.Provider == "" {
-		return ErrNoProviderSelected
+		errs = append(errs, ErrNoProviderSelected)
+	} else if !funk.Contains(SupportedProviders, cfg.Provider) {
+		// Check if provider is supported
+		errs = append(errs, ErrProviderNotSupported)
+	}
+
+	// Check label selectors
+	if cfg.LabelSelectors != nil {
+		errs = append(errs, cfg.LabelSelectors.validate()...)
+	}
+
+	// Check webhook configuration
+	if cfg.Webhook != nil && cfg.Webhook.Enabled {
+		errs = append(errs, cfg.Webhook.validate()...)
+	}
+
+	// Check reverse sync configuration
+	if cfg.ReverseSync != nil {
+		errs = append(errs, cfg.ReverseSync.validate()...)
+	}
+
+	// Check compliance configuration
+	if cfg.Compliance != nil {
+		errs = append(errs, cfg.Compliance.validate()...)
 	}
 
-	// Check if provider is supported
-	if !funk.Contains(SupportedProviders, cfg.Provider) {
-		return ErrProviderNotSupported
+	// Check on delete configuration
+	if cfg.OnDelete != nil {
+		errs = append(errs, cfg.OnDelete.validate()...)
+	}
+
+	// Check sweep configuration
+	if cfg.Sweep.IsSweepEnabled() {
+		errs = append(errs, cfg.Sweep.validate()...)
+	}
+
+	// Check reconcile configuration
+	if cfg.Reconcile != nil {
+		errs = append(errs, cfg.Reconcile.validate()...)
+	}
+
+	// Check leader election configuration
+	if cfg.LeaderElection != nil {
+		errs = append(errs, cfg.LeaderElection.validate()...)
+	}
+
+	// Check audit configuration
+	if cfg.Audit.IsAuditEnabled() {
+		errs = append(errs, cfg.Audit.validate()...)
+	}
+
+	// Check tracing configuration
+	if cfg.Tracing.IsTracingEnabled() {
+		errs = append(errs, cfg.Tracing.validate()...)
 	}
 
 	// Check AWS configuration is ok if provider is aws
 	if cfg.Provider == AWSProviderName {
-		// Check that aws configuration block exists
 		if cfg.AWS == nil {
-			return ErrEmptyAWSConfiguration
+			// Check that aws configuration block exists
+			errs = append(errs, ErrEmptyAWSConfiguration)
+		} else if cfg.AWS.Region == "" {
+			// Check that region is set in AWS configuration block
+			errs = append(errs, ErrEmptyAWSRegionConfiguration)
 		}
-		// Check that region is set in AWS configuration block
-		if cfg.AWS.Region == "" {
-			return ErrEmptyAWSRegionConfiguration
+	}
+
+	return errs
+}
+
+func (lsc *LabelSelectorsConfig) validate() []error {
+	errs := make([]error, 0)
+
+	_, err := labels.Parse(lsc.Services)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("%w for services: %v", ErrInvalidLabelSelector, err))
+	}
+
+	_, err = labels.Parse(lsc.PersistentVolumes)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("%w for persistent volumes: %v", ErrInvalidLabelSelector, err))
+	}
+
+	_, err = labels.Parse(lsc.PersistentVolumeClaims)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("%w for persistent volume claims: %v", ErrInvalidLabelSelector, err))
+	}
+
+	return errs
+}
+
+func (wc *WebhookConfig) validate() []error {
+	errs := make([]error, 0)
+
+	if wc.CertFile == "" || wc.KeyFile == "" {
+		errs = append(errs, ErrEmptyWebhookTLSFiles)
+	}
+
+	// Empty action means default one
+	if wc.MissingLabelsAction != "" &&
+		wc.MissingLabelsAction != WebhookMissingLabelsActionReject &&
+		wc.MissingLabelsAction != WebhookMissingLabelsActionWarn {
+		errs = append(errs, ErrWebhookMissingLabelsActionNotSupported)
+	}
+
+	return errs
+}
+
+func (rsc *ReverseSyncConfig) validate() []error {
+	errs := make([]error, 0)
+
+	for i, rule := range rsc.Rules {
+		if rule == nil {
+			errs = append(errs, fmt.Errorf("reverseSync.rules[%d]: %w", i, ErrEmptyListEntry))
+
+			continue
+		}
+
+		if rule.Tag == "" {
+			errs = append(errs, fmt.Errorf("reverseSync.rules[%d]: %w", i, ErrReverseSyncEmptyTag))
+		}
+
+		if rule.Target != ReverseSyncTargetPersistentVolume &&
+			rule.Target != ReverseSyncTargetPersistentVolumeClaim &&
+			rule.Target != ReverseSyncTargetService {
+			errs = append(errs, fmt.Errorf("reverseSync.rules[%d]: %w", i, ErrReverseSyncTargetNotSupported))
 		}
+
+		// Empty conflict mode means default one
+		if rule.Conflict != "" && rule.Conflict != ReverseSyncConflictOverwrite && rule.Conflict != ReverseSyncConflictSkip {
+			errs = append(errs, fmt.Errorf("reverseSync.rules[%d]: %w", i, ErrReverseSyncConflictNotSupported))
+		}
+
+		annotationErrs := validation.IsQualifiedName(rsc.GetAnnotationKey(rule))
+		if len(annotationErrs) != 0 {
+			errs = append(errs, fmt.Errorf("reverseSync.rules[%d]: %w: %s", i, ErrReverseSyncInvalidAnnotation, strings.Join(annotationErrs, ", ")))
+		}
+	}
+
+	return errs
+}
+
+func (odc *OnDeleteConfig) validate() []error {
+	errs := odc.Volume.validate("volume")
+
+	if odc.Finalizer != nil {
+		timeout, err := odc.Finalizer.GetTimeout()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("onDelete.finalizer: %w: %v", ErrOnDeleteInvalidFinalizerTimeout, err))
+		} else if timeout <= 0 {
+			errs = append(errs, fmt.Errorf("onDelete.finalizer: %w: must be positive", ErrOnDeleteInvalidFinalizerTimeout))
+		}
+	}
+
+	return append(errs, odc.LoadBalancer.validate("loadBalancer")...)
+}
+
+func (odac *OnDeleteActionsConfig) validate(name string) []error {
+	errs := make([]error, 0)
+
+	if odac == nil {
+		return errs
+	}
+
+	for i, tag := range odac.AddTags {
+		if tag == nil {
+			errs = append(errs, fmt.Errorf("onDelete.%s.addTags[%d]: %w", name, i, ErrEmptyListEntry))
+
+			continue
+		}
+
+		if tag.Key == "" {
+			errs = append(errs, fmt.Errorf("onDelete.%s.addTags[%d]: %w", name, i, ErrOnDeleteEmptyTagKey))
+		}
+	}
+
+	return errs
+}
+
+func (lec *LeaderElectionConfig) validate() []error {
+	errs := make([]error, 0)
+
+	if !funk.ContainsString(SupportedLeaderElectionLockTypes, lec.GetLockType()) {
+		errs = append(errs, fmt.Errorf("leaderElection.lockType: %w: %s", ErrLeaderElectionLockTypeNotSupported, lec.LockType))
+	}
+
+	durations, err := lec.GetDurations()
+	if err != nil {
+		return append(errs, err)
+	}
+
+	// Same constraints as Kubernetes leader election
+	if durations.LeaseDuration <= durations.RenewDeadline {
+		errs = append(errs, fmt.Errorf("leaderElection: %w: leaseDuration must be greater than renewDeadline", ErrLeaderElectionInvalidDuration))
+	}
+
+	if durations.RenewDeadline <= durations.RetryPeriod {
+		errs = append(errs, fmt.Errorf("leaderElection: %w: renewDeadline must be greater than retryPeriod", ErrLeaderElectionInvalidDuration))
+	}
+
+	return errs
+}
+
+func (rc *ReconcileConfig) validate() []error {
+	errs := make([]error, 0)
+
+	if rc.Workers < 0 {
+		errs = append(errs, fmt.Errorf("reconcile.workers: %w", ErrReconcileInvalidWorkers))
+	}
+
+	_, err := rc.GetResyncPeriods()
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	if rc.SkipUnchanged != nil {
+		maxAge, maxAgeErr := rc.SkipUnchanged.GetMaxAge()
+		if maxAgeErr != nil {
+			errs = append(errs, fmt.Errorf("reconcile.skipUnchanged: %w: %v", ErrReconcileInvalidSkipUnchangedMaxAge, maxAgeErr))
+		} else if maxAge <= 0 {
+			errs = append(errs, fmt.Errorf("reconcile.skipUnchanged: %w: must be positive", ErrReconcileInvalidSkipUnchangedMaxAge))
+		}
+	}
+
+	return errs
+}
+
+func (sc *SweepConfig) validate() []error {
+	errs := make([]error, 0)
+
+	if sc.ClusterName == "" {
+		errs = append(errs, fmt.Errorf("sweep: %w", ErrSweepEmptyClusterName))
+	}
+
+	interval, err := sc.GetInterval()
+	if err != nil {
+		errs = append(errs, fmt.Errorf("sweep: %w: %v", ErrSweepInvalidInterval, err))
+	} else if interval <= 0 {
+		errs = append(errs, fmt.Errorf("sweep: %w: must be positive", ErrSweepInvalidInterval))
+	}
+
+	gracePeriod, err := sc.GetGracePeriod()
+	if err != nil {
+		errs = append(errs, fmt.Errorf("sweep: %w: %v", ErrSweepInvalidGracePeriod, err))
+	} else if gracePeriod < 0 {
+		errs = append(errs, fmt.Errorf("sweep: %w: mustn't be negative", ErrSweepInvalidGracePeriod))
+	}
+
+	for i, tag := range sc.AddTags {
+		if tag == nil {
+			errs = append(errs, fmt.Errorf("sweep.addTags[%d]: %w", i, ErrEmptyListEntry))
+
+			continue
+		}
+
+		if tag.Key == "" {
+			errs = append(errs, fmt.Errorf("sweep.addTags[%d]: %w", i, ErrSweepEmptyTagKey))
+		}
+	}
+
+	return errs
+}
+
+func (ac *AuditConfig) validate() []error {
+	errs := make([]error, 0)
+
+	if !funk.ContainsString(SupportedAuditSinks, ac.GetSink()) {
+		errs = append(errs, fmt.Errorf("audit.sink: %w: %s", ErrAuditSinkNotSupported, ac.Sink))
+	}
+
+	_, err := ac.GetRedactKeyRegexps()
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	switch ac.GetSink() {
+	case AuditSinkFile:
+		if ac.File == nil || ac.File.Path == "" {
+			errs = append(errs, fmt.Errorf("audit.file.path: %w", ErrAuditEmptyFilePath))
+		}
+
+		if ac.File != nil && (ac.File.MaxSize < 0 || ac.File.MaxBackups < 0) {
+			errs = append(errs, fmt.Errorf("audit.file: %w: maxSize and maxBackups mustn't be negative", ErrAuditInvalidFileRotation))
+		}
+	case AuditSinkWebhook:
+		if ac.Webhook == nil || ac.Webhook.URL == "" {
+			errs = append(errs, fmt.Errorf("audit.webhook.url: %w", ErrAuditEmptyWebhookURL))
+		}
+
+		if ac.Webhook != nil {
+			timeout, timeoutErr := ac.Webhook.GetTimeout()
+			if timeoutErr != nil {
+				errs = append(errs, fmt.Errorf("audit.webhook.timeout: %w: %v", ErrAuditInvalidWebhookTimeout, timeoutErr))
+			} else if timeout <= 0 {
+				errs = append(errs, fmt.Errorf("audit.webhook.timeout: %w: must be positive", ErrAuditInvalidWebhookTimeout))
+			}
+		}
+	}
+
+	return errs
+}
+
+func (tc *TracingConfig) validate() []error {
+	errs := make([]error, 0)
+
+	if !funk.ContainsString(SupportedTracingExporters, tc.GetExporter()) {
+		errs = append(errs, fmt.Errorf("tracing.exporter: %w: %s", ErrTracingExporterNotSupported, tc.Exporter))
+	}
+
+	if tc.SampleRatio < 0 || tc.SampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("tracing.sampleRatio: %w: %v", ErrTracingInvalidSampleRatio, tc.SampleRatio))
+	}
+
+	return errs
+}
+
+func (cc *ComplianceConfig) validate() []error {
+	errs := make([]error, 0)
+
+	for i, requiredTag := range cc.RequiredTags {
+		if requiredTag == nil {
+			errs = append(errs, fmt.Errorf("compliance.requiredTags[%d]: %w", i, ErrEmptyListEntry))
+
+			continue
+		}
+
+		if requiredTag.Key == "" {
+			errs = append(errs, fmt.Errorf("compliance.requiredTags[%d]: %w", i, ErrComplianceEmptyRequiredTagKey))
+		}
+
+		_, err := requiredTag.GetPatternRegexp()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("compliance.requiredTags[%d]: %w: %v", i, ErrComplianceInvalidPattern, err))
+		}
+	}
+
+	return errs
+}
+
+// GetPatternRegexp Get regular expression matching the whole value, nil when pattern is empty.
+func (rtc *RequiredTagConfig) GetPatternRegexp() (*regexp.Regexp, error) {
+	if rtc.Pattern == "" {
+		return nil, nil // nolint: nilnil // No pattern
+	}
+
+	return regexp.Compile("^(?:" + rtc.Pattern + ")$")
+}
+
+// GetAnnotationPrefix Get prefix of annotations set by reverse sync.
+func (rsc *ReverseSyncConfig) GetAnnotationPrefix() string {
+	if rsc.AnnotationPrefix == "" {
+		return DefaultReverseSyncAnnotationPrefix
+	}
+
+	return rsc.AnnotationPrefix
+}
+
+// GetAnnotationKey Get annotation key set by a reverse sync rule.
+func (rsc *ReverseSyncConfig) GetAnnotationKey(rule *ReverseSyncRuleConfig) string {
+	name := rule.Annotation
+	if name == "" {
+		name = rule.Tag
+	}
+
+	return rsc.GetAnnotationPrefix() + name
+}
+
+// IsNamespaceWatched Checks if a namespace is in the watch scope.
+func (cfg *Configuration) IsNamespaceWatched(namespace string) bool {
+	// Excluded namespaces always win
+	if funk.ContainsString(cfg.ExcludeNamespaces, namespace) {
+		return false
+	}
+
+	// No watch namespaces means all namespaces
+	if len(cfg.WatchNamespaces) == 0 {
+		return true
 	}
 
-	return nil
+	return funk.ContainsString(cfg.WatchNamespaces, namespace)
 }
@@ -0,0 +1,596 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestConfiguration_IsNamespaceWatched(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       *Configuration
+		namespace string
+		want      bool
+	}{
+		{"no filter", &Configuration{}, "default", true},
+		{"in watch namespaces", &Configuration{WatchNamespaces: []string{"default"}}, "default", true},
+		{"not in watch namespaces", &Configuration{WatchNamespaces: []string{"default"}}, "other", false},
+		{"excluded namespace", &Configuration{ExcludeNamespaces: []string{"default"}}, "default", false},
+		{
+			"excluded namespace wins over watch namespaces",
+			&Configuration{WatchNamespaces: []string{"default"}, ExcludeNamespaces: []string{"default"}},
+			"default",
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.IsNamespaceWatched(tt.namespace); got != tt.want {
+				t.Errorf("Configuration.IsNamespaceWatched() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfiguration_IsValid(t *testing.T) {
+	awsConfig := &AWSConfig{Region: "eu-west-1"}
+	tests := []struct {
+		name    string
+		cfg     *Configuration
+		wantErr error
+	}{
+		{"no provider", &Configuration{}, ErrNoProviderSelected},
+		{"provider not supported", &Configuration{Provider: "fake"}, ErrProviderNotSupported},
+		{"empty aws configuration", &Configuration{Provider: AWSProviderName}, ErrEmptyAWSConfiguration},
+		{"empty aws region", &Configuration{Provider: AWSProviderName, AWS: &AWSConfig{}}, ErrEmptyAWSRegionConfiguration},
+		{"valid", &Configuration{Provider: AWSProviderName, AWS: awsConfig}, nil},
+		{
+			"valid label selectors",
+			&Configuration{
+				Provider:       AWSProviderName,
+				AWS:            awsConfig,
+				LabelSelectors: &LabelSelectorsConfig{Services: "app=test", PersistentVolumeClaims: "team in (a,b)"},
+			},
+			nil,
+		},
+		{
+			"invalid label selector",
+			&Configuration{
+				Provider:       AWSProviderName,
+				AWS:            awsConfig,
+				LabelSelectors: &LabelSelectorsConfig{PersistentVolumes: "app in ("},
+			},
+			ErrInvalidLabelSelector,
+		},
+		{
+			"disabled webhook isn't checked",
+			&Configuration{Provider: AWSProviderName, AWS: awsConfig, Webhook: &WebhookConfig{}},
+			nil,
+		},
+		{
+			"webhook without tls files",
+			&Configuration{Provider: AWSProviderName, AWS: awsConfig, Webhook: &WebhookConfig{Enabled: true}},
+			ErrEmptyWebhookTLSFiles,
+		},
+		{
+			"webhook missing labels action not supported",
+			&Configuration{
+				Provider: AWSProviderName,
+				AWS:      awsConfig,
+				Webhook:  &WebhookConfig{Enabled: true, CertFile: "tls.crt", KeyFile: "tls.key", MissingLabelsAction: "fake"},
+			},
+			ErrWebhookMissingLabelsActionNotSupported,
+		},
+		{
+			"valid reverse sync",
+			&Configuration{
+				Provider: AWSProviderName,
+				AWS:      awsConfig,
+				ReverseSync: &ReverseSyncConfig{Rules: []*ReverseSyncRuleConfig{
+					{Tag: "CostCenter", Target: ReverseSyncTargetPersistentVolumeClaim},
+					{Tag: "aws:stack", Annotation: "stack", Target: ReverseSyncTargetService, Conflict: ReverseSyncConflictSkip},
+				}},
+			},
+			nil,
+		},
+		{
+			"reverse sync empty tag",
+			&Configuration{
+				Provider:    AWSProviderName,
+				AWS:         awsConfig,
+				ReverseSync: &ReverseSyncConfig{Rules: []*ReverseSyncRuleConfig{{Target: ReverseSyncTargetService}}},
+			},
+			ErrReverseSyncEmptyTag,
+		},
+		{
+			"reverse sync empty rule",
+			&Configuration{
+				Provider:    AWSProviderName,
+				AWS:         awsConfig,
+				ReverseSync: &ReverseSyncConfig{Rules: []*ReverseSyncRuleConfig{nil}},
+			},
+			ErrEmptyListEntry,
+		},
+		{
+			"reverse sync target not supported",
+			&Configuration{
+				Provider:    AWSProviderName,
+				AWS:         awsConfig,
+				ReverseSync: &ReverseSyncConfig{Rules: []*ReverseSyncRuleConfig{{Tag: "tag", Target: "fake"}}},
+			},
+			ErrReverseSyncTargetNotSupported,
+		},
+		{
+			"reverse sync conflict not supported",
+			&Configuration{
+				Provider: AWSProviderName,
+				AWS:      awsConfig,
+				ReverseSync: &ReverseSyncConfig{Rules: []*ReverseSyncRuleConfig{
+					{Tag: "tag", Target: ReverseSyncTargetService, Conflict: "fake"},
+				}},
+			},
+			ErrReverseSyncConflictNotSupported,
+		},
+		{
+			"reverse sync invalid annotation",
+			&Configuration{
+				Provider:    AWSProviderName,
+				AWS:         awsConfig,
+				ReverseSync: &ReverseSyncConfig{Rules: []*ReverseSyncRuleConfig{{Tag: "aws:stack", Target: ReverseSyncTargetService}}},
+			},
+			ErrReverseSyncInvalidAnnotation,
+		},
+		{
+			"valid compliance",
+			&Configuration{
+				Provider: AWSProviderName,
+				AWS:      awsConfig,
+				Compliance: &ComplianceConfig{RequiredTags: []*RequiredTagConfig{
+					{Key: "CostCenter", Pattern: "[0-9]{4}", ResourceTypes: []string{"volume"}},
+				}},
+			},
+			nil,
+		},
+		{
+			"compliance empty key",
+			&Configuration{
+				Provider:   AWSProviderName,
+				AWS:        awsConfig,
+				Compliance: &ComplianceConfig{RequiredTags: []*RequiredTagConfig{{}}},
+			},
+			ErrComplianceEmptyRequiredTagKey,
+		},
+		{
+			"compliance empty required tag",
+			&Configuration{
+				Provider:   AWSProviderName,
+				AWS:        awsConfig,
+				Compliance: &ComplianceConfig{RequiredTags: []*RequiredTagConfig{nil}},
+			},
+			ErrEmptyListEntry,
+		},
+		{
+			"compliance invalid pattern",
+			&Configuration{
+				Provider:   AWSProviderName,
+				AWS:        awsConfig,
+				Compliance: &ComplianceConfig{RequiredTags: []*RequiredTagConfig{{Key: "CostCenter", Pattern: "[0-9"}}},
+			},
+			ErrComplianceInvalidPattern,
+		},
+		{
+			"valid on delete",
+			&Configuration{
+				Provider: AWSProviderName,
+				AWS:      awsConfig,
+				OnDelete: &OnDeleteConfig{Volume: &OnDeleteActionsConfig{
+					AddTags:           []*TagConfig{{Key: "orphaned", Value: "true"}},
+					TimestampTag:      "kubernetes-tagger/orphaned-at",
+					RemoveManagedTags: true,
+				}},
+			},
+			nil,
+		},
+		{
+			"on delete empty tag key",
+			&Configuration{
+				Provider: AWSProviderName,
+				AWS:      awsConfig,
+				OnDelete: &OnDeleteConfig{LoadBalancer: &OnDeleteActionsConfig{AddTags: []*TagConfig{{Value: "true"}}}},
+			},
+			ErrOnDeleteEmptyTagKey,
+		},
+		{
+			"on delete empty tag",
+			&Configuration{
+				Provider: AWSProviderName,
+				AWS:      awsConfig,
+				OnDelete: &OnDeleteConfig{Volume: &OnDeleteActionsConfig{AddTags: []*TagConfig{nil}}},
+			},
+			ErrEmptyListEntry,
+		},
+		{
+			"valid on delete finalizer",
+			&Configuration{
+				Provider: AWSProviderName,
+				AWS:      awsConfig,
+				OnDelete: &OnDeleteConfig{Finalizer: &OnDeleteFinalizerConfig{Enabled: true, Timeout: "1h"}},
+			},
+			nil,
+		},
+		{
+			"on delete finalizer invalid timeout",
+			&Configuration{
+				Provider: AWSProviderName,
+				AWS:      awsConfig,
+				OnDelete: &OnDeleteConfig{Finalizer: &OnDeleteFinalizerConfig{Enabled: true, Timeout: "1 hour"}},
+			},
+			ErrOnDeleteInvalidFinalizerTimeout,
+		},
+		{
+			"on delete finalizer negative timeout",
+			&Configuration{
+				Provider: AWSProviderName,
+				AWS:      awsConfig,
+				OnDelete: &OnDeleteConfig{Finalizer: &OnDeleteFinalizerConfig{Enabled: true, Timeout: "-1m"}},
+			},
+			ErrOnDeleteInvalidFinalizerTimeout,
+		},
+		{
+			"valid sweep",
+			&Configuration{
+				Provider: AWSProviderName,
+				AWS:      awsConfig,
+				Sweep:    &SweepConfig{Enabled: true, ClusterName: "prod", Interval: "30m", AddTags: []*TagConfig{{Key: "orphan", Value: "true"}}},
+			},
+			nil,
+		},
+		{
+			"disabled sweep not checked",
+			&Configuration{
+				Provider: AWSProviderName,
+				AWS:      awsConfig,
+				Sweep:    &SweepConfig{Interval: "1 hour"},
+			},
+			nil,
+		},
+		{
+			"sweep empty cluster name",
+			&Configuration{
+				Provider: AWSProviderName,
+				AWS:      awsConfig,
+				Sweep:    &SweepConfig{Enabled: true},
+			},
+			ErrSweepEmptyClusterName,
+		},
+		{
+			"sweep invalid interval",
+			&Configuration{
+				Provider: AWSProviderName,
+				AWS:      awsConfig,
+				Sweep:    &SweepConfig{Enabled: true, ClusterName: "prod", Interval: "0s"},
+			},
+			ErrSweepInvalidInterval,
+		},
+		{
+			"sweep negative grace period",
+			&Configuration{
+				Provider: AWSProviderName,
+				AWS:      awsConfig,
+				Sweep:    &SweepConfig{Enabled: true, ClusterName: "prod", GracePeriod: "-1m"},
+			},
+			ErrSweepInvalidGracePeriod,
+		},
+		{
+			"sweep empty tag key",
+			&Configuration{
+				Provider: AWSProviderName,
+				AWS:      awsConfig,
+				Sweep:    &SweepConfig{Enabled: true, ClusterName: "prod", AddTags: []*TagConfig{{Value: "true"}}},
+			},
+			ErrSweepEmptyTagKey,
+		},
+		{
+			"sweep empty tag",
+			&Configuration{
+				Provider: AWSProviderName,
+				AWS:      awsConfig,
+				Sweep:    &SweepConfig{Enabled: true, ClusterName: "prod", AddTags: []*TagConfig{nil}},
+			},
+			ErrEmptyListEntry,
+		},
+		{
+			"valid reconcile",
+			&Configuration{
+				Provider: AWSProviderName,
+				AWS:      awsConfig,
+				Reconcile: &ReconcileConfig{
+					Workers:       4,
+					Resync:        &ResyncConfig{PersistentVolumes: "10m", Services: "0"},
+					SkipUnchanged: &SkipUnchangedConfig{Enabled: true, MaxAge: "2h"},
+				},
+			},
+			nil,
+		},
+		{
+			"reconcile negative workers",
+			&Configuration{
+				Provider:  AWSProviderName,
+				AWS:       awsConfig,
+				Reconcile: &ReconcileConfig{Workers: -1},
+			},
+			ErrReconcileInvalidWorkers,
+		},
+		{
+			"reconcile invalid resync period",
+			&Configuration{
+				Provider:  AWSProviderName,
+				AWS:       awsConfig,
+				Reconcile: &ReconcileConfig{Resync: &ResyncConfig{RelatedObjects: "-1m"}},
+			},
+			ErrReconcileInvalidResyncPeriod,
+		},
+		{
+			"reconcile invalid skip unchanged max age",
+			&Configuration{
+				Provider:  AWSProviderName,
+				AWS:       awsConfig,
+				Reconcile: &ReconcileConfig{SkipUnchanged: &SkipUnchangedConfig{Enabled: true, MaxAge: "1 hour"}},
+			},
+			ErrReconcileInvalidSkipUnchangedMaxAge,
+		},
+		{
+			"valid leader election",
+			&Configuration{
+				Provider:       AWSProviderName,
+				AWS:            awsConfig,
+				LeaderElection: &LeaderElectionConfig{LockType: "endpointsleases", LeaseDuration: "30s"},
+			},
+			nil,
+		},
+		{
+			"leader election deprecated lock type",
+			&Configuration{
+				Provider:       AWSProviderName,
+				AWS:            awsConfig,
+				LeaderElection: &LeaderElectionConfig{LockType: "endpoints"},
+			},
+			ErrLeaderElectionLockTypeNotSupported,
+		},
+		{
+			"leader election invalid duration",
+			&Configuration{
+				Provider:       AWSProviderName,
+				AWS:            awsConfig,
+				LeaderElection: &LeaderElectionConfig{RetryPeriod: "two seconds"},
+			},
+			ErrLeaderElectionInvalidDuration,
+		},
+		{
+			"leader election renew deadline greater than lease duration",
+			&Configuration{
+				Provider:       AWSProviderName,
+				AWS:            awsConfig,
+				LeaderElection: &LeaderElectionConfig{RenewDeadline: "20s"},
+			},
+			ErrLeaderElectionInvalidDuration,
+		},
+		{
+			"valid webhook",
+			&Configuration{
+				Provider: AWSProviderName,
+				AWS:      awsConfig,
+				Webhook:  &WebhookConfig{Enabled: true, CertFile: "tls.crt", KeyFile: "tls.key", MissingLabelsAction: "reject"},
+			},
+			nil,
+		},
+		{
+			"valid audit",
+			&Configuration{
+				Provider: AWSProviderName,
+				AWS:      awsConfig,
+				Audit:    &AuditConfig{Enabled: true, RedactKeyPatterns: []string{"(?i)secret"}},
+			},
+			nil,
+		},
+		{
+			"disabled audit isn't validated",
+			&Configuration{Provider: AWSProviderName, AWS: awsConfig, Audit: &AuditConfig{Sink: "syslog"}},
+			nil,
+		},
+		{
+			"audit sink not supported",
+			&Configuration{Provider: AWSProviderName, AWS: awsConfig, Audit: &AuditConfig{Enabled: true, Sink: "syslog"}},
+			ErrAuditSinkNotSupported,
+		},
+		{
+			"audit invalid redact pattern",
+			&Configuration{Provider: AWSProviderName, AWS: awsConfig, Audit: &AuditConfig{Enabled: true, RedactKeyPatterns: []string{"("}}},
+			ErrAuditInvalidRedactPattern,
+		},
+		{
+			"audit file empty path",
+			&Configuration{Provider: AWSProviderName, AWS: awsConfig, Audit: &AuditConfig{Enabled: true, Sink: AuditSinkFile}},
+			ErrAuditEmptyFilePath,
+		},
+		{
+			"audit file invalid rotation",
+			&Configuration{
+				Provider: AWSProviderName,
+				AWS:      awsConfig,
+				Audit:    &AuditConfig{Enabled: true, Sink: AuditSinkFile, File: &AuditFileConfig{Path: "audit.log", MaxSize: -1}},
+			},
+			ErrAuditInvalidFileRotation,
+		},
+		{
+			"audit webhook empty url",
+			&Configuration{Provider: AWSProviderName, AWS: awsConfig, Audit: &AuditConfig{Enabled: true, Sink: AuditSinkWebhook}},
+			ErrAuditEmptyWebhookURL,
+		},
+		{
+			"audit webhook invalid timeout",
+			&Configuration{
+				Provider: AWSProviderName,
+				AWS:      awsConfig,
+				Audit: &AuditConfig{
+					Enabled: true,
+					Sink:    AuditSinkWebhook,
+					Webhook: &AuditWebhookConfig{URL: "http://localhost:8080", Timeout: "0s"},
+				},
+			},
+			ErrAuditInvalidWebhookTimeout,
+		},
+		{
+			"valid tracing",
+			&Configuration{
+				Provider: AWSProviderName,
+				AWS:      awsConfig,
+				Tracing:  &TracingConfig{Enabled: true, Exporter: TracingExporterStdout, SampleRatio: 0.5},
+			},
+			nil,
+		},
+		{
+			"disabled tracing isn't checked",
+			&Configuration{Provider: AWSProviderName, AWS: awsConfig, Tracing: &TracingConfig{Exporter: "zipkin"}},
+			nil,
+		},
+		{
+			"tracing exporter not supported",
+			&Configuration{Provider: AWSProviderName, AWS: awsConfig, Tracing: &TracingConfig{Enabled: true, Exporter: "zipkin"}},
+			ErrTracingExporterNotSupported,
+		},
+		{
+			"tracing invalid sample ratio",
+			&Configuration{Provider: AWSProviderName, AWS: awsConfig, Tracing: &TracingConfig{Enabled: true, SampleRatio: 1.5}},
+			ErrTracingInvalidSampleRatio,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.IsValid()
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Configuration.IsValid() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfiguration_IsValid_AllErrors(t *testing.T) {
+	cfg := &Configuration{
+		Provider:   AWSProviderName,
+		AWS:        &AWSConfig{},
+		Reconcile:  &ReconcileConfig{Workers: -1},
+		Tracing:    &TracingConfig{Enabled: true, SampleRatio: 1.5},
+		Compliance: &ComplianceConfig{RequiredTags: []*RequiredTagConfig{{Key: ""}}},
+	}
+
+	errs := cfg.Validate()
+	if len(errs) != 4 {
+		t.Errorf("Configuration.Validate() errors = %v, want 4 errors", errs)
+	}
+
+	err := cfg.IsValid()
+	for _, wantErr := range []error{
+		ErrEmptyAWSRegionConfiguration,
+		ErrReconcileInvalidWorkers,
+		ErrTracingInvalidSampleRatio,
+		ErrComplianceEmptyRequiredTagKey,
+	} {
+		if !errors.Is(err, wantErr) {
+			t.Errorf("Configuration.IsValid() error = %v, want to contain %v", err, wantErr)
+		}
+	}
+}
+
+func TestReconcileConfig_GetResyncPeriods(t *testing.T) {
+	tests := []struct {
+		name string
+		rc   *ReconcileConfig
+		want *ResyncPeriods
+	}{
+		{
+			"nil configuration",
+			nil,
+			&ResyncPeriods{DefaultResyncPeriod, DefaultResyncPeriod, DefaultResyncPeriod, DefaultResyncPeriod},
+		},
+		{
+			"partial configuration",
+			&ReconcileConfig{Resync: &ResyncConfig{PersistentVolumes: "0", TaggingPolicies: "5m"}},
+			&ResyncPeriods{0, DefaultResyncPeriod, DefaultResyncPeriod, 5 * time.Minute},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.rc.GetResyncPeriods()
+			if err != nil {
+				t.Errorf("ReconcileConfig.GetResyncPeriods() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReconcileConfig.GetResyncPeriods() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLeaderElectionConfig_Getters(t *testing.T) {
+	var lec *LeaderElectionConfig
+
+	if !lec.IsLeaderElectionEnabled() || lec.GetLockType() != DefaultLeaderElectionLockType ||
+		lec.GetLockName() != DefaultLeaderElectionLockName || lec.GetLockNamespace("kube-system") != "kube-system" {
+		t.Errorf("LeaderElectionConfig getters must return defaults on nil configuration")
+	}
+
+	// Enabled isn't set when only lock is configured
+	lec = &LeaderElectionConfig{LockName: "tagger", LockNamespace: "leases", LeaseDuration: "1m"}
+	if !lec.IsLeaderElectionEnabled() || lec.GetLockName() != "tagger" || lec.GetLockNamespace("kube-system") != "leases" {
+		t.Errorf("LeaderElectionConfig getters must return configured values")
+	}
+
+	enabled := false
+	if (&LeaderElectionConfig{Enabled: &enabled}).IsLeaderElectionEnabled() {
+		t.Errorf("LeaderElectionConfig.IsLeaderElectionEnabled() must be false when disabled")
+	}
+
+	durations, err := lec.GetDurations()
+	want := &LeaderElectionDurations{time.Minute, DefaultLeaderElectionRenewDeadline, DefaultLeaderElectionRetryPeriod}
+
+	if err != nil || !reflect.DeepEqual(durations, want) {
+		t.Errorf("LeaderElectionConfig.GetDurations() = %v, %v, want %v", durations, err, want)
+	}
+}
+
+func TestAuditConfig_Getters(t *testing.T) {
+	ac := &AuditConfig{File: &AuditFileConfig{}, Webhook: &AuditWebhookConfig{}}
+
+	timeout, err := ac.Webhook.GetTimeout()
+	if ac.GetSink() != AuditSinkStdout || ac.File.GetMaxSize() != DefaultAuditFileMaxSize ||
+		ac.File.GetMaxBackups() != DefaultAuditFileMaxBackups || timeout != DefaultAuditWebhookTimeout || err != nil {
+		t.Errorf("AuditConfig getters must return defaults on empty configuration")
+	}
+
+	ac = &AuditConfig{
+		Sink:    AuditSinkFile,
+		File:    &AuditFileConfig{MaxSize: 10, MaxBackups: 2},
+		Webhook: &AuditWebhookConfig{Timeout: "1s"},
+	}
+
+	timeout, err = ac.Webhook.GetTimeout()
+	if ac.GetSink() != AuditSinkFile || ac.File.GetMaxSize() != 10 || ac.File.GetMaxBackups() != 2 || timeout != time.Second || err != nil {
+		t.Errorf("AuditConfig getters must return configured values")
+	}
+}
+
+func TestTracingConfig_Getters(t *testing.T) {
+	tc := &TracingConfig{}
+	if tc.GetExporter() != TracingExporterOTLP || tc.GetServiceName() != DefaultTracingServiceName || tc.GetSampleRatio() != 1 {
+		t.Errorf("TracingConfig getters must return defaults on empty configuration")
+	}
+
+	tc = &TracingConfig{Exporter: TracingExporterStdout, ServiceName: "tagger", SampleRatio: 0.1}
+	if tc.GetExporter() != TracingExporterStdout || tc.GetServiceName() != "tagger" || tc.GetSampleRatio() != 0.1 {
+		t.Errorf("TracingConfig getters must return configured values")
+	}
+}
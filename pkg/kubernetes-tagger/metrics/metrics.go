@@ -0,0 +1,138 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Namespace Prometheus metrics namespace.
+const Namespace = "kubernetes_tagger"
+
+// SuccessResult Success result label value.
+const SuccessResult = "success"
+
+// FailureResult Failure result label value.
+const FailureResult = "failure"
+
+// IgnoredResult Object ignored because not in watch scope result label value.
+const IgnoredResult = "ignored"
+
+// UnsupportedResult Object not supported as resource result label value.
+const UnsupportedResult = "unsupported"
+
+// UnchangedResult Object skipped because unchanged since last successful reconcile result label value.
+const UnchangedResult = "unchanged"
+
+// ConfigurationReloads Configuration reloads counter by result.
+var ConfigurationReloads = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "configuration_reloads_total",
+		Help:      "Number of configuration reloads by result",
+	},
+	[]string{"result"},
+)
+
+// ObjectsProcessed Kubernetes objects processed counter by kind and result.
+var ObjectsProcessed = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "objects_processed_total",
+		Help:      "Number of Kubernetes objects processed by kind and result",
+	},
+	[]string{"kind", "result"},
+)
+
+// TagChanges Tags added or deleted counter by action and key.
+var TagChanges = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "tag_changes_total",
+		Help:      "Number of tags added or deleted on resources by action and key",
+	},
+	[]string{"action", "key"},
+)
+
+// ResourceRunDuration Resource run duration histogram by type and platform.
+var ResourceRunDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "resource_run_duration_seconds",
+		Help:      "Duration of tags management run on a resource by type and platform",
+		Buckets:   prometheus.DefBuckets,
+	},
+	[]string{"type", "platform"},
+)
+
+// ProviderRequests Provider API requests counter by service, operation and error code.
+var ProviderRequests = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "provider_requests_total",
+		Help:      "Number of provider API requests by service, operation and error code",
+	},
+	[]string{"service", "operation", "code"},
+)
+
+// ProviderRequestDuration Provider API requests duration histogram by service and operation.
+var ProviderRequestDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "provider_request_duration_seconds",
+		Help:      "Duration of provider API requests by service and operation",
+		Buckets:   prometheus.DefBuckets,
+	},
+	[]string{"service", "operation"},
+)
+
+// RuleMatches Rule matches counter by rule name or index.
+var RuleMatches = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "rule_matches_total",
+		Help:      "Number of times rule conditions matched a resource by rule name or index",
+	},
+	[]string{"rule"},
+)
+
+// ResourceCompliance Resource compliance with required tags gauge by reference, type and platform.
+var ResourceCompliance = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "resource_compliant",
+		Help:      "Whether resource has all required tags with allowed values (1) or not (0) by reference, type and platform",
+	},
+	[]string{"reference", "type", "platform"},
+)
+
+// OrphanResources Cloud resources having the cluster tag without Kubernetes object gauge by type and platform.
+var OrphanResources = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "orphan_resources",
+		Help:      "Number of cloud resources having the cluster tag without Kubernetes object found by last sweep by type and platform",
+	},
+	[]string{"type", "platform"},
+)
+
+// AuditRecords Audit records written counter by result.
+var AuditRecords = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "audit_records_total",
+		Help:      "Number of audit records of tag mutations written by result",
+	},
+	[]string{"result"},
+)
+
+func init() {
+	prometheus.MustRegister(
+		ConfigurationReloads,
+		ObjectsProcessed,
+		TagChanges,
+		ResourceRunDuration,
+		ProviderRequests,
+		ProviderRequestDuration,
+		RuleMatches,
+		ResourceCompliance,
+		OrphanResources,
+		AuditRecords,
+	)
+}
@@ -0,0 +1,175 @@
+package policies
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/rules"
+	"github.com/thoas/go-funk"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ErrNamespacedDeleteRule Delete rule in a namespace tagging policy error.
+var ErrNamespacedDeleteRule = errors.New("delete action not allowed in namespace tagging policies")
+
+// FromUnstructured Create policy from unstructured object got from dynamic client.
+func FromUnstructured(obj *unstructured.Unstructured) (*Policy, error) {
+	var policy Policy
+
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), &policy)
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert %s %s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+
+	return &policy, nil
+}
+
+// ToUnstructured Transform policy to unstructured object for dynamic client.
+func (p *Policy) ToUnstructured() (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return &unstructured.Unstructured{Object: content}, nil
+}
+
+// IsNamespaced Checks if the policy is a NamespaceTaggingPolicy.
+func (p *Policy) IsNamespaced() bool {
+	return p.Kind == NamespaceTaggingPolicyKind
+}
+
+// Key Unique key of the policy.
+func (p *Policy) Key() string {
+	if p.IsNamespaced() {
+		return p.Kind + "/" + p.Namespace + "/" + p.Name
+	}
+
+	return p.Kind + "/" + p.Name
+}
+
+// AppliesTo Checks if the policy applies to a resource type in a namespace.
+// Namespace is the one of the Kubernetes object linked to the resource.
+func (p *Policy) AppliesTo(resourceType, namespace string) bool {
+	if p.IsNamespaced() && p.Namespace != namespace {
+		return false
+	}
+
+	return len(p.Spec.ResourceTypes) == 0 || funk.ContainsString(p.Spec.ResourceTypes, resourceType)
+}
+
+// RuleConfigs Transform policy rules to rule configurations.
+func (p *Policy) RuleConfigs() []*config.RuleConfig {
+	ruleConfigs := make([]*config.RuleConfig, 0)
+
+	for _, ruleSpec := range p.Spec.Rules {
+		if ruleSpec == nil {
+			continue
+		}
+
+		ruleConfig := &config.RuleConfig{
+			Name:   ruleSpec.Name,
+			Tag:    ruleSpec.Tag,
+			Query:  ruleSpec.Query,
+			Value:  ruleSpec.Value,
+			Action: ruleSpec.Action,
+			When:   make([]*config.ConditionConfig, 0),
+		}
+
+		for _, conditionSpec := range ruleSpec.When {
+			if conditionSpec == nil {
+				continue
+			}
+
+			ruleConfig.When = append(ruleConfig.When, &config.ConditionConfig{
+				Condition: conditionSpec.Condition,
+				Value:     conditionSpec.Value,
+				Operator:  conditionSpec.Operator,
+			})
+		}
+
+		ruleConfigs = append(ruleConfigs, ruleConfig)
+	}
+
+	return ruleConfigs
+}
+
+// Validate Validate policy rules and return all errors found with path in the policy object.
+func (p *Policy) Validate() []string {
+	errs := make([]string, 0)
+
+	for _, validationError := range rules.Validate(p.RuleConfigs()) {
+		errs = append(errs, "spec."+validationError.Error())
+	}
+
+	// Namespace tenants can only add or update tags, they mustn't remove tags owned by the cluster
+	if p.IsNamespaced() {
+		for i, ruleSpec := range p.Spec.Rules {
+			if ruleSpec != nil && ruleSpec.Action == string(rules.RuleActionDelete) {
+				errs = append(errs, fmt.Sprintf("spec.rules[%d].action: %v", i, ErrNamespacedDeleteRule))
+			}
+		}
+	}
+
+	return errs
+}
+
+// Rules Create rules from policy with validation.
+func (p *Policy) Rules() ([]*rules.Rule, error) {
+	return rules.New(p.RuleConfigs())
+}
+
+// NewStatus Create policy status from validation errors and matching information.
+// Condition transition time is kept when the condition doesn't change.
+func (p *Policy) NewStatus(errs []string, matchedResources int, lastReconcileTime *metav1.Time) Status {
+	status := Status{
+		ObservedGeneration: p.Generation,
+		Conditions:         make([]metav1.Condition, len(p.Status.Conditions)),
+		MatchedResources:   matchedResources,
+		LastReconcileTime:  lastReconcileTime,
+	}
+	copy(status.Conditions, p.Status.Conditions)
+
+	condition := metav1.Condition{
+		Type:               ValidConditionType,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: p.Generation,
+		Reason:             RulesValidReason,
+		Message:            "All rules are valid",
+	}
+
+	if len(errs) != 0 {
+		status.Errors = errs
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = RulesInvalidReason
+		condition.Message = strings.Join(errs, ", ")
+	}
+
+	meta.SetStatusCondition(&status.Conditions, condition)
+
+	return status
+}
+
+// Sort Sort policies in evaluation order.
+// TaggingPolicies are evaluated first sorted by name,
+// then NamespaceTaggingPolicies sorted by namespace and name.
+func Sort(policiesList []*Policy) {
+	sort.SliceStable(policiesList, func(i, j int) bool {
+		pi, pj := policiesList[i], policiesList[j]
+		if pi.IsNamespaced() != pj.IsNamespaced() {
+			return !pi.IsNamespaced()
+		}
+
+		if pi.Namespace != pj.Namespace {
+			return pi.Namespace < pj.Namespace
+		}
+
+		return pi.Name < pj.Name
+	})
+}
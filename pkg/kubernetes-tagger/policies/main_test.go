@@ -0,0 +1,149 @@
+package policies
+
+import (
+	"testing"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestFromUnstructured(t *testing.T) {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": Group + "/" + Version,
+		"kind":       NamespaceTaggingPolicyKind,
+		"metadata":   map[string]interface{}{"name": "policy", "namespace": "team-a"},
+		"spec": map[string]interface{}{
+			"resourceTypes": []interface{}{"volume"},
+			"rules": []interface{}{
+				map[string]interface{}{
+					"tag":    "team",
+					"value":  "a",
+					"action": "add",
+					"when": []interface{}{
+						map[string]interface{}{"condition": "type", "value": "volume", "operator": "Equal"},
+					},
+				},
+			},
+		},
+	}}
+
+	policy, err := FromUnstructured(u)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "NamespaceTaggingPolicy/team-a/policy", policy.Key())
+	assert.True(t, policy.IsNamespaced())
+	assert.Equal(t, []*config.RuleConfig{{
+		Tag:    "team",
+		Value:  "a",
+		Action: "add",
+		When:   []*config.ConditionConfig{{Condition: "type", Value: "volume", Operator: "Equal"}},
+	}}, policy.RuleConfigs())
+
+	// Back to unstructured
+	u, err = policy.ToUnstructured()
+
+	assert.Nil(t, err)
+	assert.Equal(t, "policy", u.GetName())
+	assert.Equal(t, NamespaceTaggingPolicyKind, u.GetKind())
+}
+
+func TestPolicy_AppliesTo(t *testing.T) {
+	clusterPolicy := &Policy{TypeMeta: metav1.TypeMeta{Kind: TaggingPolicyKind}}
+	namespacePolicy := &Policy{
+		TypeMeta:   metav1.TypeMeta{Kind: NamespaceTaggingPolicyKind},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a"},
+		Spec:       Spec{ResourceTypes: []string{"volume"}},
+	}
+
+	assert.True(t, clusterPolicy.AppliesTo("volume", ""))
+	assert.True(t, clusterPolicy.AppliesTo("loadbalancer", "team-b"))
+	assert.True(t, namespacePolicy.AppliesTo("volume", "team-a"))
+	assert.False(t, namespacePolicy.AppliesTo("volume", "team-b"))
+	assert.False(t, namespacePolicy.AppliesTo("loadbalancer", "team-a"))
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	policy := &Policy{Spec: Spec{Rules: []*RuleSpec{
+		{Tag: "valid", Value: "value", Action: "add"},
+		{Tag: "", Action: "fake", When: []*ConditionSpec{{Condition: "type", Operator: "fake"}}},
+	}}}
+
+	errs := policy.Validate()
+
+	assert.Equal(t, []string{
+		"spec.rules[1].action: rule action not supported",
+		"spec.rules[1].tag: tag rule mustn't be empty",
+		"spec.rules[1].when[0].operator: condition operator not supported",
+	}, errs)
+
+	_, err := policy.Rules()
+	assert.NotNil(t, err)
+}
+
+func TestPolicy_Validate_NamespacedDeleteRule(t *testing.T) {
+	rulesSpec := []*RuleSpec{
+		{Tag: "owner", Value: "team-a", Action: "add"},
+		{Tag: "kubernetes-cluster", Action: "delete"},
+	}
+
+	policy := &Policy{TypeMeta: metav1.TypeMeta{Kind: NamespaceTaggingPolicyKind}, Spec: Spec{Rules: rulesSpec}}
+	assert.Equal(t, []string{"spec.rules[1].action: delete action not allowed in namespace tagging policies"}, policy.Validate())
+
+	// Cluster scoped policies can delete tags
+	policy = &Policy{TypeMeta: metav1.TypeMeta{Kind: TaggingPolicyKind}, Spec: Spec{Rules: rulesSpec}}
+	assert.Empty(t, policy.Validate())
+}
+
+func TestPolicy_NewStatus(t *testing.T) {
+	policy := &Policy{ObjectMeta: metav1.ObjectMeta{Generation: 2}}
+	now := metav1.Now()
+
+	status := policy.NewStatus(nil, 3, &now)
+
+	assert.Equal(t, int64(2), status.ObservedGeneration)
+	assert.Equal(t, 3, status.MatchedResources)
+	assert.Equal(t, &now, status.LastReconcileTime)
+	assert.Len(t, status.Conditions, 1)
+	assert.Equal(t, metav1.ConditionTrue, status.Conditions[0].Status)
+	assert.Equal(t, RulesValidReason, status.Conditions[0].Reason)
+	assert.Nil(t, status.Errors)
+
+	// Transition time is kept when condition doesn't change
+	policy.Status = status
+	sameStatus := policy.NewStatus(nil, 3, &now)
+	assert.Equal(t, status.Conditions[0].LastTransitionTime, sameStatus.Conditions[0].LastTransitionTime)
+
+	// Invalid rules
+	status = policy.NewStatus([]string{"error1", "error2"}, 0, nil)
+
+	assert.Len(t, status.Conditions, 1)
+	assert.Equal(t, metav1.ConditionFalse, status.Conditions[0].Status)
+	assert.Equal(t, RulesInvalidReason, status.Conditions[0].Reason)
+	assert.Equal(t, "error1, error2", status.Conditions[0].Message)
+	assert.Equal(t, []string{"error1", "error2"}, status.Errors)
+}
+
+func TestSort(t *testing.T) {
+	policiesList := []*Policy{
+		{TypeMeta: metav1.TypeMeta{Kind: NamespaceTaggingPolicyKind}, ObjectMeta: metav1.ObjectMeta{Namespace: "b", Name: "a"}},
+		{TypeMeta: metav1.TypeMeta{Kind: NamespaceTaggingPolicyKind}, ObjectMeta: metav1.ObjectMeta{Namespace: "a", Name: "b"}},
+		{TypeMeta: metav1.TypeMeta{Kind: TaggingPolicyKind}, ObjectMeta: metav1.ObjectMeta{Name: "z"}},
+		{TypeMeta: metav1.TypeMeta{Kind: TaggingPolicyKind}, ObjectMeta: metav1.ObjectMeta{Name: "a"}},
+	}
+
+	Sort(policiesList)
+
+	keys := make([]string, 0)
+	for _, policy := range policiesList {
+		keys = append(keys, policy.Key())
+	}
+
+	assert.Equal(t, []string{
+		"TaggingPolicy/a",
+		"TaggingPolicy/z",
+		"NamespaceTaggingPolicy/a/b",
+		"NamespaceTaggingPolicy/b/a",
+	}, keys)
+}
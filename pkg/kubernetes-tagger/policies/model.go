@@ -0,0 +1,81 @@
+package policies
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Group API group of tagging policies.
+const Group = "kubernetes-tagger.oxyno-zeta.com"
+
+// Version API version of tagging policies.
+const Version = "v1alpha1"
+
+// TaggingPolicyKind Cluster scoped tagging policy kind.
+const TaggingPolicyKind = "TaggingPolicy"
+
+// NamespaceTaggingPolicyKind Namespaced tagging policy kind.
+const NamespaceTaggingPolicyKind = "NamespaceTaggingPolicy"
+
+// TaggingPolicyResource Cluster scoped tagging policy resource.
+var TaggingPolicyResource = schema.GroupVersionResource{Group: Group, Version: Version, Resource: "taggingpolicies"}
+
+// NamespaceTaggingPolicyResource Namespaced tagging policy resource.
+var NamespaceTaggingPolicyResource = schema.GroupVersionResource{
+	Group:    Group,
+	Version:  Version,
+	Resource: "namespacetaggingpolicies",
+}
+
+// ValidConditionType Status condition type telling if policy rules are valid.
+const ValidConditionType = "Valid"
+
+// Status condition reasons.
+const (
+	RulesValidReason   = "RulesValid"
+	RulesInvalidReason = "RulesInvalid"
+)
+
+// Policy TaggingPolicy or NamespaceTaggingPolicy object.
+// Both kinds share the same structure, NamespaceTaggingPolicy only applies in its namespace.
+type Policy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   Spec   `json:"spec"`
+	Status Status `json:"status,omitempty"`
+}
+
+// Spec Policy specification.
+type Spec struct {
+	// Resource types selected by the policy (for example: "volume"), empty means all types
+	ResourceTypes []string    `json:"resourceTypes,omitempty"`
+	Rules         []*RuleSpec `json:"rules,omitempty"`
+}
+
+// RuleSpec Rule specification, mirror of rule configuration.
+type RuleSpec struct {
+	Name   string           `json:"name,omitempty"`
+	Tag    string           `json:"tag,omitempty"`
+	Query  string           `json:"query,omitempty"`
+	Value  string           `json:"value,omitempty"`
+	Action string           `json:"action,omitempty"`
+	When   []*ConditionSpec `json:"when,omitempty"`
+}
+
+// ConditionSpec Condition specification, mirror of condition configuration.
+type ConditionSpec struct {
+	Condition string `json:"condition,omitempty"`
+	Value     string `json:"value,omitempty"`
+	Operator  string `json:"operator,omitempty"`
+}
+
+// Status Policy status.
+type Status struct {
+	ObservedGeneration int64              `json:"observedGeneration,omitempty"`
+	Conditions         []metav1.Condition `json:"conditions,omitempty"`
+	Errors             []string           `json:"errors,omitempty"`
+	// Number of resources with at least one rule of the policy matching
+	MatchedResources  int          `json:"matchedResources"`
+	LastReconcileTime *metav1.Time `json:"lastReconcileTime,omitempty"`
+}
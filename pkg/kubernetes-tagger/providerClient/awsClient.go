@@ -1,6 +1,7 @@
 package providerclient
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/url"
@@ -12,8 +13,10 @@ import (
 	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/tags"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
 	v1 "k8s.io/api/core/v1"
 )
@@ -26,18 +29,59 @@ const KubernetesAnnotationsNLBValue = "nlb"
 // COPIED FROM https://github.com/kubernetes/kubernetes/blob/d7103187a37dcfff79077c80a151e98571487628/pkg/cloudprovider/providers/aws/aws.go
 const ServiceAnnotationLoadBalancerType = "service.beta.kubernetes.io/aws-load-balancer-type"
 
+// Load balancer type annotation values used by the AWS Load Balancer Controller for network load balancers.
+const (
+	KubernetesAnnotationsExternalValue = "external"
+	KubernetesAnnotationsNLBIPValue    = "nlb-ip"
+)
+
+// AWSLoadBalancerControllerNLBClass Load balancer class of network load balancers managed by the AWS Load Balancer Controller.
+const AWSLoadBalancerControllerNLBClass = "service.k8s.aws/nlb"
+
+// classicLoadBalancerHostnameSuffix Hostname suffix of classic load balancers
+// (ex: a4dd37e88031f4686ace94930e6b1e00-359923540.eu-west-1.elb.amazonaws.com).
+// Other load balancers have the region after "elb" (ex: k8s-default-web-1a2b3c4d5e-0123456789abcdef.elb.eu-west-1.amazonaws.com).
+const classicLoadBalancerHostnameSuffix = ".elb.amazonaws.com"
+
 // ErrLoadBalancerNotFound Load Balancer Not Found.
 var ErrLoadBalancerNotFound = errors.New("load balancer not found")
 
 // ErrNoTagsFound No tags found error.
 var ErrNoTagsFound = errors.New("no tags found on load balancer")
 
+// ErrVolumeNotFound Volume Not Found.
+var ErrVolumeNotFound = errors.New("volume not found")
+
+// awsVolumeNotFoundErrorCode Error code returned by EC2 API on deleted volumes.
+const awsVolumeNotFoundErrorCode = "InvalidVolume.NotFound"
+
+// IsNotFoundError Checks if error comes from a cloud resource that doesn't exist anymore.
+func IsNotFoundError(err error) bool {
+	if errors.Is(err, ErrLoadBalancerNotFound) || errors.Is(err, ErrVolumeNotFound) {
+		return true
+	}
+
+	var awsErr awserr.Error
+	if !errors.As(err, &awsErr) {
+		return false
+	}
+
+	// ELB and ELBV2 APIs use the same code
+	switch awsErr.Code() {
+	case awsVolumeNotFoundErrorCode, elb.ErrCodeAccessPointNotFoundException:
+		return true
+	default:
+		return false
+	}
+}
+
 // AWSProviderClient Aws Provider client.
 type AWSProviderClient struct {
 	awsConfig   *config.AWSConfig
 	ec2client   *ec2.EC2
 	elbclient   *elb.ELB
 	elbv2client *elbv2.ELBV2
+	stsclient   *sts.STS
 }
 
 func newAWSProviderClient(awsConfig *config.AWSConfig) (*AWSProviderClient, error) {
@@ -47,12 +91,19 @@ func newAWSProviderClient(awsConfig *config.AWSConfig) (*AWSProviderClient, erro
 	if err != nil {
 		return nil, err
 	}
+	// Observe all requests
+	sess.Handlers.Complete.PushBackNamed(metricsHandler)
+	// Trace requests, span is started before validation to include all request steps
+	sess.Handlers.Validate.PushFrontNamed(tracingStartHandler)
+	sess.Handlers.Complete.PushBackNamed(tracingEndHandler)
 	// Create EC2 service client
 	ec2client := ec2.New(sess)
 	// Create ELB service client
 	elbclient := elb.New(sess)
 	// Create ELBV2 service client
 	elbv2client := elbv2.New(sess)
+	// Create STS service client
+	stsclient := sts.New(sess)
 
 	// Create aws provider client
 	cl := &AWSProviderClient{
@@ -60,11 +111,32 @@ func newAWSProviderClient(awsConfig *config.AWSConfig) (*AWSProviderClient, erro
 		ec2client:   ec2client,
 		elbclient:   elbclient,
 		elbv2client: elbv2client,
+		stsclient:   stsclient,
 	}
 
 	return cl, nil
 }
 
+// CheckCredentials Check that AWS credentials are valid with a call that doesn't need any permission.
+func (apr *AWSProviderClient) CheckCredentials(ctx context.Context) error {
+	_, err := apr.stsclient.GetCallerIdentityWithContext(ctx, &sts.GetCallerIdentityInput{})
+
+	return err
+}
+
+// GetAccount Get AWS account ID from caller identity and configured region.
+func (apr *AWSProviderClient) GetAccount(ctx context.Context) (*Account, error) {
+	output, err := apr.stsclient.GetCallerIdentityWithContext(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Account{
+		ID:     aws.StringValue(output.Account),
+		Region: apr.awsConfig.Region,
+	}, nil
+}
+
 func getVolumeIDFromPersistentVolume(pv *v1.PersistentVolume) (string, error) {
 	url, err := url.Parse(pv.Spec.AWSElasticBlockStore.VolumeID)
 	if err != nil {
@@ -78,12 +150,17 @@ func getVolumeIDFromPersistentVolume(pv *v1.PersistentVolume) (string, error) {
 }
 
 func getAWSLoadBalancerName(svc *v1.Service) string {
+	return getAWSLoadBalancerNameFromHostname(svc.Status.LoadBalancer.Ingress[0].Hostname)
+}
+
+// getAWSLoadBalancerNameFromHostname Get load balancer name from its DNS name.
+func getAWSLoadBalancerNameFromHostname(hostname string) string {
 	// Split hostname on . and after split the first part on -
-	splitHostname := strings.Split(svc.Status.LoadBalancer.Ingress[0].Hostname, ".")
+	splitHostname := strings.Split(hostname, ".")
 	splitSubDomain := strings.Split(splitHostname[0], "-")
 	fromSplit := 0
 
-	if strings.Contains(svc.Status.LoadBalancer.Ingress[0].Hostname, "internal") {
+	if strings.Contains(hostname, "internal") {
 		// ex: internal-acc1b0155441645c6902a362c6821a9e-138903596.eu-west-1.elb.amazonaws.com
 		fromSplit = 1
 	}
@@ -97,6 +174,27 @@ func getAWSLoadBalancerName(svc *v1.Service) string {
 	return name
 }
 
+// isELBV2Service Check if service load balancer is an ELBV2 one (network or application) instead of a classic one.
+func isELBV2Service(svc *v1.Service) bool {
+	switch svc.Annotations[ServiceAnnotationLoadBalancerType] {
+	case KubernetesAnnotationsNLBValue, KubernetesAnnotationsExternalValue, KubernetesAnnotationsNLBIPValue:
+		return true
+	}
+
+	if svc.Spec.LoadBalancerClass != nil && *svc.Spec.LoadBalancerClass == AWSLoadBalancerControllerNLBClass {
+		return true
+	}
+
+	// Fallback on hostname format
+	if len(svc.Status.LoadBalancer.Ingress) == 0 {
+		return false
+	}
+
+	hostname := svc.Status.LoadBalancer.Ingress[0].Hostname
+
+	return strings.Contains(hostname, ".elb.") && !strings.HasSuffix(hostname, classicLoadBalancerHostnameSuffix)
+}
+
 func transformTagsToAwsEC2Tags(tagsList []*tags.Tag) []*ec2.Tag {
 	awsEc2Tags := make([]*ec2.Tag, 0)
 
@@ -108,7 +206,7 @@ func transformTagsToAwsEC2Tags(tagsList []*tags.Tag) []*ec2.Tag {
 }
 
 // GetActualTagsFromPersistentVolume Get actual tags from persistent volume.
-func (apr *AWSProviderClient) GetActualTagsFromPersistentVolume(pv *v1.PersistentVolume) ([]*tags.Tag, error) {
+func (apr *AWSProviderClient) GetActualTagsFromPersistentVolume(ctx context.Context, pv *v1.PersistentVolume) ([]*tags.Tag, error) {
 	// Get volume ID from pv
 	volumeID, err := getVolumeIDFromPersistentVolume(pv)
 	if err != nil {
@@ -118,7 +216,7 @@ func (apr *AWSProviderClient) GetActualTagsFromPersistentVolume(pv *v1.Persisten
 	volumesIDs := make([]*string, 0)
 	volumesIDs = append(volumesIDs, aws.String(volumeID))
 	// Describe volume to get all information from ec2 volume
-	output, err := apr.ec2client.DescribeVolumes(&ec2.DescribeVolumesInput{
+	output, err := apr.ec2client.DescribeVolumesWithContext(ctx, &ec2.DescribeVolumesInput{
 		VolumeIds: volumesIDs,
 	})
 	if err != nil {
@@ -127,7 +225,7 @@ func (apr *AWSProviderClient) GetActualTagsFromPersistentVolume(pv *v1.Persisten
 
 	volumes := output.Volumes
 	if len(volumes) != 1 {
-		return nil, fmt.Errorf("can't find volume in AWS from volume id \"%s\"", volumeID)
+		return nil, fmt.Errorf("%w: can't find volume in AWS from volume id \"%s\"", ErrVolumeNotFound, volumeID)
 	}
 
 	volume := volumes[0]
@@ -146,18 +244,18 @@ func (apr *AWSProviderClient) GetActualTagsFromPersistentVolume(pv *v1.Persisten
 }
 
 // GetActualTagsFromService Get actual tags from service.
-func (apr *AWSProviderClient) GetActualTagsFromService(svc *v1.Service) ([]*tags.Tag, error) {
+func (apr *AWSProviderClient) GetActualTagsFromService(ctx context.Context, svc *v1.Service) ([]*tags.Tag, error) {
 	// Check if it is a network loadbalancer (elbv2) or classic load balancer (elb)
-	if svc.Annotations != nil && svc.Annotations[ServiceAnnotationLoadBalancerType] == KubernetesAnnotationsNLBValue {
-		return apr.getActualTagsFromELBV2(svc)
+	if isELBV2Service(svc) {
+		return apr.getActualTagsFromELBV2(ctx, svc)
 	}
 
-	return apr.getActualTagsFromELB(svc)
+	return apr.getActualTagsFromELB(ctx, svc)
 }
 
-func (apr *AWSProviderClient) getELBV2ARNFromName(name string) (*string, error) {
+func (apr *AWSProviderClient) getELBV2FromName(ctx context.Context, name string) (*elbv2.LoadBalancer, error) {
 	// Get data from AWS
-	output, err := apr.elbv2client.DescribeLoadBalancers(&elbv2.DescribeLoadBalancersInput{
+	output, err := apr.elbv2client.DescribeLoadBalancersWithContext(ctx, &elbv2.DescribeLoadBalancersInput{
 		Names: []*string{
 			aws.String(name),
 		},
@@ -170,21 +268,80 @@ func (apr *AWSProviderClient) getELBV2ARNFromName(name string) (*string, error)
 		return nil, ErrLoadBalancerNotFound
 	}
 
-	lb := output.LoadBalancers[0]
+	return output.LoadBalancers[0], nil
+}
+
+func (apr *AWSProviderClient) getELBV2ARNFromName(ctx context.Context, name string) (*string, error) {
+	lb, err := apr.getELBV2FromName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
 
 	return lb.LoadBalancerArn, nil
 }
 
-func (apr *AWSProviderClient) getActualTagsFromELBV2(svc *v1.Service) ([]*tags.Tag, error) {
+// GetLoadBalancer Get load balancer information from service.
+func (apr *AWSProviderClient) GetLoadBalancer(ctx context.Context, svc *v1.Service) (*LoadBalancer, error) {
+	// Get aws load balancer name from service
+	name := getAWSLoadBalancerName(svc)
+
+	if isELBV2Service(svc) {
+		lb, err := apr.getELBV2FromName(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		return &LoadBalancer{
+			Type:   getELBV2LoadBalancerType(aws.StringValue(lb.Type)),
+			Name:   name,
+			ARN:    aws.StringValue(lb.LoadBalancerArn),
+			Scheme: aws.StringValue(lb.Scheme),
+		}, nil
+	}
+
+	output, err := apr.elbclient.DescribeLoadBalancersWithContext(ctx, &elb.DescribeLoadBalancersInput{
+		LoadBalancerNames: []*string{
+			aws.String(name),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(output.LoadBalancerDescriptions) == 0 {
+		return nil, ErrLoadBalancerNotFound
+	}
+
+	// Classic load balancers don't have ARN in API
+	return &LoadBalancer{
+		Type:   LoadBalancerTypeClassic,
+		Name:   name,
+		Scheme: aws.StringValue(output.LoadBalancerDescriptions[0].Scheme),
+	}, nil
+}
+
+// getELBV2LoadBalancerType Get load balancer type from ELBV2 one.
+func getELBV2LoadBalancerType(elbv2Type string) string {
+	switch elbv2Type {
+	case elbv2.LoadBalancerTypeEnumNetwork:
+		return LoadBalancerTypeNLB
+	case elbv2.LoadBalancerTypeEnumApplication:
+		return LoadBalancerTypeALB
+	default:
+		return elbv2Type
+	}
+}
+
+func (apr *AWSProviderClient) getActualTagsFromELBV2(ctx context.Context, svc *v1.Service) ([]*tags.Tag, error) {
 	// Get aws load balancer name from service
 	name := getAWSLoadBalancerName(svc)
 	// Get load balancer arn
-	loadBalancerArn, err := apr.getELBV2ARNFromName(name)
+	loadBalancerArn, err := apr.getELBV2ARNFromName(ctx, name)
 	if err != nil {
 		return nil, err
 	}
 
-	describeTagsOutput, err := apr.elbv2client.DescribeTags(&elbv2.DescribeTagsInput{
+	describeTagsOutput, err := apr.elbv2client.DescribeTagsWithContext(ctx, &elbv2.DescribeTagsInput{
 		ResourceArns: []*string{
 			loadBalancerArn,
 		},
@@ -213,10 +370,10 @@ func (apr *AWSProviderClient) getActualTagsFromELBV2(svc *v1.Service) ([]*tags.T
 	return result, nil
 }
 
-func (apr *AWSProviderClient) getActualTagsFromELB(svc *v1.Service) ([]*tags.Tag, error) {
+func (apr *AWSProviderClient) getActualTagsFromELB(ctx context.Context, svc *v1.Service) ([]*tags.Tag, error) {
 	// Get aws load balancer name from service
 	name := getAWSLoadBalancerName(svc)
-	describeTagsOutput, err := apr.elbclient.DescribeTags(&elb.DescribeTagsInput{
+	describeTagsOutput, err := apr.elbclient.DescribeTagsWithContext(ctx, &elb.DescribeTagsInput{
 		LoadBalancerNames: []*string{
 			aws.String(name),
 		},
@@ -247,7 +404,7 @@ func (apr *AWSProviderClient) getActualTagsFromELB(svc *v1.Service) ([]*tags.Tag
 }
 
 // AddTagsFromPersistentVolume Add Tags from persistent volume.
-func (apr *AWSProviderClient) AddTagsFromPersistentVolume(pv *v1.PersistentVolume, tagsList []*tags.Tag) error {
+func (apr *AWSProviderClient) AddTagsFromPersistentVolume(ctx context.Context, pv *v1.PersistentVolume, tagsList []*tags.Tag) error {
 	// Get volume ID from pv
 	volumeID, err := getVolumeIDFromPersistentVolume(pv)
 	if err != nil {
@@ -257,7 +414,7 @@ func (apr *AWSProviderClient) AddTagsFromPersistentVolume(pv *v1.PersistentVolum
 	awsEc2Tags := transformTagsToAwsEC2Tags(tagsList)
 
 	// Add tags to the created instance
-	_, err = apr.ec2client.CreateTags(&ec2.CreateTagsInput{
+	_, err = apr.ec2client.CreateTagsWithContext(ctx, &ec2.CreateTagsInput{
 		Resources: []*string{aws.String(volumeID)},
 		Tags:      awsEc2Tags,
 	})
@@ -270,7 +427,7 @@ func (apr *AWSProviderClient) AddTagsFromPersistentVolume(pv *v1.PersistentVolum
 }
 
 // DeleteTagsFromPersistentVolume Delete tags from persistent volume.
-func (apr *AWSProviderClient) DeleteTagsFromPersistentVolume(pv *v1.PersistentVolume, tagsList []*tags.Tag) error {
+func (apr *AWSProviderClient) DeleteTagsFromPersistentVolume(ctx context.Context, pv *v1.PersistentVolume, tagsList []*tags.Tag) error {
 	// Get volume ID from pv
 	volumeID, err := getVolumeIDFromPersistentVolume(pv)
 	if err != nil {
@@ -280,7 +437,7 @@ func (apr *AWSProviderClient) DeleteTagsFromPersistentVolume(pv *v1.PersistentVo
 	awsEc2Tags := transformTagsToAwsEC2Tags(tagsList)
 
 	// Add tags to the created instance
-	_, err = apr.ec2client.DeleteTags(&ec2.DeleteTagsInput{
+	_, err = apr.ec2client.DeleteTagsWithContext(ctx, &ec2.DeleteTagsInput{
 		Resources: []*string{aws.String(volumeID)},
 		Tags:      awsEc2Tags,
 	})
@@ -293,21 +450,21 @@ func (apr *AWSProviderClient) DeleteTagsFromPersistentVolume(pv *v1.PersistentVo
 }
 
 // AddTagsFromService Add tags from service.
-func (apr *AWSProviderClient) AddTagsFromService(svc *v1.Service, tagsList []*tags.Tag) error {
+func (apr *AWSProviderClient) AddTagsFromService(ctx context.Context, svc *v1.Service, tagsList []*tags.Tag) error {
 	// Check if it is a network loadbalancer (elbv2) or classic load balancer (elb)
-	if svc.Annotations != nil && svc.Annotations[ServiceAnnotationLoadBalancerType] == "nlb" {
-		return apr.addTagsToELBV2(svc, tagsList)
+	if isELBV2Service(svc) {
+		return apr.addTagsToELBV2(ctx, svc, tagsList)
 	}
 
-	return apr.addTagsToELB(svc, tagsList)
+	return apr.addTagsToELB(ctx, svc, tagsList)
 }
 
-func (apr *AWSProviderClient) addTagsToELBV2(svc *v1.Service, tagsList []*tags.Tag) error {
+func (apr *AWSProviderClient) addTagsToELBV2(ctx context.Context, svc *v1.Service, tagsList []*tags.Tag) error {
 	// Get aws load balancer name from service
 	name := getAWSLoadBalancerName(svc)
 
 	// Get load balancer arn
-	loadBalancerArn, err := apr.getELBV2ARNFromName(name)
+	loadBalancerArn, err := apr.getELBV2ARNFromName(ctx, name)
 	if err != nil {
 		return err
 	}
@@ -319,7 +476,7 @@ func (apr *AWSProviderClient) addTagsToELBV2(svc *v1.Service, tagsList []*tags.T
 	}
 
 	// Add tags to the created instance
-	_, err = apr.elbv2client.AddTags(&elbv2.AddTagsInput{
+	_, err = apr.elbv2client.AddTagsWithContext(ctx, &elbv2.AddTagsInput{
 		ResourceArns: []*string{
 			loadBalancerArn,
 		},
@@ -329,7 +486,7 @@ func (apr *AWSProviderClient) addTagsToELBV2(svc *v1.Service, tagsList []*tags.T
 	return err
 }
 
-func (apr *AWSProviderClient) addTagsToELB(svc *v1.Service, tagsList []*tags.Tag) error {
+func (apr *AWSProviderClient) addTagsToELB(ctx context.Context, svc *v1.Service, tagsList []*tags.Tag) error {
 	// Get aws load balancer name from service
 	name := getAWSLoadBalancerName(svc)
 
@@ -339,7 +496,7 @@ func (apr *AWSProviderClient) addTagsToELB(svc *v1.Service, tagsList []*tags.Tag
 	}
 
 	// Add tags to the created instance
-	_, err := apr.elbclient.AddTags(&elb.AddTagsInput{
+	_, err := apr.elbclient.AddTagsWithContext(ctx, &elb.AddTagsInput{
 		LoadBalancerNames: []*string{
 			aws.String(name),
 		},
@@ -350,21 +507,21 @@ func (apr *AWSProviderClient) addTagsToELB(svc *v1.Service, tagsList []*tags.Tag
 }
 
 // DeleteTagsFromService Delete tags from service.
-func (apr *AWSProviderClient) DeleteTagsFromService(svc *v1.Service, tagsList []*tags.Tag) error {
+func (apr *AWSProviderClient) DeleteTagsFromService(ctx context.Context, svc *v1.Service, tagsList []*tags.Tag) error {
 	// Check if it is a network loadbalancer (elbv2) or classic load balancer (elb)
-	if svc.Annotations != nil && svc.Annotations[ServiceAnnotationLoadBalancerType] == "nlb" {
-		return apr.deleteTagsToELBV2(svc, tagsList)
+	if isELBV2Service(svc) {
+		return apr.deleteTagsToELBV2(ctx, svc, tagsList)
 	}
 
-	return apr.deleteTagsToELB(svc, tagsList)
+	return apr.deleteTagsToELB(ctx, svc, tagsList)
 }
 
-func (apr *AWSProviderClient) deleteTagsToELBV2(svc *v1.Service, tagsList []*tags.Tag) error {
+func (apr *AWSProviderClient) deleteTagsToELBV2(ctx context.Context, svc *v1.Service, tagsList []*tags.Tag) error {
 	// Get aws load balancer name from service
 	name := getAWSLoadBalancerName(svc)
 
 	// Get load balancer arn
-	loadBalancerArn, err := apr.getELBV2ARNFromName(name)
+	loadBalancerArn, err := apr.getELBV2ARNFromName(ctx, name)
 	if err != nil {
 		return err
 	}
@@ -376,7 +533,7 @@ func (apr *AWSProviderClient) deleteTagsToELBV2(svc *v1.Service, tagsList []*tag
 	}
 
 	// Delete tags to the created instance
-	_, err = apr.elbv2client.RemoveTags(&elbv2.RemoveTagsInput{
+	_, err = apr.elbv2client.RemoveTagsWithContext(ctx, &elbv2.RemoveTagsInput{
 		ResourceArns: []*string{
 			loadBalancerArn,
 		},
@@ -386,7 +543,7 @@ func (apr *AWSProviderClient) deleteTagsToELBV2(svc *v1.Service, tagsList []*tag
 	return err
 }
 
-func (apr *AWSProviderClient) deleteTagsToELB(svc *v1.Service, tagsList []*tags.Tag) error {
+func (apr *AWSProviderClient) deleteTagsToELB(ctx context.Context, svc *v1.Service, tagsList []*tags.Tag) error {
 	// Get aws load balancer name from service
 	name := getAWSLoadBalancerName(svc)
 
@@ -396,7 +553,7 @@ func (apr *AWSProviderClient) deleteTagsToELB(svc *v1.Service, tagsList []*tags.
 	}
 
 	// Delete tags to the created instance
-	_, err := apr.elbclient.RemoveTags(&elb.RemoveTagsInput{
+	_, err := apr.elbclient.RemoveTagsWithContext(ctx, &elb.RemoveTagsInput{
 		LoadBalancerNames: []*string{
 			aws.String(name),
 		},
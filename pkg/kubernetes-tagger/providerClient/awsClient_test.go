@@ -1,9 +1,14 @@
 package providerclient
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 
+	"github.com/aws/aws-sdk-go/aws/awserr"
+
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func Test_getAWSLoadBalancerName(t *testing.T) {
@@ -177,3 +182,94 @@ func Test_getVolumeIDFromPersistentVolume(t *testing.T) {
 		})
 	}
 }
+
+func Test_isELBV2Service(t *testing.T) {
+	nlbClass := AWSLoadBalancerControllerNLBClass
+	tests := []struct {
+		name string
+		svc  *v1.Service
+		want bool
+	}{
+		{
+			"nlb annotation",
+			&v1.Service{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{ServiceAnnotationLoadBalancerType: "nlb"}}},
+			true,
+		},
+		{
+			"aws load balancer controller annotation",
+			&v1.Service{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{ServiceAnnotationLoadBalancerType: "external"}}},
+			true,
+		},
+		{
+			"aws load balancer controller class",
+			&v1.Service{Spec: v1.ServiceSpec{LoadBalancerClass: &nlbClass}},
+			true,
+		},
+		{
+			"nlb hostname",
+			&v1.Service{Status: v1.ServiceStatus{LoadBalancer: v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{
+				{Hostname: "a1b2c3-0123456789abcdef.elb.eu-west-1.amazonaws.com"},
+			}}}},
+			true,
+		},
+		{
+			"classic hostname",
+			&v1.Service{Status: v1.ServiceStatus{LoadBalancer: v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{
+				{Hostname: "aa59f0ca83-7455.eu-west-1.elb.amazonaws.com"},
+			}}}},
+			false,
+		},
+		{
+			"no ingress",
+			&v1.Service{},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isELBV2Service(tt.svc); got != tt.want {
+				t.Errorf("isELBV2Service() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_getELBV2LoadBalancerType(t *testing.T) {
+	tests := []struct {
+		elbv2Type string
+		want      string
+	}{
+		{"network", LoadBalancerTypeNLB},
+		{"application", LoadBalancerTypeALB},
+		{"gateway", "gateway"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.elbv2Type, func(t *testing.T) {
+			if got := getELBV2LoadBalancerType(tt.elbv2Type); got != tt.want {
+				t.Errorf("getELBV2LoadBalancerType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsNotFoundError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"load balancer not found", ErrLoadBalancerNotFound, true},
+		{"wrapped volume not found", fmt.Errorf("%w: vol-1", ErrVolumeNotFound), true},
+		{"deleted volume", awserr.New("InvalidVolume.NotFound", "The volume 'vol-1' does not exist.", nil), true},
+		{"deleted load balancer", awserr.New("LoadBalancerNotFound", "There is no ACTIVE Load Balancer named 'lb'", nil), true},
+		{"aws error", awserr.New("Throttling", "Rate exceeded", nil), false},
+		{"other error", errors.New("timeout"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsNotFoundError(tt.err); got != tt.want {
+				t.Errorf("IsNotFoundError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,287 @@
+package providerclient
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/tags"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+// AWSEBSCSIDriverName Name of the AWS EBS CSI driver.
+const AWSEBSCSIDriverName = "ebs.csi.aws.com"
+
+// describeTagsMaxResources Maximum number of load balancers in one describe tags request.
+const describeTagsMaxResources = 20
+
+// GetPersistentVolumeResourceID Get EBS volume ID of in-tree or CSI persistent volume, empty for other volumes.
+func (apr *AWSProviderClient) GetPersistentVolumeResourceID(pv *v1.PersistentVolume) (string, error) {
+	switch {
+	case pv.Spec.AWSElasticBlockStore != nil:
+		return getVolumeIDFromPersistentVolume(pv)
+	case pv.Spec.CSI != nil && pv.Spec.CSI.Driver == AWSEBSCSIDriverName:
+		return pv.Spec.CSI.VolumeHandle, nil
+	default:
+		return "", nil
+	}
+}
+
+// GetServiceResourceID Get load balancer name of service.
+func (apr *AWSProviderClient) GetServiceResourceID(svc *v1.Service) string {
+	if len(svc.Status.LoadBalancer.Ingress) == 0 {
+		return ""
+	}
+
+	return getAWSLoadBalancerName(svc)
+}
+
+// GetIngressResourceID Get load balancer name of ingress provisioned by AWS Load Balancer Controller.
+func (apr *AWSProviderClient) GetIngressResourceID(ing *networkingv1.Ingress) string {
+	if len(ing.Status.LoadBalancer.Ingress) == 0 || ing.Status.LoadBalancer.Ingress[0].Hostname == "" {
+		return ""
+	}
+
+	return getAWSLoadBalancerNameFromHostname(ing.Status.LoadBalancer.Ingress[0].Hostname)
+}
+
+// ListClusterResources List EBS volumes and load balancers having the cluster tag.
+// Load balancers of AWS Load Balancer Controller are found with its cluster tag.
+func (apr *AWSProviderClient) ListClusterResources(ctx context.Context, clusterName string) ([]*CloudResource, error) {
+	clusterTagKey := ClusterTagPrefix + clusterName
+
+	result, err := apr.listClusterVolumes(ctx, clusterTagKey)
+	if err != nil {
+		return nil, err
+	}
+
+	loadBalancers, err := apr.listClusterELBs(ctx, clusterTagKey)
+	if err != nil {
+		return nil, err
+	}
+
+	result = append(result, loadBalancers...)
+
+	loadBalancers, err = apr.listClusterELBV2s(ctx, clusterTagKey, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(result, loadBalancers...), nil
+}
+
+func (apr *AWSProviderClient) listClusterVolumes(ctx context.Context, clusterTagKey string) ([]*CloudResource, error) {
+	result := make([]*CloudResource, 0)
+
+	err := apr.ec2client.DescribeVolumesPagesWithContext(ctx, &ec2.DescribeVolumesInput{
+		Filters: []*ec2.Filter{{Name: aws.String("tag-key"), Values: []*string{aws.String(clusterTagKey)}}},
+	}, func(output *ec2.DescribeVolumesOutput, lastPage bool) bool {
+		for _, volume := range output.Volumes {
+			resourceTags := make([]*tags.Tag, 0, len(volume.Tags))
+			for _, tag := range volume.Tags {
+				resourceTags = append(resourceTags, &tags.Tag{Key: aws.StringValue(tag.Key), Value: aws.StringValue(tag.Value)})
+			}
+
+			result = append(result, &CloudResource{
+				Type:         CloudResourceTypeVolume,
+				ID:           aws.StringValue(volume.VolumeId),
+				Tags:         resourceTags,
+				CreationTime: aws.TimeValue(volume.CreateTime),
+			})
+		}
+
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (apr *AWSProviderClient) listClusterELBs(ctx context.Context, clusterTagKey string) ([]*CloudResource, error) {
+	names := make([]*string, 0)
+	creationTimes := make(map[string]time.Time)
+
+	err := apr.elbclient.DescribeLoadBalancersPagesWithContext(ctx, &elb.DescribeLoadBalancersInput{},
+		func(output *elb.DescribeLoadBalancersOutput, lastPage bool) bool {
+			for _, loadBalancer := range output.LoadBalancerDescriptions {
+				names = append(names, loadBalancer.LoadBalancerName)
+				creationTimes[aws.StringValue(loadBalancer.LoadBalancerName)] = aws.TimeValue(loadBalancer.CreatedTime)
+			}
+
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*CloudResource, 0)
+
+	// Tags can only be got by batch
+	for start := 0; start < len(names); start += describeTagsMaxResources {
+		end := start + describeTagsMaxResources
+		if end > len(names) {
+			end = len(names)
+		}
+
+		output, err := apr.elbclient.DescribeTagsWithContext(ctx, &elb.DescribeTagsInput{LoadBalancerNames: names[start:end]})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, description := range output.TagDescriptions {
+			resourceTags := make([]*tags.Tag, 0, len(description.Tags))
+			for _, tag := range description.Tags {
+				resourceTags = append(resourceTags, &tags.Tag{Key: aws.StringValue(tag.Key), Value: aws.StringValue(tag.Value)})
+			}
+
+			if hasTagKey(resourceTags, clusterTagKey) {
+				name := aws.StringValue(description.LoadBalancerName)
+				result = append(result, &CloudResource{
+					Type:         CloudResourceTypeLoadBalancer,
+					ID:           name,
+					Tags:         resourceTags,
+					CreationTime: creationTimes[name],
+				})
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (apr *AWSProviderClient) listClusterELBV2s(ctx context.Context, clusterTagKey, clusterName string) ([]*CloudResource, error) {
+	loadBalancersByARN := make(map[string]*elbv2.LoadBalancer)
+	arns := make([]*string, 0)
+
+	err := apr.elbv2client.DescribeLoadBalancersPagesWithContext(ctx, &elbv2.DescribeLoadBalancersInput{},
+		func(output *elbv2.DescribeLoadBalancersOutput, lastPage bool) bool {
+			for _, loadBalancer := range output.LoadBalancers {
+				loadBalancersByARN[aws.StringValue(loadBalancer.LoadBalancerArn)] = loadBalancer
+				arns = append(arns, loadBalancer.LoadBalancerArn)
+			}
+
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*CloudResource, 0)
+
+	// Tags can only be got by batch
+	for start := 0; start < len(arns); start += describeTagsMaxResources {
+		end := start + describeTagsMaxResources
+		if end > len(arns) {
+			end = len(arns)
+		}
+
+		output, err := apr.elbv2client.DescribeTagsWithContext(ctx, &elbv2.DescribeTagsInput{ResourceArns: arns[start:end]})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, description := range output.TagDescriptions {
+			resourceTags := make([]*tags.Tag, 0, len(description.Tags))
+			for _, tag := range description.Tags {
+				resourceTags = append(resourceTags, &tags.Tag{Key: aws.StringValue(tag.Key), Value: aws.StringValue(tag.Value)})
+			}
+
+			if hasTagKey(resourceTags, clusterTagKey) || hasTag(resourceTags, LoadBalancerControllerClusterTag, clusterName) {
+				loadBalancer := loadBalancersByARN[aws.StringValue(description.ResourceArn)]
+				result = append(result, &CloudResource{
+					Type:         CloudResourceTypeLoadBalancer,
+					ID:           aws.StringValue(loadBalancer.LoadBalancerName),
+					ARN:          aws.StringValue(loadBalancer.LoadBalancerArn),
+					Tags:         resourceTags,
+					CreationTime: aws.TimeValue(loadBalancer.CreatedTime),
+				})
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// AddTagsToCloudResource Add tags to EBS volume or load balancer.
+func (apr *AWSProviderClient) AddTagsToCloudResource(ctx context.Context, resource *CloudResource, tagsList []*tags.Tag) error {
+	var err error
+
+	switch {
+	case resource.Type == CloudResourceTypeVolume:
+		_, err = apr.ec2client.CreateTagsWithContext(ctx, &ec2.CreateTagsInput{
+			Resources: []*string{aws.String(resource.ID)},
+			Tags:      transformTagsToAwsEC2Tags(tagsList),
+		})
+	case resource.ARN != "":
+		awsTags := make([]*elbv2.Tag, 0, len(tagsList))
+		for _, tag := range tagsList {
+			awsTags = append(awsTags, &elbv2.Tag{Key: aws.String(tag.Key), Value: aws.String(tag.Value)})
+		}
+
+		_, err = apr.elbv2client.AddTagsWithContext(ctx, &elbv2.AddTagsInput{ResourceArns: []*string{aws.String(resource.ARN)}, Tags: awsTags})
+	default:
+		awsTags := make([]*elb.Tag, 0, len(tagsList))
+		for _, tag := range tagsList {
+			awsTags = append(awsTags, &elb.Tag{Key: aws.String(tag.Key), Value: aws.String(tag.Value)})
+		}
+
+		_, err = apr.elbclient.AddTagsWithContext(ctx, &elb.AddTagsInput{LoadBalancerNames: []*string{aws.String(resource.ID)}, Tags: awsTags})
+	}
+
+	return err
+}
+
+// DeleteTagsFromCloudResource Delete tags from EBS volume or load balancer.
+func (apr *AWSProviderClient) DeleteTagsFromCloudResource(ctx context.Context, resource *CloudResource, tagsList []*tags.Tag) error {
+	var err error
+
+	switch {
+	case resource.Type == CloudResourceTypeVolume:
+		_, err = apr.ec2client.DeleteTagsWithContext(ctx, &ec2.DeleteTagsInput{
+			Resources: []*string{aws.String(resource.ID)},
+			Tags:      transformTagsToAwsEC2Tags(tagsList),
+		})
+	case resource.ARN != "":
+		keys := make([]*string, 0, len(tagsList))
+		for _, tag := range tagsList {
+			keys = append(keys, aws.String(tag.Key))
+		}
+
+		_, err = apr.elbv2client.RemoveTagsWithContext(ctx, &elbv2.RemoveTagsInput{ResourceArns: []*string{aws.String(resource.ARN)}, TagKeys: keys})
+	default:
+		keys := make([]*elb.TagKeyOnly, 0, len(tagsList))
+		for _, tag := range tagsList {
+			keys = append(keys, &elb.TagKeyOnly{Key: aws.String(tag.Key)})
+		}
+
+		_, err = apr.elbclient.RemoveTagsWithContext(ctx, &elb.RemoveTagsInput{LoadBalancerNames: []*string{aws.String(resource.ID)}, Tags: keys})
+	}
+
+	return err
+}
+
+func hasTagKey(tagsList []*tags.Tag, key string) bool {
+	for _, tag := range tagsList {
+		if tag.Key == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasTag(tagsList []*tags.Tag, key, value string) bool {
+	for _, tag := range tagsList {
+		if tag.Key == key && tag.Value == value {
+			return true
+		}
+	}
+
+	return false
+}
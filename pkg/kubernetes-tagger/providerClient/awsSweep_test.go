@@ -0,0 +1,97 @@
+package providerclient
+
+import (
+	"testing"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/tags"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+func TestAWSProviderClient_GetPersistentVolumeResourceID(t *testing.T) {
+	tests := []struct {
+		name string
+		pv   *v1.PersistentVolume
+		want string
+	}{
+		{
+			"in-tree EBS volume",
+			&v1.PersistentVolume{Spec: v1.PersistentVolumeSpec{PersistentVolumeSource: v1.PersistentVolumeSource{
+				AWSElasticBlockStore: &v1.AWSElasticBlockStoreVolumeSource{VolumeID: "aws://eu-west-1a/vol-1"},
+			}}},
+			"vol-1",
+		},
+		{
+			"EBS CSI volume",
+			&v1.PersistentVolume{Spec: v1.PersistentVolumeSpec{PersistentVolumeSource: v1.PersistentVolumeSource{
+				CSI: &v1.CSIPersistentVolumeSource{Driver: AWSEBSCSIDriverName, VolumeHandle: "vol-2"},
+			}}},
+			"vol-2",
+		},
+		{
+			"other CSI volume",
+			&v1.PersistentVolume{Spec: v1.PersistentVolumeSpec{PersistentVolumeSource: v1.PersistentVolumeSource{
+				CSI: &v1.CSIPersistentVolumeSource{Driver: "efs.csi.aws.com", VolumeHandle: "fs-1"},
+			}}},
+			"",
+		},
+		{
+			"not a cloud volume",
+			&v1.PersistentVolume{Spec: v1.PersistentVolumeSpec{PersistentVolumeSource: v1.PersistentVolumeSource{
+				HostPath: &v1.HostPathVolumeSource{Path: "/data"},
+			}}},
+			"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&AWSProviderClient{}).GetPersistentVolumeResourceID(tt.pv)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAWSProviderClient_GetServiceResourceID(t *testing.T) {
+	apr := &AWSProviderClient{}
+
+	svc := &v1.Service{Spec: v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer}}
+	assert.Equal(t, "", apr.GetServiceResourceID(svc))
+
+	svc.Status.LoadBalancer.Ingress = []v1.LoadBalancerIngress{
+		{Hostname: "a8f1e7f1e2c5d11e8b7e50a1ad1b2d2f-1234567890.eu-west-1.elb.amazonaws.com"},
+	}
+	assert.Equal(t, "a8f1e7f1e2c5d11e8b7e50a1ad1b2d2f", apr.GetServiceResourceID(svc))
+}
+
+func TestAWSProviderClient_GetIngressResourceID(t *testing.T) {
+	apr := &AWSProviderClient{}
+
+	ing := &networkingv1.Ingress{}
+	assert.Equal(t, "", apr.GetIngressResourceID(ing))
+
+	ing.Status.LoadBalancer.Ingress = []v1.LoadBalancerIngress{
+		{Hostname: "k8s-default-web-4f3a2b1c0d-1234567890.eu-west-1.elb.amazonaws.com"},
+	}
+	assert.Equal(t, "k8s-default-web-4f3a2b1c0d", apr.GetIngressResourceID(ing))
+
+	ing.Status.LoadBalancer.Ingress = []v1.LoadBalancerIngress{
+		{Hostname: "internal-k8s-default-web-4f3a2b1c0d-1234567890.eu-west-1.elb.amazonaws.com"},
+	}
+	assert.Equal(t, "k8s-default-web-4f3a2b1c0d", apr.GetIngressResourceID(ing))
+}
+
+func Test_hasTag(t *testing.T) {
+	tagsList := []*tags.Tag{{Key: LoadBalancerControllerClusterTag, Value: "prod"}}
+
+	assert.True(t, hasTag(tagsList, LoadBalancerControllerClusterTag, "prod"))
+	assert.False(t, hasTag(tagsList, LoadBalancerControllerClusterTag, "dev"))
+}
+
+func Test_hasTagKey(t *testing.T) {
+	tagsList := []*tags.Tag{{Key: "kubernetes.io/cluster/prod", Value: "owned"}}
+
+	assert.True(t, hasTagKey(tagsList, ClusterTagPrefix+"prod"))
+	assert.False(t, hasTagKey(tagsList, ClusterTagPrefix+"dev"))
+}
@@ -0,0 +1,42 @@
+package providerclient
+
+import (
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/metrics"
+)
+
+// Code label value for successful requests.
+const successCode = "OK"
+
+// metricsHandler AWS request handler observing all API calls.
+var metricsHandler = request.NamedHandler{
+	Name: "kubernetes-tagger.metrics",
+	Fn:   observeRequest,
+}
+
+// observeRequest Observe AWS request count and duration once completed.
+func observeRequest(r *request.Request) {
+	service := r.ClientInfo.ServiceName
+	operation := r.Operation.Name
+
+	metrics.ProviderRequests.WithLabelValues(service, operation, getErrorCode(r.Error)).Inc()
+	metrics.ProviderRequestDuration.WithLabelValues(service, operation).Observe(time.Since(r.Time).Seconds())
+}
+
+// getErrorCode Get AWS error code from error.
+func getErrorCode(err error) string {
+	if err == nil {
+		return successCode
+	}
+
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) {
+		return awsErr.Code()
+	}
+
+	return "Unknown"
+}
@@ -0,0 +1,15 @@
+package providerclient
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_getErrorCode(t *testing.T) {
+	assert.Equal(t, "OK", getErrorCode(nil))
+	assert.Equal(t, "Throttling", getErrorCode(awserr.New("Throttling", "Rate exceeded", nil)))
+	assert.Equal(t, "Unknown", getErrorCode(errors.New("fake")))
+}
@@ -1,19 +1,83 @@
 package providerclient
 
 import (
+	"context"
+	"time"
+
 	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
 	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/tags"
 	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+// Load balancer types.
+const (
+	LoadBalancerTypeClassic = "classic"
+	LoadBalancerTypeNLB     = "nlb"
+	LoadBalancerTypeALB     = "alb"
 )
 
+// LoadBalancer Load balancer information from provider.
+type LoadBalancer struct {
+	// Type classic, nlb or alb
+	Type string `json:"type"`
+	Name string `json:"name"`
+	// ARN is empty for classic load balancers
+	ARN string `json:"arn,omitempty"`
+	// Scheme internet-facing or internal
+	Scheme string `json:"scheme,omitempty"`
+}
+
+// Account Provider account used by the client.
+type Account struct {
+	ID     string `json:"id"`
+	Region string `json:"region"`
+}
+
+// Cloud resource types, same values as resources types.
+const (
+	CloudResourceTypeVolume       = "volume"
+	CloudResourceTypeLoadBalancer = "loadbalancer"
+)
+
+// ClusterTagPrefix Prefix of the tag set by Kubernetes on cloud resources of a cluster, followed by cluster name.
+const ClusterTagPrefix = "kubernetes.io/cluster/"
+
+// LoadBalancerControllerClusterTag Tag set by AWS Load Balancer Controller on load balancers of a cluster, cluster name is its value.
+const LoadBalancerControllerClusterTag = "elbv2.k8s.aws/cluster"
+
+// CloudResource Cloud resource found in provider.
+type CloudResource struct {
+	Type string `json:"type"`
+	// ID Volume ID or load balancer name
+	ID string `json:"id"`
+	// ARN is only set for network and application load balancers
+	ARN          string      `json:"arn,omitempty"`
+	Tags         []*tags.Tag `json:"tags"`
+	CreationTime time.Time   `json:"creationTime"`
+}
+
 // ProviderClient Provider Client.
 type ProviderClient interface {
-	GetActualTagsFromPersistentVolume(pv *v1.PersistentVolume) ([]*tags.Tag, error)
-	GetActualTagsFromService(svc *v1.Service) ([]*tags.Tag, error)
-	AddTagsFromPersistentVolume(pv *v1.PersistentVolume, tagsList []*tags.Tag) error
-	DeleteTagsFromPersistentVolume(pv *v1.PersistentVolume, tagsList []*tags.Tag) error
-	AddTagsFromService(svc *v1.Service, tagsList []*tags.Tag) error
-	DeleteTagsFromService(svc *v1.Service, tagsList []*tags.Tag) error
+	GetActualTagsFromPersistentVolume(ctx context.Context, pv *v1.PersistentVolume) ([]*tags.Tag, error)
+	GetActualTagsFromService(ctx context.Context, svc *v1.Service) ([]*tags.Tag, error)
+	AddTagsFromPersistentVolume(ctx context.Context, pv *v1.PersistentVolume, tagsList []*tags.Tag) error
+	DeleteTagsFromPersistentVolume(ctx context.Context, pv *v1.PersistentVolume, tagsList []*tags.Tag) error
+	AddTagsFromService(ctx context.Context, svc *v1.Service, tagsList []*tags.Tag) error
+	DeleteTagsFromService(ctx context.Context, svc *v1.Service, tagsList []*tags.Tag) error
+	GetLoadBalancer(ctx context.Context, svc *v1.Service) (*LoadBalancer, error)
+	GetAccount(ctx context.Context) (*Account, error)
+	// ListClusterResources List cloud resources having the cluster tag.
+	ListClusterResources(ctx context.Context, clusterName string) ([]*CloudResource, error)
+	AddTagsToCloudResource(ctx context.Context, resource *CloudResource, tagsList []*tags.Tag) error
+	DeleteTagsFromCloudResource(ctx context.Context, resource *CloudResource, tagsList []*tags.Tag) error
+	// GetPersistentVolumeResourceID Get cloud resource ID of a persistent volume, empty when it isn't managed by provider.
+	GetPersistentVolumeResourceID(pv *v1.PersistentVolume) (string, error)
+	// GetServiceResourceID Get cloud resource ID of a load balancer service.
+	GetServiceResourceID(svc *v1.Service) string
+	// GetIngressResourceID Get cloud resource ID of an ingress load balancer, empty when it isn't provisioned.
+	GetIngressResourceID(ing *networkingv1.Ingress) string
+	CheckCredentials(ctx context.Context) error
 }
 
 // NewProviderClient New Provider client.
@@ -0,0 +1,45 @@
+package providerclient
+
+import (
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/tracing"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RPC system of AWS API calls in spans.
+const awsRPCSystem = "aws-api"
+
+// tracingStartHandler AWS request handler starting a span for API calls done in traced runs.
+// Retries of a call are in the same span.
+var tracingStartHandler = request.NamedHandler{
+	Name: "kubernetes-tagger.tracing.start",
+	Fn:   startRequestSpan,
+}
+
+// tracingEndHandler AWS request handler ending span started by tracingStartHandler.
+var tracingEndHandler = request.NamedHandler{
+	Name: "kubernetes-tagger.tracing.end",
+	Fn:   endRequestSpan,
+}
+
+func startRequestSpan(r *request.Request) {
+	service := r.ClientInfo.ServiceName
+	operation := r.Operation.Name
+
+	spanCtx, _ := tracing.StartChildSpan(r.Context(), service+"."+operation,
+		semconv.RPCSystemKey.String(awsRPCSystem), semconv.RPCService(service), semconv.RPCMethod(operation))
+
+	r.SetContext(spanCtx)
+}
+
+func endRequestSpan(r *request.Request) {
+	// Span isn't recording when call isn't done in a traced run
+	span := trace.SpanFromContext(r.Context())
+
+	if r.RequestID != "" {
+		span.SetAttributes(semconv.AWSRequestID(r.RequestID))
+	}
+
+	tracing.EndSpan(span, r.Error)
+}
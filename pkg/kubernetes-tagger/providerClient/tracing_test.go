@@ -0,0 +1,58 @@
+package providerclient
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/client/metadata"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/tracing"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTracedRequest(reqCtx context.Context) *request.Request {
+	r := &request.Request{
+		ClientInfo:  metadata.ClientInfo{ServiceName: "ec2"},
+		Operation:   &request.Operation{Name: "CreateTags"},
+		HTTPRequest: httptest.NewRequest("POST", "https://ec2.amazonaws.com", nil),
+	}
+	r.SetContext(reqCtx)
+
+	return r
+}
+
+func Test_requestSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	// Calls outside of traced runs aren't traced
+	r := newTracedRequest(context.Background())
+	startRequestSpan(r)
+	endRequestSpan(r)
+	assert.Empty(t, recorder.Ended())
+
+	runCtx, span := tracing.StartSpan(context.Background(), "run")
+	r = newTracedRequest(runCtx)
+	startRequestSpan(r)
+	r.RequestID = "request-1"
+	r.Error = awserr.New("UnauthorizedOperation", "denied", nil)
+	endRequestSpan(r)
+	span.End()
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "ec2.CreateTags", spans[0].Name())
+	assert.Equal(t, span.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Contains(t, spans[0].Attributes(), semconv.RPCMethod("CreateTags"))
+	assert.Contains(t, spans[0].Attributes(), semconv.AWSRequestID("request-1"))
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+}
@@ -1,6 +1,7 @@
 package resources
 
 import (
+	"context"
 	"strings"
 
 	providerclient "github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/providerClient"
@@ -16,11 +17,14 @@ import (
 type AWSLoadBalancer struct {
 	resourceType     string
 	resourcePlatform string
-	awsConfig        *config.AWSConfig
-	service          *v1.Service
-	k8sClient        kubernetes.Interface
-	log              *logrus.Entry
-	prcl             *providerclient.AWSProviderClient
+	// Context of the run using the resource
+	ctx          context.Context
+	awsConfig    *config.AWSConfig
+	service      *v1.Service
+	k8sClient    kubernetes.Interface
+	objectGetter ObjectGetter
+	log          *logrus.Entry
+	prcl         *providerclient.AWSProviderClient
 }
 
 // Type Get type.
@@ -33,15 +37,22 @@ func (al *AWSLoadBalancer) Platform() string {
 	return al.resourcePlatform
 }
 
+// ID Get load balancer name.
+func (al *AWSLoadBalancer) ID() string {
+	return al.prcl.GetServiceResourceID(al.service)
+}
+
 // newAWSLoadBalancer Generate a new AWS Load Balancer.
 func newAWSLoadBalancer(
+	ctx context.Context,
 	k8sClient kubernetes.Interface,
+	objectGetter ObjectGetter,
 	svc *v1.Service,
 	config *config.Configuration,
 	prcl providerclient.ProviderClient,
 ) (*AWSLoadBalancer, error) { // nolint: unparam // Ignore this
-	// Create logger
-	log := logrus.WithFields(logrus.Fields{
+	// Create logger, context adds trace fields
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
 		"type":        LoadBalancerResourceType,
 		"platform":    AWSResourcePlatform,
 		"serviceName": svc.Name,
@@ -53,7 +64,9 @@ func newAWSLoadBalancer(
 		resourcePlatform: AWSResourcePlatform,
 		awsConfig:        awsConfig,
 		service:          svc,
+		ctx:              ctx,
 		k8sClient:        k8sClient,
+		objectGetter:     objectGetter,
 		log:              log,
 		prcl:             prcl.(*providerclient.AWSProviderClient),
 	}
@@ -88,25 +101,38 @@ func isAWSLoadBalancerResource(svc *v1.Service) bool {
 
 // GetAvailableTagValues Get available tag values.
 func (al *AWSLoadBalancer) GetAvailableTagValues() (map[string]interface{}, error) {
-	// Begin to create available tag values
-	availableTags := make(map[string]interface{})
-	availableTags["type"] = al.Type()
-	availableTags["platform"] = al.Platform()
-	svcTags := make(map[string]interface{})
-	svcTags["name"] = al.service.Name
-	svcTags["namespace"] = al.service.Namespace
-	svcTags["annotations"] = al.service.Annotations
-	svcTags["labels"] = al.service.Labels
-	availableTags["service"] = svcTags
-
-	return availableTags, nil
+	var err error
+
+	related := &RelatedObjects{}
+
+	related.Namespace, err = al.objectGetter.GetNamespace(al.service.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	related.Workload, err = getServiceWorkload(al.objectGetter, al.service)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load balancer may not be found yet while it is provisioned, other errors are retried
+	related.LoadBalancer, err = al.prcl.GetLoadBalancer(al.ctx, al.service)
+	if err != nil {
+		if !providerclient.IsNotFoundError(err) {
+			return nil, err
+		}
+
+		al.log.Warnf("Load balancer not found, its values are ignored: %v", err)
+	}
+
+	return NewServiceTagValues(al.Platform(), al.service, related), nil
 }
 
 // GetActualTags Get actual tags.
 func (al *AWSLoadBalancer) GetActualTags() ([]*tags.Tag, error) {
 	al.log.Info("Get actual tags on resource")
 
-	return al.prcl.GetActualTagsFromService(al.service)
+	return al.prcl.GetActualTagsFromService(al.ctx, al.service)
 }
 
 // ManageTags Manage tags.
@@ -117,7 +143,7 @@ func (al *AWSLoadBalancer) ManageTags(delta *tags.TagDelta) error { // nolint: d
 	// Check if tags needs to be added
 	if len(delta.AddList) > 0 {
 		al.log.WithField("delta", delta).Debug("Add list detected. Begin request to AWS.")
-		err := al.prcl.AddTagsFromService(al.service, delta.AddList)
+		err := al.prcl.AddTagsFromService(al.ctx, al.service, delta.AddList)
 		// Check error
 		if err != nil {
 			return err
@@ -131,7 +157,7 @@ func (al *AWSLoadBalancer) ManageTags(delta *tags.TagDelta) error { // nolint: d
 	// Check if tags needs to be removed
 	if len(delta.DeleteList) > 0 {
 		al.log.WithField("delta", delta).Debug("Delete list detected. Begin request to AWS.")
-		err := al.prcl.DeleteTagsFromService(al.service, delta.DeleteList)
+		err := al.prcl.DeleteTagsFromService(al.ctx, al.service, delta.DeleteList)
 		// Check error
 		if err != nil {
 			return err
@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	providerclient "github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/providerClient"
 	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/tags"
 
@@ -15,9 +17,12 @@ import (
 type AWSVolume struct {
 	resourceType     string
 	resourcePlatform string
+	// Context of the run using the resource
+	ctx              context.Context
 	awsConfig        *config.AWSConfig
 	persistentVolume *v1.PersistentVolume
 	k8sClient        kubernetes.Interface
+	objectGetter     ObjectGetter
 	log              *logrus.Entry
 	prcl             *providerclient.AWSProviderClient
 }
@@ -32,15 +37,25 @@ func (av *AWSVolume) Platform() string {
 	return av.resourcePlatform
 }
 
+// ID Get EBS volume ID.
+func (av *AWSVolume) ID() string {
+	// Errors are already returned by cloud calls using volume ID
+	id, _ := av.prcl.GetPersistentVolumeResourceID(av.persistentVolume)
+
+	return id
+}
+
 // newAWSVolume Generate a new AWS Volume.
 func newAWSVolume(
+	ctx context.Context,
 	k8sClient kubernetes.Interface,
+	objectGetter ObjectGetter,
 	pv *v1.PersistentVolume,
 	config *config.Configuration,
 	prcl providerclient.ProviderClient,
 ) (*AWSVolume, error) { // nolint: unparam // Ignore this
-	// Create logger
-	log := logrus.WithFields(logrus.Fields{
+	// Create logger, context adds trace fields
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
 		"type":                 VolumeResourceType,
 		"platform":             AWSResourcePlatform,
 		"persistentVolumeName": pv.Name,
@@ -52,7 +67,9 @@ func newAWSVolume(
 		resourcePlatform: AWSResourcePlatform,
 		awsConfig:        awsConfig,
 		persistentVolume: pv,
+		ctx:              ctx,
 		k8sClient:        k8sClient,
+		objectGetter:     objectGetter,
 		log:              log,
 		prcl:             prcl.(*providerclient.AWSProviderClient),
 	}
@@ -67,43 +84,41 @@ func isAWSVolumeResource(pv *v1.PersistentVolume) bool {
 
 // GetAvailableTagValues Get available tags.
 func (av *AWSVolume) GetAvailableTagValues() (map[string]interface{}, error) {
-	pvc, err := getPersistentVolumeClaim(av.persistentVolume, av.k8sClient)
+	pvc, err := getPersistentVolumeClaim(av.ctx, av.persistentVolume, av.k8sClient)
 	if err != nil {
 		return nil, err
 	}
 
-	// Begin to create available tag values
-	availableTags := make(map[string]interface{})
-	availableTags["type"] = av.Type()
-	availableTags["platform"] = av.Platform()
-	pvTags := make(map[string]interface{})
-	pvTags["labels"] = av.persistentVolume.Labels
-	pvTags["annotations"] = av.persistentVolume.Annotations
-	pvTags["name"] = av.persistentVolume.Name
-	pvTags["phase"] = av.persistentVolume.Status.Phase
-	pvTags["reclaimpolicy"] = av.persistentVolume.Spec.PersistentVolumeReclaimPolicy
-	pvTags["storageclassname"] = av.persistentVolume.Spec.StorageClassName
-	availableTags["persistentvolume"] = pvTags
-
-	// If pvc exists, create tag values
+	related := &RelatedObjects{}
+
+	// Namespace of persistent volume is the claim one
 	if pvc != nil {
-		pvcTags := make(map[string]interface{})
-		pvcTags["labels"] = pvc.Labels
-		pvcTags["annotations"] = pvc.Annotations
-		pvcTags["namespace"] = pvc.Namespace
-		pvcTags["name"] = pvc.Name
-		pvcTags["phase"] = pvc.Status.Phase
-		availableTags["persistentvolumeclaim"] = pvcTags
+		related.Namespace, err = av.objectGetter.GetNamespace(pvc.Namespace)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	related.Workload, err = getClaimWorkload(av.objectGetter, pvc)
+	if err != nil {
+		return nil, err
+	}
+
+	if storageClassName := getPersistentVolumeStorageClassName(av.persistentVolume); storageClassName != "" {
+		related.StorageClass, err = av.objectGetter.GetStorageClass(storageClassName)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	return availableTags, nil
+	return NewPersistentVolumeTagValues(av.Platform(), av.persistentVolume, pvc, related), nil
 }
 
 // GetActualTags Get actual tags.
 func (av *AWSVolume) GetActualTags() ([]*tags.Tag, error) {
 	av.log.Info("Get actual tags on resource")
 
-	return av.prcl.GetActualTagsFromPersistentVolume(av.persistentVolume)
+	return av.prcl.GetActualTagsFromPersistentVolume(av.ctx, av.persistentVolume)
 }
 
 // ManageTags Manage tags on resource.
@@ -117,7 +132,7 @@ func (av *AWSVolume) ManageTags(delta *tags.TagDelta) error { // nolint: dupl //
 	// Check if tags needs to be added
 	if len(delta.AddList) > 0 {
 		av.log.WithField("delta", delta).Debug("Add list detected. Begin request to AWS.")
-		err := av.prcl.AddTagsFromPersistentVolume(av.persistentVolume, delta.AddList)
+		err := av.prcl.AddTagsFromPersistentVolume(av.ctx, av.persistentVolume, delta.AddList)
 		// Check error
 		if err != nil {
 			return err
@@ -131,7 +146,7 @@ func (av *AWSVolume) ManageTags(delta *tags.TagDelta) error { // nolint: dupl //
 	// Check if tags needs to be removed
 	if len(delta.DeleteList) > 0 {
 		av.log.WithField("delta", delta).Debug("Delete list detected. Begin request to AWS.")
-		err := av.prcl.DeleteTagsFromPersistentVolume(av.persistentVolume, delta.DeleteList)
+		err := av.prcl.DeleteTagsFromPersistentVolume(av.ctx, av.persistentVolume, delta.DeleteList)
 		// Check error
 		if err != nil {
 			return err
@@ -0,0 +1,87 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	providerclient "github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/providerClient"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ErrClusterFactsDiscovery Some cluster facts cannot be discovered.
+var ErrClusterFactsDiscovery = errors.New("cannot discover cluster facts")
+
+// ClusterFacts Cluster facts discovered from Kubernetes API and provider.
+type ClusterFacts struct {
+	// UID of the kube-system namespace
+	ID                string `json:"id"`
+	KubernetesVersion string `json:"kubernetesVersion"`
+	Region            string `json:"region"`
+	AccountID         string `json:"accountId"`
+}
+
+// DiscoverClusterFacts Discover cluster facts.
+// Each fact is discovered even when another one fails, facts found are returned with errors.
+// Provider facts aren't discovered without provider client.
+func DiscoverClusterFacts(
+	ctx context.Context,
+	k8sClient kubernetes.Interface,
+	objectGetter ObjectGetter,
+	prcl providerclient.ProviderClient,
+) (*ClusterFacts, error) {
+	facts := &ClusterFacts{}
+	errs := make([]string, 0)
+
+	version, err := k8sClient.Discovery().ServerVersion()
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("kubernetes version: %v", err))
+	} else {
+		facts.KubernetesVersion = version.GitVersion
+	}
+
+	// kube-system namespace is never deleted, its UID identifies the cluster
+	ns, err := objectGetter.GetNamespace(metav1.NamespaceSystem)
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("cluster id: %v", err))
+	} else if ns != nil {
+		facts.ID = string(ns.UID)
+	}
+
+	if prcl != nil {
+		account, err := prcl.GetAccount(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("account: %v", err))
+		} else {
+			facts.Region = account.Region
+			facts.AccountID = account.ID
+		}
+	}
+
+	if len(errs) != 0 {
+		return facts, fmt.Errorf("%w: %s", ErrClusterFactsDiscovery, strings.Join(errs, ", "))
+	}
+
+	return facts, nil
+}
+
+// AddClusterTagValues Add cluster tag values from discovered facts and static values.
+// Static values override discovered facts with the same key.
+func AddClusterTagValues(availableTags map[string]interface{}, staticValues map[string]string, facts *ClusterFacts) {
+	clusterTags := make(map[string]interface{})
+
+	if facts != nil {
+		clusterTags["id"] = facts.ID
+		clusterTags["kubernetesversion"] = facts.KubernetesVersion
+		clusterTags["region"] = facts.Region
+		clusterTags["accountid"] = facts.AccountID
+	}
+
+	for key, value := range staticValues {
+		clusterTags[key] = value
+	}
+
+	availableTags["cluster"] = clusterTags
+}
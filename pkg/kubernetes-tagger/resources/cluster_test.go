@@ -0,0 +1,90 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	providerclient "github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/providerClient"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	testclient "k8s.io/client-go/kubernetes/fake"
+)
+
+type fakeAccountProviderClient struct {
+	providerclient.ProviderClient
+	account *providerclient.Account
+	err     error
+}
+
+func (fapc *fakeAccountProviderClient) GetAccount(_ context.Context) (*providerclient.Account, error) {
+	return fapc.account, fapc.err
+}
+
+// fakeNamespaceErrorGetter Object getter failing to get namespaces.
+type fakeNamespaceErrorGetter struct {
+	ObjectGetter
+}
+
+func (fneg *fakeNamespaceErrorGetter) GetNamespace(_ string) (*v1.Namespace, error) {
+	return nil, errors.New("forbidden")
+}
+
+func TestDiscoverClusterFacts(t *testing.T) {
+	ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: metav1.NamespaceSystem, UID: "0f4c6c2e-1b7e-4d1a-9c3e-3a2b1c0d9e8f"}}
+	k8sClient := testclient.NewSimpleClientset(ns)
+	k8sClient.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: "v1.23.1"}
+	prcl := &fakeAccountProviderClient{account: &providerclient.Account{ID: "123456789012", Region: "eu-west-1"}}
+
+	facts, err := DiscoverClusterFacts(context.Background(), k8sClient, NewClientObjectGetter(context.Background(), k8sClient), prcl)
+	assert.Nil(t, err)
+	assert.Equal(t, &ClusterFacts{
+		ID:                "0f4c6c2e-1b7e-4d1a-9c3e-3a2b1c0d9e8f",
+		KubernetesVersion: "v1.23.1",
+		Region:            "eu-west-1",
+		AccountID:         "123456789012",
+	}, facts)
+
+	// Facts found are kept on provider error
+	prcl = &fakeAccountProviderClient{err: errors.New("access denied")}
+
+	facts, err = DiscoverClusterFacts(context.Background(), k8sClient, NewClientObjectGetter(context.Background(), k8sClient), prcl)
+	assert.EqualError(t, err, "cannot discover cluster facts: account: access denied")
+	assert.Equal(t, &ClusterFacts{ID: "0f4c6c2e-1b7e-4d1a-9c3e-3a2b1c0d9e8f", KubernetesVersion: "v1.23.1"}, facts)
+
+	// Facts after a failed one are discovered
+	failingClient := testclient.NewSimpleClientset()
+	failingClient.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: "v1.23.1"}
+	prcl = &fakeAccountProviderClient{account: &providerclient.Account{ID: "123456789012", Region: "eu-west-1"}}
+
+	facts, err = DiscoverClusterFacts(context.Background(), failingClient, &fakeNamespaceErrorGetter{}, prcl)
+	assert.True(t, errors.Is(err, ErrClusterFactsDiscovery))
+	assert.Equal(t, &ClusterFacts{KubernetesVersion: "v1.23.1", Region: "eu-west-1", AccountID: "123456789012"}, facts)
+
+	// Provider facts aren't discovered without provider client
+	facts, err = DiscoverClusterFacts(context.Background(), k8sClient, NewClientObjectGetter(context.Background(), k8sClient), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, &ClusterFacts{ID: "0f4c6c2e-1b7e-4d1a-9c3e-3a2b1c0d9e8f", KubernetesVersion: "v1.23.1"}, facts)
+}
+
+func TestAddClusterTagValues(t *testing.T) {
+	availableTags := make(map[string]interface{})
+	facts := &ClusterFacts{ID: "id", KubernetesVersion: "v1.23.1", Region: "eu-west-1", AccountID: "123456789012"}
+
+	// Static values override facts
+	AddClusterTagValues(availableTags, map[string]string{"environment": "production", "region": "eu"}, facts)
+	assert.Equal(t, map[string]interface{}{
+		"id":                "id",
+		"kubernetesversion": "v1.23.1",
+		"region":            "eu",
+		"accountid":         "123456789012",
+		"environment":       "production",
+	}, availableTags["cluster"])
+
+	// Root exists without facts and values
+	AddClusterTagValues(availableTags, nil, nil)
+	assert.Equal(t, map[string]interface{}{}, availableTags["cluster"])
+}
@@ -0,0 +1,86 @@
+package resources
+
+import (
+	"fmt"
+	"strings"
+)
+
+// dataStructureDocumentationHeader Header of the generated data structure documentation.
+const dataStructureDocumentationHeader = `# Data Structure
+
+<!-- Generated from resources.AvailableTagValuesSchema, run "make docs" to update it -->
+
+This page will show the available data structure for queries or conditions.
+`
+
+// GenerateDataStructureDocumentation Generate data structure documentation in Markdown from schema.
+func GenerateDataStructureDocumentation() string {
+	var sb strings.Builder
+
+	sb.WriteString(dataStructureDocumentationHeader)
+	writeDocumentationSection(&sb, "Root", "", AvailableTagValuesSchema)
+
+	return sb.String()
+}
+
+// writeDocumentationSection Write section table and sections of object children after it.
+func writeDocumentationSection(sb *strings.Builder, title, notes string, fields []*SchemaField) {
+	sb.WriteString("\n## " + title + "\n\n")
+
+	if notes != "" {
+		sb.WriteString(notes + "\n\n")
+	}
+
+	rows := [][]string{{"Key", "Description"}}
+
+	for _, field := range fields {
+		description := field.Description
+		if field.Section != "" {
+			description = fmt.Sprintf("[%s](#%s) (%s)", field.Section, strings.ToLower(field.Section), field.Description)
+		}
+
+		rows = append(rows, []string{field.Key, description})
+	}
+
+	writeMarkdownTable(sb, rows)
+
+	for _, field := range fields {
+		if field.Section != "" {
+			writeDocumentationSection(sb, field.Section, field.Notes, field.Children)
+		}
+	}
+}
+
+// writeMarkdownTable Write table with aligned columns, first row is the header.
+func writeMarkdownTable(sb *strings.Builder, rows [][]string) {
+	widths := make([]int, len(rows[0]))
+
+	for _, row := range rows {
+		for i, cell := range row {
+			if len(cell) > widths[i] {
+				widths[i] = len(cell)
+			}
+		}
+	}
+
+	writeRow := func(row []string) {
+		for i, cell := range row {
+			sb.WriteString("| " + cell + strings.Repeat(" ", widths[i]-len(cell)) + " ")
+		}
+
+		sb.WriteString("|\n")
+	}
+
+	writeRow(rows[0])
+
+	separator := make([]string, len(widths))
+	for i, width := range widths {
+		separator[i] = strings.Repeat("-", width)
+	}
+
+	writeRow(separator)
+
+	for _, row := range rows[1:] {
+		writeRow(row)
+	}
+}
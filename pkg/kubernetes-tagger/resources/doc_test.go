@@ -0,0 +1,37 @@
+package resources
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const dataStructureDocumentationPath = "../../../docs/data-structure.md"
+
+// TestDataStructureDocumentation Check that documentation is in sync with schema.
+// Run with UPDATE_DOCS=true to update documentation.
+func TestDataStructureDocumentation(t *testing.T) {
+	generated := GenerateDataStructureDocumentation()
+
+	if os.Getenv("UPDATE_DOCS") == "true" {
+		err := ioutil.WriteFile(dataStructureDocumentationPath, []byte(generated), 0644) // nolint: gosec // Documentation file
+		assert.Nil(t, err)
+
+		return
+	}
+
+	content, err := ioutil.ReadFile(dataStructureDocumentationPath)
+	assert.Nil(t, err)
+	assert.Equal(t, string(content), generated, "docs/data-structure.md is out of date, run \"make docs\"")
+}
+
+func TestDataStructureDocumentationSectionsForAllObjects(t *testing.T) {
+	// Objects without section wouldn't be documented
+	for _, field := range AvailableTagValuesSchema {
+		if len(field.Children) != 0 {
+			assert.NotEmpty(t, field.Section, field.Key)
+		}
+	}
+}
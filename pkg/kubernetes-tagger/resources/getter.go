@@ -0,0 +1,277 @@
+package resources
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	storagelisters "k8s.io/client-go/listers/storage/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// PodClaimIndex Name of the pods informer index by persistent volume claim.
+const PodClaimIndex = "claim"
+
+// ObjectGetter Get Kubernetes objects linked to resources.
+// Objects not found are returned as nil without error.
+type ObjectGetter interface {
+	GetNamespace(name string) (*v1.Namespace, error)
+	// GetClaimPods Get pods mounting a persistent volume claim.
+	GetClaimPods(namespace, claimName string) ([]*v1.Pod, error)
+	// GetSelectorPods Get pods matching a label selector in a namespace.
+	GetSelectorPods(namespace string, selector labels.Selector) ([]*v1.Pod, error)
+	// GetOwner Get owner object, nil is returned when owner kind isn't supported.
+	GetOwner(namespace string, ownerRef *metav1.OwnerReference) (metav1.Object, error)
+	GetStorageClass(name string) (*storagev1.StorageClass, error)
+}
+
+// PodClaimIndexFunc Index pods by namespace and name of mounted persistent volume claims.
+func PodClaimIndexFunc(obj interface{}) ([]string, error) {
+	pod, ok := obj.(*v1.Pod)
+	if !ok {
+		return []string{}, nil
+	}
+
+	return getPodClaimKeys(pod), nil
+}
+
+func getPodClaimKeys(pod *v1.Pod) []string {
+	keys := make([]string, 0)
+
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim != nil {
+			keys = append(keys, pod.Namespace+"/"+volume.PersistentVolumeClaim.ClaimName)
+		}
+	}
+
+	return keys
+}
+
+// clientObjectGetter Object getter using Kubernetes API.
+type clientObjectGetter struct {
+	ctx       context.Context
+	k8sClient kubernetes.Interface
+}
+
+// NewClientObjectGetter New object getter calling Kubernetes API on each get.
+// This is used when no informer cache is available (like in CLI commands).
+func NewClientObjectGetter(ctx context.Context, k8sClient kubernetes.Interface) ObjectGetter {
+	return &clientObjectGetter{ctx: ctx, k8sClient: k8sClient}
+}
+
+// GetNamespace Get namespace.
+func (cog *clientObjectGetter) GetNamespace(name string) (*v1.Namespace, error) {
+	ns, err := cog.k8sClient.CoreV1().Namespaces().Get(cog.ctx, name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return nil, nil // nolint: nilnil // Not found isn't an error
+	}
+
+	return ns, err
+}
+
+// GetClaimPods Get pods mounting a persistent volume claim.
+func (cog *clientObjectGetter) GetClaimPods(namespace, claimName string) ([]*v1.Pod, error) {
+	podList, err := cog.k8sClient.CoreV1().Pods(namespace).List(cog.ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	pods := make([]*v1.Pod, 0)
+
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+
+		for _, key := range getPodClaimKeys(pod) {
+			if key == namespace+"/"+claimName {
+				pods = append(pods, pod)
+
+				break
+			}
+		}
+	}
+
+	return pods, nil
+}
+
+// GetSelectorPods Get pods matching a label selector in a namespace.
+func (cog *clientObjectGetter) GetSelectorPods(namespace string, selector labels.Selector) ([]*v1.Pod, error) {
+	podList, err := cog.k8sClient.CoreV1().Pods(namespace).List(cog.ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+
+	pods := make([]*v1.Pod, 0, len(podList.Items))
+	for i := range podList.Items {
+		pods = append(pods, &podList.Items[i])
+	}
+
+	return pods, nil
+}
+
+// GetOwner Get owner object.
+func (cog *clientObjectGetter) GetOwner(namespace string, ownerRef *metav1.OwnerReference) (metav1.Object, error) {
+	var (
+		owner metav1.Object
+		err   error
+	)
+
+	ctx := cog.ctx
+	opts := metav1.GetOptions{}
+
+	switch ownerRef.Kind {
+	case "ReplicaSet":
+		owner, err = cog.k8sClient.AppsV1().ReplicaSets(namespace).Get(ctx, ownerRef.Name, opts)
+	case "Deployment":
+		owner, err = cog.k8sClient.AppsV1().Deployments(namespace).Get(ctx, ownerRef.Name, opts)
+	case "StatefulSet":
+		owner, err = cog.k8sClient.AppsV1().StatefulSets(namespace).Get(ctx, ownerRef.Name, opts)
+	case "DaemonSet":
+		owner, err = cog.k8sClient.AppsV1().DaemonSets(namespace).Get(ctx, ownerRef.Name, opts)
+	case "Job":
+		owner, err = cog.k8sClient.BatchV1().Jobs(namespace).Get(ctx, ownerRef.Name, opts)
+	case "CronJob":
+		owner, err = cog.k8sClient.BatchV1().CronJobs(namespace).Get(ctx, ownerRef.Name, opts)
+	case "ReplicationController":
+		owner, err = cog.k8sClient.CoreV1().ReplicationControllers(namespace).Get(ctx, ownerRef.Name, opts)
+	default:
+		return nil, nil // nolint: nilnil // Kind not supported
+	}
+
+	if k8serrors.IsNotFound(err) {
+		return nil, nil // nolint: nilnil // Not found isn't an error
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return owner, nil
+}
+
+// GetStorageClass Get storage class.
+func (cog *clientObjectGetter) GetStorageClass(name string) (*storagev1.StorageClass, error) {
+	sc, err := cog.k8sClient.StorageV1().StorageClasses().Get(cog.ctx, name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return nil, nil // nolint: nilnil // Not found isn't an error
+	}
+
+	return sc, err
+}
+
+// listerObjectGetter Object getter using informers caches.
+type listerObjectGetter struct {
+	*clientObjectGetter
+	namespaceLister corelisters.NamespaceLister
+	// Pod indexers by namespace, metav1.NamespaceAll for a cluster wide one
+	podIndexers        map[string]cache.Indexer
+	storageClassLister storagelisters.StorageClassLister
+	ownerCache         *OwnerCache
+}
+
+// NewListerObjectGetter New object getter reading informers caches.
+// Pod indexers are given by watched namespace (metav1.NamespaceAll for a cluster wide one)
+// and must have the PodClaimIndex index. Pods of other namespaces aren't found.
+// Owners are got from Kubernetes API and kept in owner cache.
+func NewListerObjectGetter(
+	ctx context.Context,
+	k8sClient kubernetes.Interface,
+	namespaceLister corelisters.NamespaceLister,
+	podIndexers map[string]cache.Indexer,
+	storageClassLister storagelisters.StorageClassLister,
+	ownerCache *OwnerCache,
+) ObjectGetter {
+	return &listerObjectGetter{
+		clientObjectGetter: &clientObjectGetter{ctx: ctx, k8sClient: k8sClient},
+		namespaceLister:    namespaceLister,
+		podIndexers:        podIndexers,
+		storageClassLister: storageClassLister,
+		ownerCache:         ownerCache,
+	}
+}
+
+// GetNamespace Get namespace.
+func (lg *listerObjectGetter) GetNamespace(name string) (*v1.Namespace, error) {
+	ns, err := lg.namespaceLister.Get(name)
+	if k8serrors.IsNotFound(err) {
+		return nil, nil // nolint: nilnil // Not found isn't an error
+	}
+
+	return ns, err
+}
+
+// getPodIndexer Get pod indexer of a namespace, nil when namespace pods aren't watched.
+func (lg *listerObjectGetter) getPodIndexer(namespace string) cache.Indexer {
+	if podIndexer, ok := lg.podIndexers[namespace]; ok {
+		return podIndexer
+	}
+
+	return lg.podIndexers[metav1.NamespaceAll]
+}
+
+// GetClaimPods Get pods mounting a persistent volume claim.
+func (lg *listerObjectGetter) GetClaimPods(namespace, claimName string) ([]*v1.Pod, error) {
+	pods := make([]*v1.Pod, 0)
+
+	podIndexer := lg.getPodIndexer(namespace)
+	if podIndexer == nil {
+		return pods, nil
+	}
+
+	objs, err := podIndexer.ByIndex(PodClaimIndex, namespace+"/"+claimName)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, obj := range objs {
+		if pod, ok := obj.(*v1.Pod); ok {
+			pods = append(pods, pod)
+		}
+	}
+
+	return pods, nil
+}
+
+// GetSelectorPods Get pods matching a label selector in a namespace.
+func (lg *listerObjectGetter) GetSelectorPods(namespace string, selector labels.Selector) ([]*v1.Pod, error) {
+	podIndexer := lg.getPodIndexer(namespace)
+	if podIndexer == nil {
+		return []*v1.Pod{}, nil
+	}
+
+	return corelisters.NewPodLister(podIndexer).Pods(namespace).List(selector)
+}
+
+// GetOwner Get owner object from owner cache or from Kubernetes API.
+func (lg *listerObjectGetter) GetOwner(namespace string, ownerRef *metav1.OwnerReference) (metav1.Object, error) {
+	if lg.ownerCache == nil {
+		return lg.clientObjectGetter.GetOwner(namespace, ownerRef)
+	}
+
+	if owner, ok := lg.ownerCache.get(namespace, ownerRef); ok {
+		return owner, nil
+	}
+
+	owner, err := lg.clientObjectGetter.GetOwner(namespace, ownerRef)
+	if err != nil {
+		return nil, err
+	}
+
+	lg.ownerCache.set(namespace, ownerRef, owner)
+
+	return owner, nil
+}
+
+// GetStorageClass Get storage class.
+func (lg *listerObjectGetter) GetStorageClass(name string) (*storagev1.StorageClass, error) {
+	sc, err := lg.storageClassLister.Get(name)
+	if k8serrors.IsNotFound(err) {
+		return nil, nil // nolint: nilnil // Not found isn't an error
+	}
+
+	return sc, err
+}
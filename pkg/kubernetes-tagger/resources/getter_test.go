@@ -0,0 +1,167 @@
+package resources
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	testclient "k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	storagelisters "k8s.io/client-go/listers/storage/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func newTestObjectGetters(objects ...runtime.Object) map[string]ObjectGetter {
+	namespaceIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	podIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{PodClaimIndex: PodClaimIndexFunc})
+	storageClassIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+
+	for _, obj := range objects {
+		switch o := obj.(type) {
+		case *v1.Namespace:
+			_ = namespaceIndexer.Add(o)
+		case *v1.Pod:
+			_ = podIndexer.Add(o)
+		case *storagev1.StorageClass:
+			_ = storageClassIndexer.Add(o)
+		}
+	}
+
+	k8sClient := testclient.NewSimpleClientset(objects...)
+
+	return map[string]ObjectGetter{
+		"client": NewClientObjectGetter(context.Background(), k8sClient),
+		"lister": NewListerObjectGetter(
+			context.Background(),
+			k8sClient,
+			corelisters.NewNamespaceLister(namespaceIndexer),
+			map[string]cache.Indexer{metav1.NamespaceAll: podIndexer},
+			storagelisters.NewStorageClassLister(storageClassIndexer),
+			NewOwnerCache(time.Minute),
+		),
+	}
+}
+
+func TestObjectGetter_GetNamespace(t *testing.T) {
+	ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default", Labels: map[string]string{"cost-center": "1234"}}}
+
+	for name, getter := range newTestObjectGetters(ns) {
+		t.Run(name, func(t *testing.T) {
+			res, err := getter.GetNamespace("default")
+			assert.Nil(t, err)
+			assert.Equal(t, ns, res)
+
+			// Not found namespace isn't an error
+			res, err = getter.GetNamespace("other")
+			assert.Nil(t, err)
+			assert.Nil(t, res)
+		})
+	}
+}
+
+func TestObjectGetter_GetPods(t *testing.T) {
+	pod1 := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "default", Labels: map[string]string{"app": "db"}},
+		Spec: v1.PodSpec{Volumes: []v1.Volume{{
+			Name:         "data",
+			VolumeSource: v1.VolumeSource{PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: "data-db-0"}},
+		}}},
+	}
+	pod2 := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Labels: map[string]string{"app": "web"}}}
+
+	for name, getter := range newTestObjectGetters(pod1, pod2) {
+		t.Run(name, func(t *testing.T) {
+			res, err := getter.GetClaimPods("default", "data-db-0")
+			assert.Nil(t, err)
+			assert.Equal(t, []*v1.Pod{pod1}, res)
+
+			res, err = getter.GetClaimPods("other", "data-db-0")
+			assert.Nil(t, err)
+			assert.Empty(t, res)
+
+			res, err = getter.GetSelectorPods("default", labels.SelectorFromSet(map[string]string{"app": "web"}))
+			assert.Nil(t, err)
+			assert.Equal(t, []*v1.Pod{pod2}, res)
+		})
+	}
+}
+
+func TestObjectGetter_GetOwner(t *testing.T) {
+	sts := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"}}
+
+	for name, getter := range newTestObjectGetters(sts) {
+		t.Run(name, func(t *testing.T) {
+			res, err := getter.GetOwner("default", &metav1.OwnerReference{Kind: "StatefulSet", Name: "db"})
+			assert.Nil(t, err)
+			assert.Equal(t, sts, res)
+
+			// Not found owner isn't an error
+			res, err = getter.GetOwner("default", &metav1.OwnerReference{Kind: "Deployment", Name: "db"})
+			assert.Nil(t, err)
+			assert.Nil(t, res)
+
+			// Kind not supported
+			res, err = getter.GetOwner("default", &metav1.OwnerReference{Kind: "Rollout", Name: "db"})
+			assert.Nil(t, err)
+			assert.Nil(t, res)
+		})
+	}
+}
+
+func TestListerObjectGetter_NamespacedPods(t *testing.T) {
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "excluded", Labels: map[string]string{"app": "web"}}}
+	podIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{PodClaimIndex: PodClaimIndexFunc})
+	_ = podIndexer.Add(pod)
+
+	// Only default namespace pods are watched
+	getter := NewListerObjectGetter(context.Background(), testclient.NewSimpleClientset(pod), nil,
+		map[string]cache.Indexer{"default": cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{PodClaimIndex: PodClaimIndexFunc})},
+		nil, nil)
+
+	res, err := getter.GetSelectorPods("excluded", labels.SelectorFromSet(map[string]string{"app": "web"}))
+	assert.Nil(t, err)
+	assert.Empty(t, res)
+
+	res, err = getter.GetClaimPods("excluded", "data")
+	assert.Nil(t, err)
+	assert.Empty(t, res)
+}
+
+func TestListerObjectGetter_GetOwnerCache(t *testing.T) {
+	sts := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default", UID: "uid1"}}
+	k8sClient := testclient.NewSimpleClientset(sts)
+	ownerCache := NewOwnerCache(time.Minute)
+	getter := NewListerObjectGetter(context.Background(), k8sClient, nil, nil, nil, ownerCache)
+	ownerRef := &metav1.OwnerReference{Kind: "StatefulSet", Name: "db", UID: "uid1"}
+
+	res, err := getter.GetOwner("default", ownerRef)
+	assert.Nil(t, err)
+	assert.Equal(t, sts, res)
+
+	// Owner is served from cache
+	err = k8sClient.AppsV1().StatefulSets("default").Delete(context.Background(), "db", metav1.DeleteOptions{})
+	assert.Nil(t, err)
+
+	res, err = getter.GetOwner("default", ownerRef)
+	assert.Nil(t, err)
+	assert.Equal(t, sts, res)
+
+	// Owner recreated with another UID isn't served from cache
+	res, err = getter.GetOwner("default", &metav1.OwnerReference{Kind: "StatefulSet", Name: "db", UID: "uid2"})
+	assert.Nil(t, err)
+	assert.Nil(t, res)
+
+	// Expired owners are got again
+	ownerCache.ttl = 0
+
+	res, err = getter.GetOwner("default", ownerRef)
+	assert.Nil(t, err)
+	assert.Nil(t, res)
+}
@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"context"
+
 	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
 	providerclient "github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/providerClient"
 	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/tags"
@@ -12,13 +14,21 @@ import (
 type Resource interface {
 	Type() string
 	Platform() string
+	// Cloud resource ID, empty when unknown
+	ID() string
 	GetAvailableTagValues() (map[string]interface{}, error)
 	GetActualTags() ([]*tags.Tag, error)
 	ManageTags(delta *tags.TagDelta) error
 }
 
 // NewFromPersistentVolume New resource instance from persistent volume.
-func NewFromPersistentVolume(k8sClient kubernetes.Interface, pv *v1.PersistentVolume, cfg *config.Configuration) (Resource, error) {
+func NewFromPersistentVolume(
+	ctx context.Context,
+	k8sClient kubernetes.Interface,
+	objectGetter ObjectGetter,
+	pv *v1.PersistentVolume,
+	cfg *config.Configuration,
+) (Resource, error) {
 	// Check if AWS provider is enabled
 	if cfg.Provider == config.AWSProviderName {
 		// Create Provider client
@@ -28,7 +38,7 @@ func NewFromPersistentVolume(k8sClient kubernetes.Interface, pv *v1.PersistentVo
 		}
 		// Check if it is an aws volume resource
 		if isAWSVolumeResource(pv) {
-			res, err := newAWSVolume(k8sClient, pv, cfg, prcl)
+			res, err := newAWSVolume(ctx, k8sClient, objectGetter, pv, cfg, prcl)
 			if err != nil {
 				return nil, err
 			}
@@ -41,7 +51,13 @@ func NewFromPersistentVolume(k8sClient kubernetes.Interface, pv *v1.PersistentVo
 }
 
 // NewFromService New resource instance from service.
-func NewFromService(k8sClient kubernetes.Interface, svc *v1.Service, cfg *config.Configuration) (Resource, error) {
+func NewFromService(
+	ctx context.Context,
+	k8sClient kubernetes.Interface,
+	objectGetter ObjectGetter,
+	svc *v1.Service,
+	cfg *config.Configuration,
+) (Resource, error) {
 	// Check if AWS provider is enabled
 	if cfg.Provider == config.AWSProviderName {
 		// Create Provider client
@@ -51,7 +67,7 @@ func NewFromService(k8sClient kubernetes.Interface, svc *v1.Service, cfg *config
 		}
 		// Check if it is an aws volume resource
 		if isAWSLoadBalancerResource(svc) {
-			res, err := newAWSLoadBalancer(k8sClient, svc, cfg, prcl)
+			res, err := newAWSLoadBalancer(ctx, k8sClient, objectGetter, svc, cfg, prcl)
 			if err != nil {
 				return nil, err
 			}
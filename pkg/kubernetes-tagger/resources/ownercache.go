@@ -0,0 +1,64 @@
+package resources
+
+import (
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OwnerCache Owners got from Kubernetes API, shared by object getters of a watch.
+// Owners not found are cached too to avoid getting them on each run.
+type OwnerCache struct {
+	mutex     sync.Mutex
+	ttl       time.Duration
+	entries   map[string]*ownerCacheEntry
+	pruneTime time.Time
+}
+
+type ownerCacheEntry struct {
+	owner metav1.Object
+	time  time.Time
+}
+
+// NewOwnerCache New owner cache keeping owners during ttl.
+func NewOwnerCache(ttl time.Duration) *OwnerCache {
+	return &OwnerCache{ttl: ttl, entries: make(map[string]*ownerCacheEntry), pruneTime: time.Now()}
+}
+
+// getOwnerCacheKey Get cache key of owner, UID is used to not serve a deleted owner recreated with the same name.
+func getOwnerCacheKey(namespace string, ownerRef *metav1.OwnerReference) string {
+	return ownerRef.Kind + "/" + namespace + "/" + ownerRef.Name + "/" + string(ownerRef.UID)
+}
+
+func (oc *OwnerCache) get(namespace string, ownerRef *metav1.OwnerReference) (metav1.Object, bool) {
+	oc.mutex.Lock()
+	defer oc.mutex.Unlock()
+
+	entry, ok := oc.entries[getOwnerCacheKey(namespace, ownerRef)]
+	if !ok || time.Since(entry.time) >= oc.ttl {
+		return nil, false
+	}
+
+	return entry.owner, true
+}
+
+func (oc *OwnerCache) set(namespace string, ownerRef *metav1.OwnerReference, owner metav1.Object) {
+	oc.mutex.Lock()
+	defer oc.mutex.Unlock()
+
+	now := time.Now()
+
+	// Remove expired entries of deleted owners
+	if now.Sub(oc.pruneTime) >= oc.ttl {
+		for key, entry := range oc.entries {
+			if now.Sub(entry.time) >= oc.ttl {
+				delete(oc.entries, key)
+			}
+		}
+
+		oc.pruneTime = now
+	}
+
+	oc.entries[getOwnerCacheKey(namespace, ownerRef)] = &ownerCacheEntry{owner: owner, time: now}
+}
@@ -0,0 +1,115 @@
+package resources
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+// GetPersistentVolumeRelatedVersions Get resource versions of Kubernetes objects used in persistent volume tag values.
+// They change with these objects, without calling provider.
+func GetPersistentVolumeRelatedVersions(
+	ctx context.Context,
+	k8sClient kubernetes.Interface,
+	objectGetter ObjectGetter,
+	pv *v1.PersistentVolume,
+) (map[string]string, error) {
+	versions := make(map[string]string)
+
+	pvc, err := getPersistentVolumeClaim(ctx, pv, k8sClient)
+	if err != nil {
+		return nil, err
+	}
+
+	if pvc != nil {
+		versions["persistentvolumeclaim"] = pvc.ResourceVersion
+
+		err = addNamespaceVersion(versions, objectGetter, pvc.Namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		pods, err := objectGetter.GetClaimPods(pvc.Namespace, pvc.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		err = addWorkloadVersions(versions, objectGetter, pods)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if storageClassName := getPersistentVolumeStorageClassName(pv); storageClassName != "" {
+		sc, err := objectGetter.GetStorageClass(storageClassName)
+		if err != nil {
+			return nil, err
+		}
+
+		if sc != nil {
+			versions["storageclass"] = sc.ResourceVersion
+		}
+	}
+
+	return versions, nil
+}
+
+// GetServiceRelatedVersions Get resource versions of Kubernetes objects used in service tag values.
+// Load balancer values from provider aren't included.
+func GetServiceRelatedVersions(objectGetter ObjectGetter, svc *v1.Service) (map[string]string, error) {
+	versions := make(map[string]string)
+
+	err := addNamespaceVersion(versions, objectGetter, svc.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(svc.Spec.Selector) == 0 {
+		return versions, nil
+	}
+
+	pods, err := objectGetter.GetSelectorPods(svc.Namespace, labels.SelectorFromSet(svc.Spec.Selector))
+	if err != nil {
+		return nil, err
+	}
+
+	err = addWorkloadVersions(versions, objectGetter, pods)
+	if err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+func addNamespaceVersion(versions map[string]string, objectGetter ObjectGetter, name string) error {
+	ns, err := objectGetter.GetNamespace(name)
+	if err != nil {
+		return err
+	}
+
+	if ns != nil {
+		versions["namespace"] = ns.ResourceVersion
+	}
+
+	return nil
+}
+
+// addWorkloadVersions Add versions of pods, they choose the workload, and of workload owners.
+func addWorkloadVersions(versions map[string]string, objectGetter ObjectGetter, pods []*v1.Pod) error {
+	for _, pod := range pods {
+		versions["pod/"+pod.Name] = pod.ResourceVersion
+	}
+
+	_, owners, err := getPodsWorkloadWithOwners(objectGetter, pods)
+	if err != nil {
+		return err
+	}
+
+	for _, owner := range owners {
+		versions["owner/"+string(owner.GetUID())] = owner.GetResourceVersion()
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package resources
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclient "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetPersistentVolumeRelatedVersions(t *testing.T) {
+	ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default", ResourceVersion: "1"}}
+	pvc := &v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "default", ResourceVersion: "2"}}
+	sc := &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "gp2", ResourceVersion: "3"}}
+	statefulSet := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default", UID: "db-uid", ResourceVersion: "4"}}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "default", ResourceVersion: "5", OwnerReferences: controllerRef("StatefulSet", "db")},
+		Spec: v1.PodSpec{Volumes: []v1.Volume{{
+			Name:         "data",
+			VolumeSource: v1.VolumeSource{PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: "data"}},
+		}}},
+	}
+	k8sClient := testclient.NewSimpleClientset(ns, pvc, sc, statefulSet, pod)
+	pv := &v1.PersistentVolume{Spec: v1.PersistentVolumeSpec{
+		StorageClassName: "gp2",
+		ClaimRef:         &v1.ObjectReference{Namespace: "default", Name: "data"},
+	}}
+
+	res, err := GetPersistentVolumeRelatedVersions(context.Background(), k8sClient, NewClientObjectGetter(context.Background(), k8sClient), pv)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{
+		"persistentvolumeclaim": "2",
+		"namespace":             "1",
+		"storageclass":          "3",
+		"pod/db-0":              "5",
+		"owner/db-uid":          "4",
+	}, res)
+
+	// Unbound persistent volume
+	res, err = GetPersistentVolumeRelatedVersions(context.Background(), k8sClient, NewClientObjectGetter(context.Background(), k8sClient), &v1.PersistentVolume{})
+	assert.Nil(t, err)
+	assert.Empty(t, res)
+}
+
+func TestGetServiceRelatedVersions(t *testing.T) {
+	ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default", ResourceVersion: "1"}}
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name: "web", Namespace: "default", ResourceVersion: "2", Labels: map[string]string{"app": "web"},
+	}}
+	getter := NewClientObjectGetter(context.Background(), testclient.NewSimpleClientset(ns, pod))
+
+	res, err := GetServiceRelatedVersions(getter, &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       v1.ServiceSpec{Selector: map[string]string{"app": "web"}},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"namespace": "1", "pod/web": "2"}, res)
+
+	// Service without selector
+	res, err = GetServiceRelatedVersions(getter, &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"namespace": "1"}, res)
+}
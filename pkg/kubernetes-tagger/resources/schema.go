@@ -0,0 +1,246 @@
+package resources
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SchemaField Field of the available tag values data structure.
+type SchemaField struct {
+	Key         string
+	Description string
+	// Children fields when the field is an object
+	Children []*SchemaField
+	// DynamicKeys is set when the field is a map with any key (like labels)
+	DynamicKeys bool
+	// List is set when the field is a list of objects described by children
+	List bool
+	// Section title of the object in documentation
+	Section string
+	// Notes added under the section title in documentation
+	Notes string
+}
+
+// AvailableTagValuesSchema Data structure of available tag values for all resources.
+// Documentation in docs/data-structure.md is generated from it.
+var AvailableTagValuesSchema = []*SchemaField{
+	{Key: "type", Description: "Resource type (for example: \"volume\")"},
+	{Key: "platform", Description: "Resource platform (for example: \"aws\")"},
+	{
+		Key:         "persistentvolume",
+		Section:     "PersistentVolumeStructure",
+		Description: "Only if the resource is a persistent volume",
+		Children: []*SchemaField{
+			{Key: "labels", Description: "This is the `map[string]string` got from `labels` in the Kubernetes PersistentVolume Kind", DynamicKeys: true},
+			{Key: "annotations", Description: "This is the `map[string]string` got from `annotations` in the Kubernetes PersistentVolume Kind", DynamicKeys: true},
+			{Key: "name", Description: "The PersistentVolume name"},
+			{Key: "phase", Description: "The PersistentVolume status phase"},
+			{Key: "reclaimpolicy", Description: "The PersistentVolume Spec Reclaim Policy"},
+			{Key: "storageclassname", Description: "The PersistentVolume storage class name (from the field or the `volume.beta.kubernetes.io/storage-class` annotation)"},
+			{Key: "capacity", Description: "The PersistentVolume storage capacity (for example: \"10Gi\")"},
+			{Key: "accessmodes", Description: "The PersistentVolume access modes list (for example: `[\"ReadWriteOnce\"]`)"},
+			{Key: "volumemode", Description: "The PersistentVolume volume mode (\"Filesystem\" or \"Block\")"},
+			{Key: "mountoptions", Description: "The PersistentVolume mount options list"},
+			{
+				Key:         "nodeaffinity",
+				Description: "This is the `map[string]string` of values of required node affinity `In` expressions by key (values joined with a comma)",
+				DynamicKeys: true,
+			},
+			{Key: "zone", Description: "The PersistentVolume topology zone from `topology.kubernetes.io/zone` or `failure-domain.beta.kubernetes.io/zone` labels or node affinity"},
+			{Key: "creationtimestamp", Description: "The PersistentVolume creation timestamp (RFC 3339 format)"},
+		},
+	},
+	{
+		Key:         "persistentvolumeclaim",
+		Section:     "PersistentVolumeClaimStructure",
+		Description: "Only when a persistent volume claim is linked to the persistent volume",
+		Children: []*SchemaField{
+			{Key: "labels", Description: "This is the `map[string]string` got from `labels` in the Kubernetes PersistentVolumeClaim Kind", DynamicKeys: true},
+			{Key: "annotations", Description: "This is the `map[string]string` got from `annotations` in the Kubernetes PersistentVolumeClaim Kind", DynamicKeys: true},
+			{Key: "namespace", Description: "The PersistentVolumeClaim namespace"},
+			{Key: "name", Description: "The PersistentVolumeClaim name"},
+			{Key: "phase", Description: "The PersistentVolumeClaim Status phase"},
+			{Key: "requestedstorage", Description: "The PersistentVolumeClaim requested storage (for example: \"10Gi\")"},
+			{Key: "creationtimestamp", Description: "The PersistentVolumeClaim creation timestamp (RFC 3339 format)"},
+		},
+	},
+	{
+		Key:         "storageclass",
+		Section:     "StorageClass",
+		Description: "Only when the storage class of the persistent volume exists",
+		Notes:       "Storage classes are read from an informer cache.",
+		Children: []*SchemaField{
+			{Key: "name", Description: "The StorageClass name"},
+			{Key: "provisioner", Description: "The StorageClass provisioner (for example: \"ebs.csi.aws.com\")"},
+			{Key: "parameters", Description: "This is the `map[string]string` got from `parameters` in the Kubernetes StorageClass Kind", DynamicKeys: true},
+			{Key: "labels", Description: "This is the `map[string]string` got from `labels` in the Kubernetes StorageClass Kind", DynamicKeys: true},
+			{Key: "annotations", Description: "This is the `map[string]string` got from `annotations` in the Kubernetes StorageClass Kind", DynamicKeys: true},
+		},
+	},
+	{
+		Key:         "service",
+		Section:     "Service",
+		Description: "Only if the resource is a service",
+		Children: []*SchemaField{
+			{Key: "name", Description: "The Service name"},
+			{Key: "namespace", Description: "The Service namespace"},
+			{Key: "labels", Description: "This is the `map[string]string` got from `labels` in the Kubernetes Service Kind", DynamicKeys: true},
+			{Key: "annotations", Description: "This is the `map[string]string` got from `annotations` in the Kubernetes Service Kind", DynamicKeys: true},
+			{Key: "type", Description: "The Service type (for example: \"LoadBalancer\")"},
+			{
+				Key:         "ports",
+				Section:     "ServicePort",
+				Description: "List of Service ports, select one with an index like `service.ports.0.port`",
+				List:        true,
+				Children: []*SchemaField{
+					{Key: "name", Description: "The port name"},
+					{Key: "protocol", Description: "The port protocol (for example: \"TCP\")"},
+					{Key: "port", Description: "The port exposed by the Service"},
+					{Key: "targetport", Description: "The target port number or name on pods"},
+					{Key: "nodeport", Description: "The node port, 0 when not allocated"},
+				},
+			},
+			{Key: "selector", Description: "This is the `map[string]string` got from `selector` in the Kubernetes Service Kind", DynamicKeys: true},
+			{Key: "externaltrafficpolicy", Description: "The Service external traffic policy (for example: \"Local\")"},
+			{Key: "loadbalancersourceranges", Description: "The `[]string` of CIDRs allowed to access the load balancer"},
+			{Key: "loadbalancerclass", Description: "The Service load balancer class, only when set"},
+			{
+				Key:         "loadbalancer",
+				Section:     "ServiceLoadBalancer",
+				Description: "Load balancer from Service status and provider",
+				Notes:       "Provider fields (`type`, `name`, `arn` and `scheme`) are only present when the load balancer has been found in the provider. When the load balancer isn't found yet, a warning is logged and other values are still available to rules.",
+				Children: []*SchemaField{
+					{Key: "hostname", Description: "The first hostname in Service load balancer status ingress"},
+					{Key: "ips", Description: "The `[]string` of IPs in Service load balancer status ingress"},
+					{Key: "type", Description: "The load balancer type: \"classic\", \"nlb\" or \"alb\""},
+					{Key: "name", Description: "The load balancer name"},
+					{Key: "arn", Description: "The load balancer ARN, empty for classic load balancers"},
+					{Key: "scheme", Description: "The load balancer scheme (for example: \"internet-facing\" or \"internal\")"},
+				},
+			},
+		},
+	},
+	{
+		Key:         "namespace",
+		Section:     "Namespace",
+		Description: "Namespace of the persistent volume claim or the service, only when it exists",
+		Notes:       "Namespaces are read from an informer cache, a namespace change is taken into account at the next resync (every minute by default).",
+		Children: []*SchemaField{
+			{Key: "name", Description: "The Namespace name"},
+			{Key: "labels", Description: "This is the `map[string]string` got from `labels` in the Kubernetes Namespace Kind", DynamicKeys: true},
+			{Key: "annotations", Description: "This is the `map[string]string` got from `annotations` in the Kubernetes Namespace Kind", DynamicKeys: true},
+		},
+	},
+	{
+		Key:         "workload",
+		Section:     "Workload",
+		Description: "Top level owner of pods mounting the persistent volume claim or matched by the service selector",
+		Notes: "Pods mounting the persistent volume claim (for volumes) or matched by the service selector (for load balancers) are read from an informer cache.\n" +
+			"When many pods are found, running pods are preferred and the first one by name is used.\n" +
+			"The workload is the top level controller of this pod found with `ownerReferences` (for example Pod -> ReplicaSet -> Deployment or Pod -> Job -> CronJob).\n" +
+			"Supported owner kinds are `ReplicaSet`, `Deployment`, `StatefulSet`, `DaemonSet`, `Job`, `CronJob` and `ReplicationController`. " +
+			"With another owner kind, the workload is this owner without labels. A pod without controller is its own workload.",
+		Children: []*SchemaField{
+			{Key: "kind", Description: "The workload kind (for example: \"StatefulSet\", \"Deployment\", \"CronJob\" or \"Pod\" when pod has no controller)"},
+			{Key: "name", Description: "The workload name"},
+			{Key: "labels", Description: "This is the `map[string]string` got from `labels` in the workload object", DynamicKeys: true},
+		},
+	},
+	{
+		Key:         "pod",
+		Section:     "Pod",
+		Description: "Pod used to find the workload",
+		Children: []*SchemaField{
+			{Key: "name", Description: "The Pod name"},
+			{Key: "labels", Description: "This is the `map[string]string` got from `labels` in the Kubernetes Pod Kind", DynamicKeys: true},
+		},
+	},
+	{
+		Key:         "cluster",
+		Section:     "Cluster",
+		Description: "Cluster metadata, always present",
+		Notes: "Facts are discovered on first use and refreshed every hour (every minute after a discovery error) " +
+			"and after configuration reloads. Facts that can't be discovered are empty, other ones are kept. " +
+			"Values of the `cluster` configuration block are added by key (in lower case) and override facts with the same key.",
+		DynamicKeys: true,
+		Children: []*SchemaField{
+			{Key: "id", Description: "The cluster ID (UID of the `kube-system` namespace)"},
+			{Key: "kubernetesversion", Description: "The Kubernetes API server version (for example: \"v1.23.1\")"},
+			{Key: "region", Description: "The provider region (for example: \"eu-west-1\")"},
+			{Key: "accountid", Description: "The provider account ID got from AWS STS"},
+		},
+	},
+}
+
+// Characters that make a query impossible to check against the schema.
+const unsupportedSchemaQueryCharacters = "*?#@|!"
+
+// IsKnownQuery Checks if a query can match the available tag values data structure.
+// Queries using wildcards, arrays or modifiers are considered as known.
+func IsKnownQuery(query string) bool {
+	if strings.ContainsAny(query, unsupportedSchemaQueryCharacters) {
+		return true
+	}
+
+	fields := AvailableTagValuesSchema
+	listIndexExpected := false
+
+	for _, part := range SplitQuery(query) {
+		// An index is needed to select an item in a list
+		if listIndexExpected {
+			if _, err := strconv.Atoi(part); err != nil {
+				return false
+			}
+
+			listIndexExpected = false
+
+			continue
+		}
+
+		var found *SchemaField
+
+		for _, field := range fields {
+			if field.Key == part {
+				found = field
+
+				break
+			}
+		}
+
+		if found == nil {
+			return false
+		}
+
+		// Any sub key can exist in a map
+		if found.DynamicKeys {
+			return true
+		}
+
+		fields = found.Children
+		listIndexExpected = found.List
+	}
+
+	return true
+}
+
+// SplitQuery Split a gjson query on dots that aren't escaped.
+func SplitQuery(query string) []string {
+	parts := make([]string, 0)
+
+	var current strings.Builder
+
+	for i := 0; i < len(query); i++ {
+		switch {
+		case query[i] == '\\' && i+1 < len(query):
+			i++
+			current.WriteByte(query[i])
+		case query[i] == '.':
+			parts = append(parts, current.String())
+			current.Reset()
+		default:
+			current.WriteByte(query[i])
+		}
+	}
+
+	return append(parts, current.String())
+}
@@ -0,0 +1,40 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsKnownQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"type", true},
+		{"persistentvolume", true},
+		{"persistentvolume.name", true},
+		{"persistentvolume.labels.app", true},
+		{"service.labels.app\\.kubernetes\\.io/name", true},
+		{"service.*", true},
+		{"service.ports", true},
+		{"service.ports.0.port", true},
+		{"service.ports.0", true},
+		{"service.ports.port", false},
+		{"service.ports.0.unknown", false},
+		{"service.loadbalancer.scheme", true},
+		{"unknown", false},
+		{"persistentvolume.unknown", false},
+		{"persistentvolume.name.unknown", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsKnownQuery(tt.query))
+		})
+	}
+}
+
+func TestSplitQuery(t *testing.T) {
+	assert.Equal(t, []string{"service", "labels", "app.kubernetes.io/name"}, SplitQuery("service.labels.app\\.kubernetes\\.io/name"))
+	assert.Equal(t, []string{"type"}, SplitQuery("type"))
+}
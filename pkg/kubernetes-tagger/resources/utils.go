@@ -18,13 +18,17 @@ const LoadBalancerResourceType = "loadbalancer"
 // AWSResourcePlatform AWS Resource Platform.
 const AWSResourcePlatform = "aws"
 
-func getPersistentVolumeClaim(persistentVolume *v1.PersistentVolume, k8sClient kubernetes.Interface) (*v1.PersistentVolumeClaim, error) {
+func getPersistentVolumeClaim(
+	ctx context.Context,
+	persistentVolume *v1.PersistentVolume,
+	k8sClient kubernetes.Interface,
+) (*v1.PersistentVolumeClaim, error) {
 	claimRef := persistentVolume.Spec.ClaimRef
 	if claimRef == nil {
 		return nil, nil // nolint: nilnil // No need
 	}
 
-	pvc, err := k8sClient.CoreV1().PersistentVolumeClaims(claimRef.Namespace).Get(context.TODO(), claimRef.Name, metav1.GetOptions{})
+	pvc, err := k8sClient.CoreV1().PersistentVolumeClaims(claimRef.Namespace).Get(ctx, claimRef.Name, metav1.GetOptions{})
 	if err != nil && !k8serrors.IsNotFound(err) {
 		return nil, err
 	}
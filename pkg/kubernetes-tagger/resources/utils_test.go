@@ -1,6 +1,7 @@
 package resources
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -15,7 +16,7 @@ func TestGetPersistentVolumeClaimWithoutClaimRef(t *testing.T) {
 	pv := v1.PersistentVolume{Spec: spec}
 
 	// Call code
-	res, err := getPersistentVolumeClaim(&pv, nil)
+	res, err := getPersistentVolumeClaim(context.Background(), &pv, nil)
 
 	assert.Nil(t, res)
 	assert.Nil(t, err)
@@ -29,7 +30,7 @@ func TestGetPersistentVolumeClaimWithClaimNotFound(t *testing.T) {
 	client := testclient.NewSimpleClientset()
 
 	// Call code
-	res, err := getPersistentVolumeClaim(&pv, client)
+	res, err := getPersistentVolumeClaim(context.Background(), &pv, client)
 
 	assert.Nil(t, res)
 	assert.Nil(t, err)
@@ -48,7 +49,7 @@ func TestGetPersistentVolumeClaimWithClaimFound(t *testing.T) {
 	client := testclient.NewSimpleClientset(pvc)
 
 	// Call code
-	res, err := getPersistentVolumeClaim(&pv, client)
+	res, err := getPersistentVolumeClaim(context.Background(), &pv, client)
 
 	assert.NotNil(t, res)
 	assert.Nil(t, err)
@@ -0,0 +1,313 @@
+package resources
+
+import (
+	"strings"
+	"time"
+
+	providerclient "github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/providerClient"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Topology zone keys in labels and node affinity, the first found is used.
+var zoneKeys = []string{v1.LabelTopologyZone, v1.LabelFailureDomainBetaZone}
+
+// betaStorageClassAnnotation Storage class annotation used before storageClassName field.
+const betaStorageClassAnnotation = "volume.beta.kubernetes.io/storage-class"
+
+// RelatedObjects Objects linked to a resource object used in available tag values.
+// All of them are optional.
+type RelatedObjects struct {
+	// Load balancer of the service from provider
+	LoadBalancer *providerclient.LoadBalancer
+	// Namespace of the persistent volume claim or the service
+	Namespace *v1.Namespace
+	// Workload of pods mounting the claim or matched by the service selector
+	Workload *Workload
+	// Storage class of the persistent volume
+	StorageClass *storagev1.StorageClass
+}
+
+// NewPersistentVolumeTagValues Build available tag values for a persistent volume.
+// Persistent volume claim can be nil when the persistent volume isn't bound.
+func NewPersistentVolumeTagValues(
+	platform string,
+	pv *v1.PersistentVolume,
+	pvc *v1.PersistentVolumeClaim,
+	related *RelatedObjects,
+) map[string]interface{} {
+	// Begin to create available tag values
+	availableTags := make(map[string]interface{})
+	availableTags["type"] = VolumeResourceType
+	availableTags["platform"] = platform
+	pvTags := make(map[string]interface{})
+	pvTags["labels"] = pv.Labels
+	pvTags["annotations"] = pv.Annotations
+	pvTags["name"] = pv.Name
+	pvTags["phase"] = pv.Status.Phase
+	pvTags["reclaimpolicy"] = pv.Spec.PersistentVolumeReclaimPolicy
+	pvTags["storageclassname"] = getPersistentVolumeStorageClassName(pv)
+	pvTags["capacity"] = getStorageQuantity(pv.Spec.Capacity)
+	pvTags["accessmodes"] = getAccessModes(pv.Spec.AccessModes)
+	pvTags["mountoptions"] = pv.Spec.MountOptions
+	pvTags["nodeaffinity"] = getNodeAffinity(pv)
+	pvTags["zone"] = getPersistentVolumeZone(pv)
+	pvTags["creationtimestamp"] = formatTimestamp(pv.CreationTimestamp)
+
+	if pv.Spec.VolumeMode != nil {
+		pvTags["volumemode"] = *pv.Spec.VolumeMode
+	}
+
+	availableTags["persistentvolume"] = pvTags
+
+	// If pvc exists, create tag values
+	if pvc != nil {
+		availableTags["persistentvolumeclaim"] = getPersistentVolumeClaimTagValues(pvc)
+	}
+
+	addRelatedObjectsTagValues(availableTags, related)
+
+	return availableTags
+}
+
+// NewPersistentVolumeClaimTagValues Build available tag values known before the persistent volume of a claim exists.
+// It is used to review claims on admission.
+func NewPersistentVolumeClaimTagValues(platform string, pvc *v1.PersistentVolumeClaim) map[string]interface{} {
+	availableTags := make(map[string]interface{})
+	availableTags["type"] = VolumeResourceType
+	availableTags["platform"] = platform
+	availableTags["persistentvolumeclaim"] = getPersistentVolumeClaimTagValues(pvc)
+
+	return availableTags
+}
+
+func getPersistentVolumeClaimTagValues(pvc *v1.PersistentVolumeClaim) map[string]interface{} {
+	pvcTags := make(map[string]interface{})
+	pvcTags["labels"] = pvc.Labels
+	pvcTags["annotations"] = pvc.Annotations
+	pvcTags["namespace"] = pvc.Namespace
+	pvcTags["name"] = pvc.Name
+	pvcTags["phase"] = pvc.Status.Phase
+	pvcTags["requestedstorage"] = getStorageQuantity(pvc.Spec.Resources.Requests)
+	pvcTags["creationtimestamp"] = formatTimestamp(pvc.CreationTimestamp)
+
+	return pvcTags
+}
+
+// NewServiceTagValues Build available tag values for a load balancer service.
+func NewServiceTagValues(platform string, svc *v1.Service, related *RelatedObjects) map[string]interface{} {
+	// Begin to create available tag values
+	availableTags := make(map[string]interface{})
+	availableTags["type"] = LoadBalancerResourceType
+	availableTags["platform"] = platform
+	svcTags := make(map[string]interface{})
+	svcTags["name"] = svc.Name
+	svcTags["namespace"] = svc.Namespace
+	svcTags["annotations"] = svc.Annotations
+	svcTags["labels"] = svc.Labels
+	svcTags["type"] = svc.Spec.Type
+	svcTags["ports"] = getServicePorts(svc)
+	svcTags["selector"] = svc.Spec.Selector
+	svcTags["externaltrafficpolicy"] = svc.Spec.ExternalTrafficPolicy
+	svcTags["loadbalancersourceranges"] = svc.Spec.LoadBalancerSourceRanges
+
+	if svc.Spec.LoadBalancerClass != nil {
+		svcTags["loadbalancerclass"] = *svc.Spec.LoadBalancerClass
+	}
+
+	var lb *providerclient.LoadBalancer
+	if related != nil {
+		lb = related.LoadBalancer
+	}
+
+	svcTags["loadbalancer"] = getServiceLoadBalancer(svc, lb)
+	availableTags["service"] = svcTags
+
+	addRelatedObjectsTagValues(availableTags, related)
+
+	return availableTags
+}
+
+func getServicePorts(svc *v1.Service) []map[string]interface{} {
+	ports := make([]map[string]interface{}, 0, len(svc.Spec.Ports))
+
+	for _, port := range svc.Spec.Ports {
+		ports = append(ports, map[string]interface{}{
+			"name":       port.Name,
+			"protocol":   port.Protocol,
+			"port":       port.Port,
+			"targetport": port.TargetPort.String(),
+			"nodeport":   port.NodePort,
+		})
+	}
+
+	return ports
+}
+
+// getServiceLoadBalancer Get load balancer values from service status and provider load balancer when it exists.
+func getServiceLoadBalancer(svc *v1.Service, lb *providerclient.LoadBalancer) map[string]interface{} {
+	hostname := ""
+	ips := make([]string, 0)
+
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		if hostname == "" {
+			hostname = ingress.Hostname
+		}
+
+		if ingress.IP != "" {
+			ips = append(ips, ingress.IP)
+		}
+	}
+
+	lbTags := make(map[string]interface{})
+	lbTags["hostname"] = hostname
+	lbTags["ips"] = ips
+
+	if lb != nil {
+		lbTags["type"] = lb.Type
+		lbTags["name"] = lb.Name
+		lbTags["arn"] = lb.ARN
+		lbTags["scheme"] = lb.Scheme
+	}
+
+	return lbTags
+}
+
+// addRelatedObjectsTagValues Add tag values of related objects that exist.
+func addRelatedObjectsTagValues(availableTags map[string]interface{}, related *RelatedObjects) {
+	if related == nil {
+		return
+	}
+
+	addNamespaceTagValues(availableTags, related.Namespace)
+	addWorkloadTagValues(availableTags, related.Workload)
+	addStorageClassTagValues(availableTags, related.StorageClass)
+}
+
+// addNamespaceTagValues Add namespace tag values when namespace exists.
+func addNamespaceTagValues(availableTags map[string]interface{}, ns *v1.Namespace) {
+	if ns == nil {
+		return
+	}
+
+	nsTags := make(map[string]interface{})
+	nsTags["name"] = ns.Name
+	nsTags["labels"] = ns.Labels
+	nsTags["annotations"] = ns.Annotations
+	availableTags["namespace"] = nsTags
+}
+
+// addWorkloadTagValues Add workload and pod tag values when workload exists.
+func addWorkloadTagValues(availableTags map[string]interface{}, workload *Workload) {
+	if workload == nil {
+		return
+	}
+
+	workloadTags := make(map[string]interface{})
+	workloadTags["kind"] = workload.Kind
+	workloadTags["name"] = workload.Name
+	workloadTags["labels"] = workload.Labels
+	availableTags["workload"] = workloadTags
+
+	if workload.Pod != nil {
+		podTags := make(map[string]interface{})
+		podTags["name"] = workload.Pod.Name
+		podTags["labels"] = workload.Pod.Labels
+		availableTags["pod"] = podTags
+	}
+}
+
+// addStorageClassTagValues Add storage class tag values when storage class exists.
+func addStorageClassTagValues(availableTags map[string]interface{}, sc *storagev1.StorageClass) {
+	if sc == nil {
+		return
+	}
+
+	scTags := make(map[string]interface{})
+	scTags["name"] = sc.Name
+	scTags["provisioner"] = sc.Provisioner
+	scTags["parameters"] = sc.Parameters
+	scTags["labels"] = sc.Labels
+	scTags["annotations"] = sc.Annotations
+	availableTags["storageclass"] = scTags
+}
+
+// getPersistentVolumeStorageClassName Get storage class name from field or from beta annotation.
+func getPersistentVolumeStorageClassName(pv *v1.PersistentVolume) string {
+	if pv.Spec.StorageClassName != "" {
+		return pv.Spec.StorageClassName
+	}
+
+	return pv.Annotations[betaStorageClassAnnotation]
+}
+
+// getStorageQuantity Get storage quantity as string (for example: "10Gi"), empty when it doesn't exist.
+func getStorageQuantity(resourceList v1.ResourceList) string {
+	quantity, ok := resourceList[v1.ResourceStorage]
+	if !ok {
+		return ""
+	}
+
+	return quantity.String()
+}
+
+func getAccessModes(accessModes []v1.PersistentVolumeAccessMode) []string {
+	result := make([]string, 0, len(accessModes))
+	for _, accessMode := range accessModes {
+		result = append(result, string(accessMode))
+	}
+
+	return result
+}
+
+// getNodeAffinity Get required node affinity values by key (values joined with a comma).
+// Only the "In" operator is used because other ones don't give values the volume is restricted to.
+func getNodeAffinity(pv *v1.PersistentVolume) map[string]string {
+	result := make(map[string]string)
+
+	if pv.Spec.NodeAffinity == nil || pv.Spec.NodeAffinity.Required == nil {
+		return result
+	}
+
+	for _, term := range pv.Spec.NodeAffinity.Required.NodeSelectorTerms {
+		for _, expression := range term.MatchExpressions {
+			if expression.Operator != v1.NodeSelectorOpIn {
+				continue
+			}
+
+			// First term wins
+			if _, exists := result[expression.Key]; !exists {
+				result[expression.Key] = strings.Join(expression.Values, ",")
+			}
+		}
+	}
+
+	return result
+}
+
+// getPersistentVolumeZone Get persistent volume topology zone from labels or node affinity.
+func getPersistentVolumeZone(pv *v1.PersistentVolume) string {
+	nodeAffinity := getNodeAffinity(pv)
+
+	for _, key := range zoneKeys {
+		if zone := pv.Labels[key]; zone != "" {
+			return zone
+		}
+
+		if zone := nodeAffinity[key]; zone != "" {
+			return zone
+		}
+	}
+
+	return ""
+}
+
+// formatTimestamp Format timestamp in RFC 3339, empty when it isn't set.
+func formatTimestamp(timestamp metav1.Time) string {
+	if timestamp.IsZero() {
+		return ""
+	}
+
+	return timestamp.UTC().Format(time.RFC3339)
+}
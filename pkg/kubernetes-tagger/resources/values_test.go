@@ -0,0 +1,168 @@
+package resources
+
+import (
+	"testing"
+	"time"
+
+	providerclient "github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/providerClient"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestNewPersistentVolumeTagValues(t *testing.T) {
+	volumeMode := v1.PersistentVolumeFilesystem
+	creationTimestamp := metav1.NewTime(time.Date(2021, 12, 1, 10, 0, 0, 0, time.UTC))
+	pv := &v1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv1", CreationTimestamp: creationTimestamp},
+		Spec: v1.PersistentVolumeSpec{
+			Capacity:         v1.ResourceList{v1.ResourceStorage: resource.MustParse("10Gi")},
+			AccessModes:      []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+			VolumeMode:       &volumeMode,
+			MountOptions:     []string{"noatime"},
+			StorageClassName: "gp3",
+			NodeAffinity: &v1.VolumeNodeAffinity{Required: &v1.NodeSelector{NodeSelectorTerms: []v1.NodeSelectorTerm{{
+				MatchExpressions: []v1.NodeSelectorRequirement{
+					{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"eu-west-1a"}},
+					{Key: "other", Operator: v1.NodeSelectorOpNotIn, Values: []string{"value"}},
+				},
+			}}}},
+		},
+	}
+	pvc := &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "default", CreationTimestamp: creationTimestamp},
+		Spec: v1.PersistentVolumeClaimSpec{Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse("8Gi")},
+		}},
+	}
+	sc := &storagev1.StorageClass{
+		ObjectMeta:  metav1.ObjectMeta{Name: "gp3", Labels: map[string]string{"tier": "standard"}},
+		Provisioner: "ebs.csi.aws.com",
+		Parameters:  map[string]string{"type": "gp3"},
+	}
+
+	res := NewPersistentVolumeTagValues(AWSResourcePlatform, pv, pvc, &RelatedObjects{StorageClass: sc})
+
+	pvTags, _ := res["persistentvolume"].(map[string]interface{})
+	assert.Equal(t, "10Gi", pvTags["capacity"])
+	assert.Equal(t, []string{"ReadWriteOnce"}, pvTags["accessmodes"])
+	assert.Equal(t, v1.PersistentVolumeFilesystem, pvTags["volumemode"])
+	assert.Equal(t, []string{"noatime"}, pvTags["mountoptions"])
+	assert.Equal(t, map[string]string{v1.LabelTopologyZone: "eu-west-1a"}, pvTags["nodeaffinity"])
+	assert.Equal(t, "eu-west-1a", pvTags["zone"])
+	assert.Equal(t, "2021-12-01T10:00:00Z", pvTags["creationtimestamp"])
+
+	pvcTags, _ := res["persistentvolumeclaim"].(map[string]interface{})
+	assert.Equal(t, "8Gi", pvcTags["requestedstorage"])
+	assert.Equal(t, "2021-12-01T10:00:00Z", pvcTags["creationtimestamp"])
+
+	assert.Equal(t, map[string]interface{}{
+		"name":        "gp3",
+		"provisioner": "ebs.csi.aws.com",
+		"parameters":  map[string]string{"type": "gp3"},
+		"labels":      map[string]string{"tier": "standard"},
+		"annotations": map[string]string(nil),
+	}, res["storageclass"])
+
+	// Beta storage class annotation and zone label
+	pv = &v1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{
+		Name:        "pv2",
+		Labels:      map[string]string{v1.LabelFailureDomainBetaZone: "eu-west-1b"},
+		Annotations: map[string]string{betaStorageClassAnnotation: "standard"},
+	}}
+
+	res = NewPersistentVolumeTagValues(AWSResourcePlatform, pv, nil, nil)
+
+	pvTags, _ = res["persistentvolume"].(map[string]interface{})
+	assert.Equal(t, "standard", pvTags["storageclassname"])
+	assert.Equal(t, "eu-west-1b", pvTags["zone"])
+	assert.Equal(t, "", pvTags["capacity"])
+	assert.Equal(t, "", pvTags["creationtimestamp"])
+	assert.NotContains(t, pvTags, "volumemode")
+	assert.NotContains(t, res, "persistentvolumeclaim")
+	assert.NotContains(t, res, "storageclass")
+}
+
+func TestNewServiceTagValues(t *testing.T) {
+	svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}
+	ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default", Labels: map[string]string{"cost-center": "1234"}}}
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Labels: map[string]string{"app": "web"}}}
+	workload := &Workload{Kind: "Deployment", Name: "web", Labels: map[string]string{"team": "a"}, Pod: pod}
+
+	res := NewServiceTagValues(AWSResourcePlatform, svc, &RelatedObjects{Namespace: ns, Workload: workload})
+	assert.Equal(t, map[string]interface{}{
+		"name":        "default",
+		"labels":      map[string]string{"cost-center": "1234"},
+		"annotations": map[string]string(nil),
+	}, res["namespace"])
+	assert.Equal(t, map[string]interface{}{
+		"kind":   "Deployment",
+		"name":   "web",
+		"labels": map[string]string{"team": "a"},
+	}, res["workload"])
+	assert.Equal(t, map[string]interface{}{
+		"name":   "web-1",
+		"labels": map[string]string{"app": "web"},
+	}, res["pod"])
+
+	// No namespace and workload roots when they don't exist
+	res = NewServiceTagValues(AWSResourcePlatform, svc, &RelatedObjects{})
+	assert.NotContains(t, res, "namespace")
+	assert.NotContains(t, res, "workload")
+	assert.NotContains(t, res, "pod")
+}
+
+func TestNewServiceTagValuesSpecAndLoadBalancer(t *testing.T) {
+	lbClass := "service.k8s.aws/nlb"
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: v1.ServiceSpec{
+			Type: v1.ServiceTypeLoadBalancer,
+			Ports: []v1.ServicePort{
+				{Name: "https", Protocol: v1.ProtocolTCP, Port: 443, TargetPort: intstr.FromString("https"), NodePort: 30443},
+			},
+			Selector:                 map[string]string{"app": "web"},
+			ExternalTrafficPolicy:    v1.ServiceExternalTrafficPolicyTypeLocal,
+			LoadBalancerSourceRanges: []string{"10.0.0.0/8"},
+			LoadBalancerClass:        &lbClass,
+		},
+		Status: v1.ServiceStatus{LoadBalancer: v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{
+			{Hostname: "web-0123456789abcdef.elb.eu-west-1.amazonaws.com"},
+		}}},
+	}
+	lb := &providerclient.LoadBalancer{
+		Type:   providerclient.LoadBalancerTypeNLB,
+		Name:   "web",
+		ARN:    "arn:aws:elasticloadbalancing:eu-west-1:123456789012:loadbalancer/net/web/0123456789abcdef",
+		Scheme: "internal",
+	}
+
+	res := NewServiceTagValues(AWSResourcePlatform, svc, &RelatedObjects{LoadBalancer: lb})
+	svcTags := res["service"].(map[string]interface{})
+	assert.Equal(t, v1.ServiceTypeLoadBalancer, svcTags["type"])
+	assert.Equal(t, []map[string]interface{}{
+		{"name": "https", "protocol": v1.ProtocolTCP, "port": int32(443), "targetport": "https", "nodeport": int32(30443)},
+	}, svcTags["ports"])
+	assert.Equal(t, map[string]string{"app": "web"}, svcTags["selector"])
+	assert.Equal(t, v1.ServiceExternalTrafficPolicyTypeLocal, svcTags["externaltrafficpolicy"])
+	assert.Equal(t, []string{"10.0.0.0/8"}, svcTags["loadbalancersourceranges"])
+	assert.Equal(t, "service.k8s.aws/nlb", svcTags["loadbalancerclass"])
+	assert.Equal(t, map[string]interface{}{
+		"hostname": "web-0123456789abcdef.elb.eu-west-1.amazonaws.com",
+		"ips":      []string{},
+		"type":     "nlb",
+		"name":     "web",
+		"arn":      "arn:aws:elasticloadbalancing:eu-west-1:123456789012:loadbalancer/net/web/0123456789abcdef",
+		"scheme":   "internal",
+	}, svcTags["loadbalancer"])
+
+	// Only status values without provider load balancer
+	res = NewServiceTagValues(AWSResourcePlatform, svc, nil)
+	assert.Equal(t, map[string]interface{}{
+		"hostname": "web-0123456789abcdef.elb.eu-west-1.amazonaws.com",
+		"ips":      []string{},
+	}, res["service"].(map[string]interface{})["loadbalancer"])
+}
@@ -0,0 +1,115 @@
+package resources
+
+import (
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// maxOwnerDepth Maximum number of owners followed to find the top level one.
+const maxOwnerDepth = 5
+
+// Workload Top level owner of pods using a resource.
+type Workload struct {
+	Kind   string            `json:"kind"`
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+	// Pod used to find the workload
+	Pod *v1.Pod `json:"pod,omitempty"`
+}
+
+// getClaimWorkload Get workload of pods mounting a persistent volume claim.
+// Nil is returned when no pod mounts it.
+func getClaimWorkload(objectGetter ObjectGetter, pvc *v1.PersistentVolumeClaim) (*Workload, error) {
+	if pvc == nil {
+		return nil, nil // nolint: nilnil // No claim
+	}
+
+	pods, err := objectGetter.GetClaimPods(pvc.Namespace, pvc.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return getPodsWorkload(objectGetter, pods)
+}
+
+// getServiceWorkload Get workload of pods matched by service selector.
+// Nil is returned when service has no selector or when no pod is matched.
+func getServiceWorkload(objectGetter ObjectGetter, svc *v1.Service) (*Workload, error) {
+	if len(svc.Spec.Selector) == 0 {
+		return nil, nil // nolint: nilnil // Service without selector
+	}
+
+	pods, err := objectGetter.GetSelectorPods(svc.Namespace, labels.SelectorFromSet(svc.Spec.Selector))
+	if err != nil {
+		return nil, err
+	}
+
+	return getPodsWorkload(objectGetter, pods)
+}
+
+// getPodsWorkload Get workload of the first pod.
+// Running pods are preferred to finished ones and pods are sorted by name to always get the same one.
+func getPodsWorkload(objectGetter ObjectGetter, pods []*v1.Pod) (*Workload, error) {
+	workload, _, err := getPodsWorkloadWithOwners(objectGetter, pods)
+
+	return workload, err
+}
+
+// getPodsWorkloadWithOwners Get workload of the first pod with owners got to find it.
+func getPodsWorkloadWithOwners(objectGetter ObjectGetter, pods []*v1.Pod) (*Workload, []metav1.Object, error) {
+	owners := make([]metav1.Object, 0)
+
+	if len(pods) == 0 {
+		return nil, owners, nil
+	}
+
+	sortedPods := append([]*v1.Pod{}, pods...)
+	sort.SliceStable(sortedPods, func(i, j int) bool {
+		iFinished, jFinished := isPodFinished(sortedPods[i]), isPodFinished(sortedPods[j])
+		if iFinished != jFinished {
+			return !iFinished
+		}
+
+		return sortedPods[i].Name < sortedPods[j].Name
+	})
+
+	pod := sortedPods[0]
+	// Pod is its own workload when it has no controller
+	workload := &Workload{Kind: "Pod", Name: pod.Name, Labels: pod.Labels, Pod: pod}
+
+	var obj metav1.Object = pod
+
+	for i := 0; i < maxOwnerDepth; i++ {
+		ownerRef := metav1.GetControllerOf(obj)
+		if ownerRef == nil {
+			break
+		}
+
+		// Owner is known even if it cannot be got
+		workload.Kind = ownerRef.Kind
+		workload.Name = ownerRef.Name
+		workload.Labels = nil
+
+		owner, err := objectGetter.GetOwner(pod.Namespace, ownerRef)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if owner == nil {
+			break
+		}
+
+		owners = append(owners, owner)
+		workload.Labels = owner.GetLabels()
+		obj = owner
+	}
+
+	return workload, owners, nil
+}
+
+func isPodFinished(pod *v1.Pod) bool {
+	return pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed
+}
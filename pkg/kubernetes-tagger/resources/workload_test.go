@@ -0,0 +1,84 @@
+package resources
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclient "k8s.io/client-go/kubernetes/fake"
+)
+
+func controllerRef(kind, name string) []metav1.OwnerReference {
+	controller := true
+
+	return []metav1.OwnerReference{{Kind: kind, Name: name, Controller: &controller}}
+}
+
+func TestGetServiceWorkload(t *testing.T) {
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name: "web", Namespace: "default", Labels: map[string]string{"team": "a"},
+	}}
+	replicaSet := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name: "web-abc", Namespace: "default", OwnerReferences: controllerRef("Deployment", "web"),
+	}}
+	// Finished pods are used only when no other pod exists
+	finishedPod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "a-web", Namespace: "default", Labels: map[string]string{"app": "web"}},
+		Status:     v1.PodStatus{Phase: v1.PodFailed},
+	}
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name: "web-abc-1", Namespace: "default", Labels: map[string]string{"app": "web"}, OwnerReferences: controllerRef("ReplicaSet", "web-abc"),
+	}}
+	getter := NewClientObjectGetter(context.Background(), testclient.NewSimpleClientset(deployment, replicaSet, finishedPod, pod))
+
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       v1.ServiceSpec{Selector: map[string]string{"app": "web"}},
+	}
+
+	res, err := getServiceWorkload(getter, svc)
+	assert.Nil(t, err)
+	assert.Equal(t, "Deployment", res.Kind)
+	assert.Equal(t, "web", res.Name)
+	assert.Equal(t, map[string]string{"team": "a"}, res.Labels)
+	assert.Equal(t, "web-abc-1", res.Pod.Name)
+
+	// Service without selector
+	res, err = getServiceWorkload(getter, &v1.Service{})
+	assert.Nil(t, err)
+	assert.Nil(t, res)
+}
+
+func TestGetClaimWorkload(t *testing.T) {
+	pvc := &v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "default"}}
+	volumes := []v1.Volume{{
+		Name:         "data",
+		VolumeSource: v1.VolumeSource{PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: "data"}},
+	}}
+	// Owner kind not supported
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-1", Namespace: "default", OwnerReferences: controllerRef("Rollout", "app")},
+		Spec:       v1.PodSpec{Volumes: volumes},
+	}
+	getter := NewClientObjectGetter(context.Background(), testclient.NewSimpleClientset(pod))
+
+	res, err := getClaimWorkload(getter, pvc)
+	assert.Nil(t, err)
+	assert.Equal(t, &Workload{Kind: "Rollout", Name: "app", Pod: pod}, res)
+
+	// Claim not mounted
+	res, err = getClaimWorkload(getter, &v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"}})
+	assert.Nil(t, err)
+	assert.Nil(t, res)
+
+	// Pod without controller is its own workload
+	pod.OwnerReferences = nil
+	getter = NewClientObjectGetter(context.Background(), testclient.NewSimpleClientset(pod))
+
+	res, err = getClaimWorkload(getter, pvc)
+	assert.Nil(t, err)
+	assert.Equal(t, &Workload{Kind: "Pod", Name: "app-1", Pod: pod}, res)
+}
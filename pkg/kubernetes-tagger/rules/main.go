@@ -16,13 +16,24 @@ var ErrCannotStringifyAvailableTagValues = errors.New("error cannot transform to
 
 // CalculateTags Calculate tags delta to add/update or delete tags on resource.
 func CalculateTags(actualTags []*tags.Tag, availableTagValues map[string]interface{}, rules []*Rule) (*tags.TagDelta, error) {
+	delta, _, err := CalculateTagsWithTrace(actualTags, availableTagValues, rules)
+
+	return delta, err
+}
+
+// CalculateTagsWithTrace Calculate tags delta and trace how each rule was evaluated.
+func CalculateTagsWithTrace(
+	actualTags []*tags.Tag,
+	availableTagValues map[string]interface{},
+	rules []*Rule,
+) (*tags.TagDelta, *Trace, error) {
 	logrus.Debug("Begin calculate tags from available values and rules")
 	// Create GJSON result to filter tags
 	jsonBytes, err := json.Marshal(availableTagValues)
 	if err != nil {
 		logrus.Debugf("Error: cannot stringify available tag values: %v", err)
 
-		return nil, ErrCannotStringifyAvailableTagValues
+		return nil, nil, ErrCannotStringifyAvailableTagValues
 	}
 
 	jsonString := string(jsonBytes)
@@ -33,90 +44,170 @@ func CalculateTags(actualTags []*tags.Tag, availableTagValues map[string]interfa
 	// Manage rules
 	addList := make([]*tags.Tag, 0)
 	deleteList := make([]*tags.Tag, 0)
+	trace := &Trace{Rules: make([]*RuleTrace, 0)}
+
+	for i, rule := range rules {
+		ruleTrace := &RuleTrace{Index: i, Name: rule.Name, Tag: rule.Tag, Action: rule.Action, Query: rule.Query, Value: rule.Value}
+		trace.Rules = append(trace.Rules, ruleTrace)
 
-	for _, rule := range rules {
 		// Eval conditions
-		whenResult := evalConditions(rule.When, gjsonResult)
+		whenResult, conditionTraces := evalConditions(rule.When, gjsonResult)
+		ruleTrace.Conditions = conditionTraces
+
 		if !whenResult {
-			continue
-		}
+			ruleTrace.Result = TraceResultSkip
+			ruleTrace.Reason = TraceReasonConditionsNotMatched
 
-		// Create tag
-		tag := &tags.Tag{
-			Key: rule.Tag,
+			continue
 		}
 
+		var tag *tags.Tag
 		if rule.Action == RuleActionDelete {
-			// Delete case
-			// In the delete case, no value is required
-			// Value is the actual one in fact, if it exists
-			// Filter to check if the value already exists on the resource
-			filterResult, _ := funk.Filter(actualTags, func(actualTag *tags.Tag) bool {
-				return actualTag.Key == tag.Key
-			}).([]*tags.Tag)
-			// Check if tag already exists and need to be removed
-			if len(filterResult) != 0 {
-				// Add actual tag value
-				tag.Value = filterResult[0].Value
-				// Add it to delete list
+			tag = evalDeleteRule(rule, actualTags, ruleTrace)
+			if tag != nil {
 				deleteList = append(deleteList, tag)
-			} else {
-				logrus.Infof("Tag %s is already deleted -> skipping", tag.Key)
 			}
 		} else {
-			// Add case
-
-			// In Add Action, value is required
-
-			// Check if we are in query case
-			if rule.Query != "" {
-				queryResult := gjsonResult.Get(rule.Query).String()
-				if queryResult == "" {
-					// Stop here, cannot get value
-					logrus.Infof("Tag %s with query %s doesn't give any results -> skip it", rule.Tag, rule.Query)
-
-					continue
-				}
-				tag.Value = queryResult
-			} else {
-				// Value directly case
-				tag.Value = rule.Value
-			}
-
-			// Filter to test if value if necessary added / updated
-			filterResult, _ := funk.Filter(actualTags, func(actualTag *tags.Tag) bool {
-				return actualTag.Key == tag.Key && actualTag.Value == tag.Value
-			}).([]*tags.Tag)
-
-			// Check if tag already exists and need to be added / updated
-			if len(filterResult) == 0 {
+			tag = evalAddRule(rule, actualTags, gjsonResult, ruleTrace)
+			if tag != nil {
 				addList = append(addList, tag)
-			} else {
-				logrus.Infof("Tag %s with value \"%s\" is already present -> skipping", tag.Key, tag.Value)
 			}
 		}
 	}
 
 	delta := &tags.TagDelta{AddList: addList, DeleteList: deleteList}
 
-	return delta, nil
+	return delta, trace, nil
+}
+
+// evalDeleteRule Evaluate delete rule and return tag to delete if needed.
+func evalDeleteRule(rule *Rule, actualTags []*tags.Tag, ruleTrace *RuleTrace) *tags.Tag {
+	// Create tag
+	tag := &tags.Tag{
+		Key: rule.Tag,
+	}
+
+	// In the delete case, no value is required
+	// Value is the actual one in fact, if it exists
+	// Filter to check if the value already exists on the resource
+	filterResult, _ := funk.Filter(actualTags, func(actualTag *tags.Tag) bool {
+		return actualTag.Key == tag.Key
+	}).([]*tags.Tag)
+	// Check if tag already exists and need to be removed
+	if len(filterResult) == 0 {
+		logrus.Infof("Tag %s is already deleted -> skipping", tag.Key)
+
+		ruleTrace.Result = TraceResultSkip
+		ruleTrace.Reason = TraceReasonTagAlreadyDeleted
+
+		return nil
+	}
+
+	// Add actual tag value
+	tag.Value = filterResult[0].Value
+
+	ruleTrace.Result = TraceResultDelete
+	ruleTrace.Reason = TraceReasonTagPresentOnResource
+
+	return tag
+}
+
+// evalAddRule Evaluate add rule and return tag to add if needed.
+func evalAddRule(rule *Rule, actualTags []*tags.Tag, gjsonResult gjson.Result, ruleTrace *RuleTrace) *tags.Tag {
+	// Create tag
+	tag := &tags.Tag{
+		Key: rule.Tag,
+	}
+
+	// In Add Action, value is required
+
+	// Check if we are in query case
+	if rule.Query != "" {
+		queryResult := gjsonResult.Get(rule.Query).String()
+		ruleTrace.QueryResult = queryResult
+
+		if queryResult == "" {
+			// Stop here, cannot get value
+			logrus.Infof("Tag %s with query %s doesn't give any results -> skip it", rule.Tag, rule.Query)
+
+			ruleTrace.Result = TraceResultSkip
+			ruleTrace.Reason = TraceReasonQueryWithoutResult
+
+			return nil
+		}
+
+		tag.Value = queryResult
+	} else {
+		// Value directly case
+		tag.Value = rule.Value
+	}
+
+	// Filter to test if value if necessary added / updated
+	filterResult, _ := funk.Filter(actualTags, func(actualTag *tags.Tag) bool {
+		return actualTag.Key == tag.Key && actualTag.Value == tag.Value
+	}).([]*tags.Tag)
+
+	// Check if tag already exists and need to be added / updated
+	if len(filterResult) != 0 {
+		logrus.Infof("Tag %s with value \"%s\" is already present -> skipping", tag.Key, tag.Value)
+
+		ruleTrace.Result = TraceResultSkip
+		ruleTrace.Reason = TraceReasonTagAlreadyPresent
+
+		return nil
+	}
+
+	ruleTrace.Result = TraceResultAdd
+	ruleTrace.Reason = TraceReasonTagMissing
+
+	// Check if it is an update
+	sameKeyResult, _ := funk.Filter(actualTags, func(actualTag *tags.Tag) bool {
+		return actualTag.Key == tag.Key
+	}).([]*tags.Tag)
+	if len(sameKeyResult) != 0 {
+		ruleTrace.Reason = TraceReasonTagValueDifferent
+	}
+
+	return tag
 }
 
-func evalConditions(conditions []*Condition, gjsonResult gjson.Result) bool {
+// MatchConditions Check if all conditions match available tag values like rules evaluation does.
+func MatchConditions(conditions []*Condition, availableTagValues map[string]interface{}) (bool, error) {
+	jsonBytes, err := json.Marshal(availableTagValues)
+	if err != nil {
+		return false, ErrCannotStringifyAvailableTagValues
+	}
+
+	result, _ := evalConditions(conditions, gjson.ParseBytes(jsonBytes))
+
+	return result, nil
+}
+
+// evalConditions Evaluate all conditions and trace each of them.
+func evalConditions(conditions []*Condition, gjsonResult gjson.Result) (bool, []*ConditionTrace) {
 	result := true
+	conditionTraces := make([]*ConditionTrace, 0)
 
 	for _, condition := range conditions {
 		queryResult := gjsonResult.Get(condition.Condition).String()
+
+		var matched bool
 		if condition.Operator == ConditionOperatorEqual {
-			result = result && queryResult == condition.Value
+			matched = queryResult == condition.Value
 		} else {
-			result = result && queryResult != condition.Value
-		}
-		// Quit when false arrive => ASAP
-		if !result {
-			return result
+			matched = queryResult != condition.Value
 		}
+
+		result = result && matched
+
+		conditionTraces = append(conditionTraces, &ConditionTrace{
+			Condition: condition.Condition,
+			Operator:  condition.Operator,
+			Expected:  condition.Value,
+			Actual:    queryResult,
+			Matched:   matched,
+		})
 	}
 
-	return result
+	return result, conditionTraces
 }
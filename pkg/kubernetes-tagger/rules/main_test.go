@@ -366,3 +366,57 @@ func TestCalculateTags(t *testing.T) {
 		})
 	}
 }
+
+func TestCalculateTagsWithTrace(t *testing.T) {
+	actualTags := []*tags.Tag{
+		&tags.Tag{Key: "present", Value: "value"},
+		&tags.Tag{Key: "updated", Value: "old"},
+	}
+	availableTagValues := map[string]interface{}{
+		"key": "value",
+	}
+	rules := []*Rule{
+		&Rule{Action: RuleActionAdd, Tag: "present", Value: "value"},
+		&Rule{Action: RuleActionAdd, Tag: "updated", Query: "key"},
+		&Rule{Action: RuleActionAdd, Tag: "empty", Query: "unknown"},
+		&Rule{
+			Action: RuleActionAdd,
+			Tag:    "conditioned",
+			Value:  "value",
+			When: []*Condition{
+				&Condition{Condition: "key", Operator: ConditionOperatorNotEqual, Value: "value"},
+				&Condition{Condition: "key", Operator: ConditionOperatorEqual, Value: "value"},
+			},
+		},
+		&Rule{Action: RuleActionDelete, Tag: "present"},
+		&Rule{Action: RuleActionDelete, Tag: "deleted"},
+	}
+
+	_, trace, err := CalculateTagsWithTrace(actualTags, availableTagValues, rules)
+	if err != nil {
+		t.Errorf("CalculateTagsWithTrace() error = %v", err)
+		return
+	}
+
+	want := []*RuleTrace{
+		&RuleTrace{Index: 0, Tag: "present", Action: RuleActionAdd, Value: "value", Conditions: []*ConditionTrace{},
+			Result: TraceResultSkip, Reason: TraceReasonTagAlreadyPresent},
+		&RuleTrace{Index: 1, Tag: "updated", Action: RuleActionAdd, Query: "key", QueryResult: "value", Conditions: []*ConditionTrace{},
+			Result: TraceResultAdd, Reason: TraceReasonTagValueDifferent},
+		&RuleTrace{Index: 2, Tag: "empty", Action: RuleActionAdd, Query: "unknown", Conditions: []*ConditionTrace{},
+			Result: TraceResultSkip, Reason: TraceReasonQueryWithoutResult},
+		&RuleTrace{Index: 3, Tag: "conditioned", Action: RuleActionAdd, Value: "value",
+			Conditions: []*ConditionTrace{
+				&ConditionTrace{Condition: "key", Operator: ConditionOperatorNotEqual, Expected: "value", Actual: "value", Matched: false},
+				&ConditionTrace{Condition: "key", Operator: ConditionOperatorEqual, Expected: "value", Actual: "value", Matched: true},
+			},
+			Result: TraceResultSkip, Reason: TraceReasonConditionsNotMatched},
+		&RuleTrace{Index: 4, Tag: "present", Action: RuleActionDelete, Conditions: []*ConditionTrace{},
+			Result: TraceResultDelete, Reason: TraceReasonTagPresentOnResource},
+		&RuleTrace{Index: 5, Tag: "deleted", Action: RuleActionDelete, Conditions: []*ConditionTrace{},
+			Result: TraceResultSkip, Reason: TraceReasonTagAlreadyDeleted},
+	}
+	if !reflect.DeepEqual(trace.Rules, want) {
+		t.Errorf("CalculateTagsWithTrace() trace = %v, want %v", trace.Rules, want)
+	}
+}
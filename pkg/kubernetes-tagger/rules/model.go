@@ -20,6 +20,7 @@ const ConditionOperatorNotEqual = ConditionOperator("NotEqual")
 
 // Rule rule.
 type Rule struct {
+	Name   string
 	Tag    string
 	Query  string
 	Value  string
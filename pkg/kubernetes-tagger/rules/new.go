@@ -2,6 +2,7 @@ package rules
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
 )
@@ -9,6 +10,9 @@ import (
 // ErrRuleEmptyWhenCondition Error when "when condition" is empty.
 var ErrRuleEmptyWhenCondition = errors.New("when condition mustn't be empty")
 
+// ErrRuleEmptyWhen Error when a "when" entry is empty.
+var ErrRuleEmptyWhen = errors.New("when entry mustn't be empty")
+
 // ErrRuleConditionOperatorNotSupported Rule condition operator not supported.
 var ErrRuleConditionOperatorNotSupported = errors.New("condition operator not supported")
 
@@ -24,6 +28,20 @@ var ErrRuleQueryAndValueEmptyForAddCase = errors.New("query and value mustn't be
 // ErrRuleQueryAndValuePopulatedForAddCase Err Rule query and value populated for Add case.
 var ErrRuleQueryAndValuePopulatedForAddCase = errors.New("query and value cannot be populated at the same time in add case")
 
+// ValidationError Rule validation error with the path of the field in error.
+type ValidationError struct {
+	Path string
+	Err  error
+}
+
+func (ve *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %v", ve.Path, ve.Err)
+}
+
+func (ve *ValidationError) Unwrap() error {
+	return ve.Err
+}
+
 // New Create rules array from ruleConfig with validation.
 func New(ruleConfigs []*config.RuleConfig) ([]*Rule, error) {
 	rules := make([]*Rule, 0)
@@ -31,6 +49,12 @@ func New(ruleConfigs []*config.RuleConfig) ([]*Rule, error) {
 		return rules, nil
 	}
 
+	// Validate all rules before creating them
+	errs := Validate(ruleConfigs)
+	if len(errs) != 0 {
+		return nil, errs[0]
+	}
+
 	for _, ruleConfig := range ruleConfigs {
 		rule, err := newFromRuleConfig(ruleConfig)
 		// Check error
@@ -44,61 +68,95 @@ func New(ruleConfigs []*config.RuleConfig) ([]*Rule, error) {
 	return rules, nil
 }
 
-func newFromRuleConfig(ruleConfig *config.RuleConfig) (*Rule, error) {
-	if ruleConfig == nil {
-		return nil, nil // nolint: nilnil // No need
+// Validate Validate rule configurations and return all errors found.
+func Validate(ruleConfigs []*config.RuleConfig) []*ValidationError {
+	errs := make([]*ValidationError, 0)
+
+	for i, ruleConfig := range ruleConfigs {
+		errs = append(errs, validateRuleConfig(fmt.Sprintf("rules[%d]", i), ruleConfig)...)
 	}
 
-	// Create rule
-	rule := &Rule{
-		Tag:   ruleConfig.Tag,
-		Query: ruleConfig.Query,
-		Value: ruleConfig.Value,
+	return errs
+}
+
+func validateRuleConfig(path string, ruleConfig *config.RuleConfig) []*ValidationError {
+	errs := make([]*ValidationError, 0)
+
+	if ruleConfig == nil {
+		return errs
 	}
 
-	switch ruleConfig.Action {
-	case string(RuleActionAdd):
-		rule.Action = RuleActionAdd
-	case string(RuleActionDelete):
-		rule.Action = RuleActionDelete
-	default:
-		return nil, ErrRuleActionNotSupported
+	// Check action
+	if ruleConfig.Action != string(RuleActionAdd) && ruleConfig.Action != string(RuleActionDelete) {
+		errs = append(errs, &ValidationError{Path: path + ".action", Err: ErrRuleActionNotSupported})
 	}
+
 	// Check if rule is valid
-	if rule.Tag == "" {
-		return nil, ErrRuleEmptyTag
+	if ruleConfig.Tag == "" {
+		errs = append(errs, &ValidationError{Path: path + ".tag", Err: ErrRuleEmptyTag})
 	}
 
 	// Check add case
-	if rule.Action == RuleActionAdd && rule.Query == "" && rule.Value == "" {
-		return nil, ErrRuleQueryAndValueEmptyForAddCase
+	if ruleConfig.Action == string(RuleActionAdd) && ruleConfig.Query == "" && ruleConfig.Value == "" {
+		errs = append(errs, &ValidationError{Path: path + ".query", Err: ErrRuleQueryAndValueEmptyForAddCase})
 	}
 
 	// Check that in add case we haven't query and value at the same time
-	if rule.Action == RuleActionAdd && rule.Query != "" && rule.Value != "" {
-		return nil, ErrRuleQueryAndValuePopulatedForAddCase
+	if ruleConfig.Action == string(RuleActionAdd) && ruleConfig.Query != "" && ruleConfig.Value != "" {
+		errs = append(errs, &ValidationError{Path: path + ".query", Err: ErrRuleQueryAndValuePopulatedForAddCase})
 	}
 
-	// Manage conditions
-	conditions := make([]*Condition, 0)
+	// Check conditions
+	for i, conditionConfig := range ruleConfig.When {
+		conditionPath := fmt.Sprintf("%s.when[%d]", path, i)
+
+		if conditionConfig == nil {
+			errs = append(errs, &ValidationError{Path: conditionPath, Err: ErrRuleEmptyWhen})
+
+			continue
+		}
 
-	for _, conditionConfig := range ruleConfig.When {
 		if conditionConfig.Condition == "" {
-			return nil, ErrRuleEmptyWhenCondition
+			errs = append(errs, &ValidationError{Path: conditionPath + ".condition", Err: ErrRuleEmptyWhenCondition})
+		}
+
+		if conditionConfig.Operator != string(ConditionOperatorEqual) &&
+			conditionConfig.Operator != string(ConditionOperatorNotEqual) {
+			errs = append(errs, &ValidationError{Path: conditionPath + ".operator", Err: ErrRuleConditionOperatorNotSupported})
 		}
+	}
+
+	return errs
+}
+
+func newFromRuleConfig(ruleConfig *config.RuleConfig) (*Rule, error) {
+	if ruleConfig == nil {
+		return nil, nil // nolint: nilnil // No need
+	}
+
+	// Check if rule is valid
+	errs := validateRuleConfig("", ruleConfig)
+	if len(errs) != 0 {
+		return nil, errs[0].Err
+	}
+
+	// Create rule
+	rule := &Rule{
+		Name:   ruleConfig.Name,
+		Tag:    ruleConfig.Tag,
+		Query:  ruleConfig.Query,
+		Value:  ruleConfig.Value,
+		Action: ActionType(ruleConfig.Action),
+	}
 
+	// Manage conditions
+	conditions := make([]*Condition, 0)
+
+	for _, conditionConfig := range ruleConfig.When {
 		condition := &Condition{
 			Condition: conditionConfig.Condition,
 			Value:     conditionConfig.Value,
-		}
-
-		switch conditionConfig.Operator {
-		case string(ConditionOperatorEqual):
-			condition.Operator = ConditionOperatorEqual
-		case string(ConditionOperatorNotEqual):
-			condition.Operator = ConditionOperatorNotEqual
-		default:
-			return nil, ErrRuleConditionOperatorNotSupported
+			Operator:  ConditionOperator(conditionConfig.Operator),
 		}
 
 		conditions = append(conditions, condition)
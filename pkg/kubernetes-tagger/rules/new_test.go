@@ -189,6 +189,18 @@ func Test_newFromRuleConfig(t *testing.T) {
 			true,
 			ErrRuleEmptyWhenCondition,
 		},
+		{
+			"When with empty entry",
+			args{ruleConfig: &config.RuleConfig{
+				Action: "add",
+				Tag:    "test-tag",
+				Query:  "query",
+				When:   []*config.ConditionConfig{nil},
+			}},
+			nil,
+			true,
+			ErrRuleEmptyWhen,
+		},
 		{
 			"When with empty operator",
 			args{ruleConfig: &config.RuleConfig{
@@ -250,3 +262,42 @@ func Test_newFromRuleConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestValidate(t *testing.T) {
+	ruleConfigs := []*config.RuleConfig{
+		&config.RuleConfig{
+			Action: "add",
+			Tag:    "test-tag",
+			Value:  "value",
+		},
+		&config.RuleConfig{
+			Action: "not-supported",
+		},
+		&config.RuleConfig{
+			Action: "add",
+			Tag:    "test-tag",
+			When: []*config.ConditionConfig{
+				&config.ConditionConfig{Condition: "condition", Operator: "Equal"},
+				&config.ConditionConfig{Operator: "Other"},
+			},
+		},
+	}
+	want := []*ValidationError{
+		&ValidationError{Path: "rules[1].action", Err: ErrRuleActionNotSupported},
+		&ValidationError{Path: "rules[1].tag", Err: ErrRuleEmptyTag},
+		&ValidationError{Path: "rules[2].query", Err: ErrRuleQueryAndValueEmptyForAddCase},
+		&ValidationError{Path: "rules[2].when[1].condition", Err: ErrRuleEmptyWhenCondition},
+		&ValidationError{Path: "rules[2].when[1].operator", Err: ErrRuleConditionOperatorNotSupported},
+	}
+
+	got := Validate(ruleConfigs)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate() = %v, want %v", got, want)
+	}
+
+	// New must return the first error with its path
+	_, err := New(ruleConfigs)
+	if err == nil || err.Error() != "rules[1].action: rule action not supported" {
+		t.Errorf("New() error = '%v'", err)
+	}
+}
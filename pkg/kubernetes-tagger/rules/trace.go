@@ -0,0 +1,52 @@
+package rules
+
+// TraceResult Rule evaluation result.
+type TraceResult string
+
+// TraceResultAdd Tag added or updated.
+const TraceResultAdd = TraceResult("add")
+
+// TraceResultDelete Tag deleted.
+const TraceResultDelete = TraceResult("delete")
+
+// TraceResultSkip Rule skipped.
+const TraceResultSkip = TraceResult("skip")
+
+// Reasons of rule evaluation results.
+const (
+	TraceReasonConditionsNotMatched = "conditions not matched"
+	TraceReasonQueryWithoutResult   = "query doesn't give any results"
+	TraceReasonTagAlreadyPresent    = "tag already present with the same value"
+	TraceReasonTagAlreadyDeleted    = "tag already deleted"
+	TraceReasonTagMissing           = "tag missing on resource"
+	TraceReasonTagValueDifferent    = "tag present with a different value"
+	TraceReasonTagPresentOnResource = "tag present on resource"
+)
+
+// Trace Rules evaluation trace.
+type Trace struct {
+	Rules []*RuleTrace `json:"rules"`
+}
+
+// RuleTrace Rule evaluation trace.
+type RuleTrace struct {
+	Index       int               `json:"index"`
+	Name        string            `json:"name,omitempty"`
+	Tag         string            `json:"tag"`
+	Action      ActionType        `json:"action"`
+	Conditions  []*ConditionTrace `json:"conditions"`
+	Query       string            `json:"query,omitempty"`
+	QueryResult string            `json:"queryResult,omitempty"`
+	Value       string            `json:"value,omitempty"`
+	Result      TraceResult       `json:"result"`
+	Reason      string            `json:"reason"`
+}
+
+// ConditionTrace Condition evaluation trace.
+type ConditionTrace struct {
+	Condition string            `json:"condition"`
+	Operator  ConditionOperator `json:"operator"`
+	Expected  string            `json:"expected"`
+	Actual    string            `json:"actual"`
+	Matched   bool              `json:"matched"`
+}
@@ -0,0 +1,153 @@
+package ruletest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/resources"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/rules"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/tags"
+	"sigs.k8s.io/yaml"
+)
+
+// LoadSuite Load rules test suite from YAML file.
+func LoadSuite(path string) (*Suite, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var suite Suite
+
+	err = yaml.UnmarshalStrict(content, &suite)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse test suite %s: %w", path, err)
+	}
+
+	return &suite, nil
+}
+
+// Run Run all test cases of suite with rules.
+func Run(suite *Suite, platform string, rulesList []*rules.Rule) []*Result {
+	results := make([]*Result, 0)
+
+	for _, testCase := range suite.Tests {
+		results = append(results, runTestCase(testCase, platform, rulesList))
+	}
+
+	return results
+}
+
+func runTestCase(testCase *TestCase, platform string, rulesList []*rules.Rule) *Result {
+	result := &Result{Name: testCase.Name}
+
+	availableTagValues, err := getAvailableTagValues(testCase, platform)
+	if err != nil {
+		result.Error = err.Error()
+
+		return result
+	}
+
+	// Transform actual tags
+	actualTags := make([]*tags.Tag, 0)
+	for key, value := range testCase.ActualTags {
+		actualTags = append(actualTags, &tags.Tag{Key: key, Value: value})
+	}
+
+	delta, err := rules.CalculateTags(actualTags, availableTagValues, rulesList)
+	// Check error
+	if err != nil {
+		result.Error = err.Error()
+
+		return result
+	}
+
+	expected := testCase.Expected
+	if expected == nil {
+		expected = &tags.TagDelta{}
+	}
+
+	// Deleted tag values are the actual ones, only keys are compared
+	result.Diffs = append(diffTags("addList", expected.AddList, delta.AddList, true),
+		diffTags("deleteList", expected.DeleteList, delta.DeleteList, false)...)
+	result.Passed = len(result.Diffs) == 0
+
+	return result
+}
+
+// getAvailableTagValues Build available tag values with the same functions as resources.
+func getAvailableTagValues(testCase *TestCase, platform string) (map[string]interface{}, error) {
+	related := &resources.RelatedObjects{
+		Namespace:    testCase.Namespace,
+		Workload:     testCase.Workload,
+		StorageClass: testCase.StorageClass,
+		LoadBalancer: testCase.LoadBalancer,
+	}
+
+	var availableTagValues map[string]interface{}
+
+	switch {
+	case testCase.PersistentVolume != nil && testCase.Service != nil:
+		return nil, ErrTooManyObjectsInTestCase
+	case testCase.PersistentVolume != nil:
+		availableTagValues = resources.NewPersistentVolumeTagValues(platform, testCase.PersistentVolume, testCase.PersistentVolumeClaim, related)
+	case testCase.PersistentVolumeClaim != nil:
+		return nil, ErrClaimWithoutPersistentVolume
+	case testCase.Service != nil:
+		availableTagValues = resources.NewServiceTagValues(platform, testCase.Service, related)
+	default:
+		return nil, ErrNoObjectInTestCase
+	}
+
+	resources.AddClusterTagValues(availableTagValues, testCase.Cluster, nil)
+
+	return availableTagValues, nil
+}
+
+// diffTags Get differences between expected and calculated tag lists.
+func diffTags(listName string, expected, got []*tags.Tag, compareValues bool) []string {
+	diffs := make([]string, 0)
+
+	expectedValues := tagsToMap(expected)
+	gotValues := tagsToMap(got)
+
+	for _, key := range sortedKeys(expectedValues) {
+		gotValue, exists := gotValues[key]
+
+		switch {
+		case !exists:
+			diffs = append(diffs, fmt.Sprintf("%s: missing tag %s=\"%s\"", listName, key, expectedValues[key]))
+		case compareValues && gotValue != expectedValues[key]:
+			diffs = append(diffs, fmt.Sprintf("%s: tag %s expected \"%s\" but got \"%s\"", listName, key, expectedValues[key], gotValue))
+		}
+	}
+
+	for _, key := range sortedKeys(gotValues) {
+		if _, exists := expectedValues[key]; !exists {
+			diffs = append(diffs, fmt.Sprintf("%s: unexpected tag %s=\"%s\"", listName, key, gotValues[key]))
+		}
+	}
+
+	return diffs
+}
+
+func tagsToMap(tagsList []*tags.Tag) map[string]string {
+	res := make(map[string]string)
+	for _, tag := range tagsList {
+		res[tag.Key] = tag.Value
+	}
+
+	return res
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,55 @@
+package ruletest
+
+import (
+	"testing"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/rules"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestRun(t *testing.T) {
+	suite, err := LoadSuite("testdata/suite.yaml")
+	assert.Nil(t, err)
+
+	rulesList := []*rules.Rule{
+		&rules.Rule{Action: rules.RuleActionAdd, Tag: "team", Query: "persistentvolumeclaim.labels.team"},
+		&rules.Rule{
+			Action: rules.RuleActionAdd,
+			Tag:    "name",
+			Query:  "persistentvolume.name",
+			When: []*rules.Condition{
+				&rules.Condition{Condition: "persistentvolume.phase", Operator: rules.ConditionOperatorEqual, Value: "Bound"},
+			},
+		},
+		&rules.Rule{Action: rules.RuleActionDelete, Tag: "old"},
+		&rules.Rule{Action: rules.RuleActionAdd, Tag: "cost-center", Query: "namespace.labels.cost-center"},
+	}
+
+	results := Run(suite, "aws", rulesList)
+
+	assert.Equal(t, []*Result{
+		&Result{Name: "bound volume", Passed: true, Diffs: []string{}},
+		&Result{Name: "load balancer", Passed: false, Diffs: []string{"addList: missing tag team=\"web\""}},
+	}, results)
+}
+
+func TestRunInvalidTestCases(t *testing.T) {
+	suite := &Suite{Tests: []*TestCase{
+		&TestCase{Name: "empty"},
+		&TestCase{Name: "claim only", PersistentVolumeClaim: &v1.PersistentVolumeClaim{}},
+		&TestCase{Name: "too many", PersistentVolume: &v1.PersistentVolume{}, Service: &v1.Service{}},
+	}}
+
+	results := Run(suite, "aws", []*rules.Rule{})
+
+	assert.Equal(t, []*Result{
+		&Result{Name: "empty", Error: ErrNoObjectInTestCase.Error()},
+		&Result{Name: "claim only", Error: ErrClaimWithoutPersistentVolume.Error()},
+		&Result{Name: "too many", Error: ErrTooManyObjectsInTestCase.Error()},
+	}, results)
+}
+
+func TestDiffTags(t *testing.T) {
+	assert.Equal(t, []string{}, diffTags("list", nil, nil, true))
+}
@@ -0,0 +1,54 @@
+package ruletest
+
+import (
+	"errors"
+
+	providerclient "github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/providerClient"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/resources"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/tags"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+)
+
+// ErrNoObjectInTestCase Test case without object error.
+var ErrNoObjectInTestCase = errors.New("test case must have a persistentVolume or a service")
+
+// ErrTooManyObjectsInTestCase Test case with persistent volume and service error.
+var ErrTooManyObjectsInTestCase = errors.New("test case cannot have a persistentVolume and a service at the same time")
+
+// ErrClaimWithoutPersistentVolume Test case with claim but without persistent volume error.
+var ErrClaimWithoutPersistentVolume = errors.New("test case cannot have a persistentVolumeClaim without persistentVolume")
+
+// Suite Rules test suite.
+type Suite struct {
+	Tests []*TestCase `json:"tests"`
+}
+
+// TestCase Rules test case built from Kubernetes manifests.
+type TestCase struct {
+	Name                  string                    `json:"name"`
+	PersistentVolume      *v1.PersistentVolume      `json:"persistentVolume,omitempty"`
+	PersistentVolumeClaim *v1.PersistentVolumeClaim `json:"persistentVolumeClaim,omitempty"`
+	Service               *v1.Service               `json:"service,omitempty"`
+	// Namespace of the persistent volume claim or the service
+	Namespace *v1.Namespace `json:"namespace,omitempty"`
+	// Workload of pods using the persistent volume claim or the service
+	Workload *resources.Workload `json:"workload,omitempty"`
+	// Storage class of the persistent volume
+	StorageClass *storagev1.StorageClass `json:"storageClass,omitempty"`
+	// Load balancer of the service from provider
+	LoadBalancer *providerclient.LoadBalancer `json:"loadBalancer,omitempty"`
+	// Cluster values by key (discovered facts and static values)
+	Cluster map[string]string `json:"cluster,omitempty"`
+	// Actual tags on cloud resource by key
+	ActualTags map[string]string `json:"actualTags,omitempty"`
+	Expected   *tags.TagDelta    `json:"expected"`
+}
+
+// Result Test case result.
+type Result struct {
+	Name   string   `json:"name"`
+	Passed bool     `json:"passed"`
+	Error  string   `json:"error,omitempty"`
+	Diffs  []string `json:"diffs,omitempty"`
+}
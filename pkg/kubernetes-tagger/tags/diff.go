@@ -0,0 +1,89 @@
+package tags
+
+// ChangeAction Tag change action.
+type ChangeAction string
+
+// ChangeActionAdd Tag added.
+const ChangeActionAdd = ChangeAction("add")
+
+// ChangeActionUpdate Tag value updated.
+const ChangeActionUpdate = ChangeAction("update")
+
+// ChangeActionDelete Tag deleted.
+const ChangeActionDelete = ChangeAction("delete")
+
+// Change Tag change with old and new values.
+type Change struct {
+	Action   ChangeAction `json:"action"`
+	Key      string       `json:"key"`
+	OldValue string       `json:"oldValue,omitempty"`
+	NewValue string       `json:"newValue,omitempty"`
+}
+
+// Changes Get changes applied by delta on actual tags.
+func (delta *TagDelta) Changes(actualTags []*Tag) []*Change {
+	changes := make([]*Change, 0)
+
+	// Index actual values by key
+	actualValues := make(map[string]string)
+	for _, tag := range actualTags {
+		actualValues[tag.Key] = tag.Value
+	}
+
+	for _, tag := range delta.AddList {
+		oldValue, exists := actualValues[tag.Key]
+		if exists {
+			changes = append(changes, &Change{Action: ChangeActionUpdate, Key: tag.Key, OldValue: oldValue, NewValue: tag.Value})
+		} else {
+			changes = append(changes, &Change{Action: ChangeActionAdd, Key: tag.Key, NewValue: tag.Value})
+		}
+	}
+
+	for _, tag := range delta.DeleteList {
+		changes = append(changes, &Change{Action: ChangeActionDelete, Key: tag.Key, OldValue: tag.Value})
+	}
+
+	return changes
+}
+
+// Size Get number of tags added or updated and deleted by delta.
+func (delta *TagDelta) Size() int {
+	return len(delta.AddList) + len(delta.DeleteList)
+}
+
+// Apply Get tags on resource once delta is applied on actual tags.
+func (delta *TagDelta) Apply(actualTags []*Tag) []*Tag {
+	values := make(map[string]string)
+	keys := make([]string, 0)
+
+	for _, tag := range actualTags {
+		if _, exists := values[tag.Key]; !exists {
+			keys = append(keys, tag.Key)
+		}
+
+		values[tag.Key] = tag.Value
+	}
+
+	for _, tag := range delta.AddList {
+		if _, exists := values[tag.Key]; !exists {
+			keys = append(keys, tag.Key)
+		}
+
+		values[tag.Key] = tag.Value
+	}
+
+	for _, tag := range delta.DeleteList {
+		delete(values, tag.Key)
+	}
+
+	result := make([]*Tag, 0)
+
+	for _, key := range keys {
+		value, exists := values[key]
+		if exists {
+			result = append(result, &Tag{Key: key, Value: value})
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,49 @@
+package tags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTagDelta_Changes(t *testing.T) {
+	actualTags := []*Tag{{Key: "updated", Value: "old"}, {Key: "deleted", Value: "value"}}
+	delta := &TagDelta{
+		AddList:    []*Tag{{Key: "added", Value: "new"}, {Key: "updated", Value: "new"}},
+		DeleteList: []*Tag{{Key: "deleted", Value: "value"}},
+	}
+
+	assert.Equal(t, []*Change{
+		{Action: ChangeActionAdd, Key: "added", NewValue: "new"},
+		{Action: ChangeActionUpdate, Key: "updated", OldValue: "old", NewValue: "new"},
+		{Action: ChangeActionDelete, Key: "deleted", OldValue: "value"},
+	}, delta.Changes(actualTags))
+
+	assert.Equal(t, []*Change{}, (&TagDelta{}).Changes(nil))
+}
+
+func TestTagDelta_Size(t *testing.T) {
+	delta := &TagDelta{
+		AddList:    []*Tag{{Key: "added", Value: "new"}, {Key: "updated", Value: "new"}},
+		DeleteList: []*Tag{{Key: "deleted", Value: "value"}},
+	}
+
+	assert.Equal(t, 3, delta.Size())
+	assert.Equal(t, 0, (&TagDelta{}).Size())
+}
+
+func TestTagDelta_Apply(t *testing.T) {
+	actualTags := []*Tag{{Key: "updated", Value: "old"}, {Key: "deleted", Value: "value"}, {Key: "kept", Value: "value"}}
+	delta := &TagDelta{
+		AddList:    []*Tag{{Key: "added", Value: "new"}, {Key: "updated", Value: "new"}},
+		DeleteList: []*Tag{{Key: "deleted", Value: "value"}},
+	}
+
+	assert.Equal(t, []*Tag{
+		{Key: "updated", Value: "new"},
+		{Key: "kept", Value: "value"},
+		{Key: "added", Value: "new"},
+	}, delta.Apply(actualTags))
+
+	assert.Equal(t, []*Tag{}, (&TagDelta{}).Apply(nil))
+}
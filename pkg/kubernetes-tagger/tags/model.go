@@ -2,12 +2,12 @@ package tags
 
 // Tag Tag structure.
 type Tag struct {
-	Key   string
-	Value string
+	Key   string `json:"key"`
+	Value string `json:"value"`
 }
 
 // TagDelta Tag delta with to add and to delete tag lists.
 type TagDelta struct {
-	AddList    []*Tag
-	DeleteList []*Tag
+	AddList    []*Tag `json:"addList"`
+	DeleteList []*Tag `json:"deleteList"`
 }
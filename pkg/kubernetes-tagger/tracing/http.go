@@ -0,0 +1,45 @@
+package tracing
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+// transport HTTP transport creating a span for each request done in a traced run.
+type transport struct {
+	next http.RoundTripper
+}
+
+// WrapTransport Wrap Kubernetes clients transport to trace their requests.
+func WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	return &transport{next: rt}
+}
+
+// RoundTrip Do request in a child span of request context one.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	spanCtx, span := StartChildSpan(req.Context(), "kubernetes "+req.Method,
+		semconv.HTTPMethod(req.Method), semconv.URLPath(req.URL.Path))
+	if !span.IsRecording() {
+		return t.next.RoundTrip(req)
+	}
+
+	resp, err := t.next.RoundTrip(req.WithContext(spanCtx))
+	if err != nil {
+		EndSpan(span, err)
+
+		return resp, err
+	}
+
+	span.SetAttributes(semconv.HTTPStatusCode(resp.StatusCode))
+
+	// Not found objects are expected on gets, only server errors are span errors
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+
+	span.End()
+
+	return resp, nil
+}
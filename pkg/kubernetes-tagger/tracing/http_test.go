@@ -0,0 +1,51 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+func TestWrapTransport(t *testing.T) {
+	recorder := recordSpans(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/namespaces/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: WrapTransport(http.DefaultTransport)}
+	get := func(getCtx context.Context, path string) {
+		req, err := http.NewRequestWithContext(getCtx, http.MethodGet, server.URL+path, nil)
+		assert.NoError(t, err)
+
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	// Requests outside of traced runs aren't traced
+	get(context.Background(), "/api/v1/pods")
+	assert.Empty(t, recorder.Ended())
+
+	runCtx, span := StartSpan(context.Background(), "run")
+	get(runCtx, "/api/v1/namespaces/default")
+	get(runCtx, "/api/v1/namespaces/missing")
+	span.End()
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 3)
+	assert.Equal(t, "kubernetes GET", spans[0].Name())
+	assert.Equal(t, span.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Contains(t, spans[0].Attributes(), semconv.URLPath("/api/v1/namespaces/default"))
+	assert.Contains(t, spans[0].Attributes(), semconv.HTTPStatusCode(http.StatusOK))
+	// Not found isn't an error
+	assert.Contains(t, spans[1].Attributes(), semconv.HTTPStatusCode(http.StatusNotFound))
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+}
@@ -0,0 +1,39 @@
+package tracing
+
+import (
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Log fields correlating entries with traces.
+const (
+	TraceIDField = "traceId"
+	SpanIDField  = "spanId"
+)
+
+// LogHook Logrus hook adding trace and span IDs of entry context to its fields.
+// Only entries created with WithContext from a sampled span have them.
+type LogHook struct{}
+
+// Levels Get levels of entries managed by hook.
+func (LogHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire Add trace fields to entry.
+func (LogHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+
+	spanCtx := trace.SpanContextFromContext(entry.Context)
+	// IDs of spans not exported would reference missing traces
+	if !spanCtx.IsSampled() {
+		return nil
+	}
+
+	entry.Data[TraceIDField] = spanCtx.TraceID().String()
+	entry.Data[SpanIDField] = spanCtx.SpanID().String()
+
+	return nil
+}
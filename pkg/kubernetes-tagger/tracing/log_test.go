@@ -0,0 +1,43 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogHook(t *testing.T) {
+	recordSpans(t)
+
+	buffer := &bytes.Buffer{}
+	logger := logrus.New()
+	logger.SetOutput(buffer)
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.AddHook(LogHook{})
+
+	readFields := func() map[string]interface{} {
+		fields := make(map[string]interface{})
+		assert.NoError(t, json.Unmarshal(buffer.Bytes(), &fields))
+		buffer.Reset()
+
+		return fields
+	}
+
+	logger.Info("no context")
+	assert.NotContains(t, readFields(), TraceIDField)
+
+	logger.WithContext(context.Background()).Info("no span")
+	assert.NotContains(t, readFields(), TraceIDField)
+
+	runCtx, span := StartSpan(context.Background(), "run")
+	defer span.End()
+
+	logger.WithContext(runCtx).Info("traced")
+	fields := readFields()
+	assert.Equal(t, span.SpanContext().TraceID().String(), fields[TraceIDField])
+	assert.Equal(t, span.SpanContext().SpanID().String(), fields[SpanIDField])
+}
@@ -0,0 +1,124 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName Name of the tracer creating all spans.
+const instrumentationName = "github.com/oxyno-zeta/kubernetes-tagger"
+
+// Span attributes keys.
+const (
+	ResourceTypeKey     = attribute.Key("kubernetes_tagger.resource.type")
+	ResourcePlatformKey = attribute.Key("kubernetes_tagger.resource.platform")
+	ResourceIDKey       = attribute.Key("kubernetes_tagger.resource.id")
+	// Kubernetes object reference like pv/<name> or svc/<namespace>/<name>
+	ObjectReferenceKey = attribute.Key("kubernetes_tagger.object.reference")
+	// Number of tags added or updated and deleted by the delta
+	DeltaSizeKey = attribute.Key("kubernetes_tagger.delta.size")
+	// Number of evaluated rules, configuration and tagging policies ones
+	RulesCountKey = attribute.Key("kubernetes_tagger.rules.count")
+	// Cluster name of cloud sweeps
+	ClusterNameKey = attribute.Key("kubernetes_tagger.cluster.name")
+	// Sweep results: cloud resources with the cluster tag, orphans and resources with errors
+	SweepResourcesKey      = attribute.Key("kubernetes_tagger.sweep.resources")
+	SweepOrphansKey        = attribute.Key("kubernetes_tagger.sweep.orphans")
+	SweepResourceErrorsKey = attribute.Key("kubernetes_tagger.sweep.resource_errors")
+)
+
+// Start Set global tracer provider exporting spans as configured.
+// Returned function flushes and stops exports, it must be called before exiting.
+// Without Start, spans aren't recorded.
+func Start(tracingConfig *config.TracingConfig, serviceVersion string) (func(ctx context.Context) error, error) {
+	exporter, err := newExporter(tracingConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(tracingConfig.GetServiceName()),
+			semconv.ServiceVersion(serviceVersion),
+		)),
+		// Sampling is decided on runs (reconciles, on delete actions and sweeps), their calls follow it
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(tracingConfig.GetSampleRatio()))),
+	)
+
+	otel.SetTracerProvider(provider)
+	// Export errors are logged instead of being written on standard error
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		logrus.Warnf("Tracing error: %v", err)
+	}))
+
+	return provider.Shutdown, nil
+}
+
+func newExporter(tracingConfig *config.TracingConfig) (sdktrace.SpanExporter, error) {
+	switch tracingConfig.GetExporter() {
+	case config.TracingExporterStdout:
+		return stdouttrace.New()
+	case config.TracingExporterOTLP:
+		options := make([]otlptracehttp.Option, 0)
+
+		if tracingConfig.OTLP != nil {
+			if tracingConfig.OTLP.Endpoint != "" {
+				options = append(options, otlptracehttp.WithEndpoint(tracingConfig.OTLP.Endpoint))
+			}
+
+			if tracingConfig.OTLP.Insecure {
+				options = append(options, otlptracehttp.WithInsecure())
+			}
+
+			if len(tracingConfig.OTLP.Headers) > 0 {
+				options = append(options, otlptracehttp.WithHeaders(tracingConfig.OTLP.Headers))
+			}
+		}
+
+		// Collector isn't contacted before first export
+		return otlptracehttp.New(context.Background(), options...)
+	default:
+		return nil, fmt.Errorf("%w: %s", config.ErrTracingExporterNotSupported, tracingConfig.Exporter)
+	}
+}
+
+// StartSpan Start a span, child of the context one when it exists.
+func StartSpan(parentCtx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(parentCtx, name, trace.WithAttributes(attrs...))
+}
+
+// StartChildSpan Start a client span only when context has a span.
+// Calls done outside of traced runs (like informers ones) don't create traces.
+func StartChildSpan(parentCtx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(parentCtx).IsValid() {
+		// Span isn't recording, ending it does nothing
+		return parentCtx, trace.SpanFromContext(parentCtx)
+	}
+
+	return otel.Tracer(instrumentationName).Start(parentCtx, name,
+		trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// EndSpan End span with an error status when error isn't nil.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordSpans Record ended spans with a global tracer provider until test end.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(trace.NewNoopTracerProvider()) })
+
+	return recorder
+}
+
+func TestStartChildSpan(t *testing.T) {
+	recorder := recordSpans(t)
+
+	// No span is created outside of traced runs
+	childCtx, span := StartChildSpan(context.Background(), "call")
+	assert.False(t, span.IsRecording())
+	assert.Equal(t, context.Background(), childCtx)
+	EndSpan(span, nil)
+	assert.Empty(t, recorder.Ended())
+
+	runCtx, runSpan := StartSpan(context.Background(), "run", ResourceIDKey.String("vol-1"))
+	_, span = StartChildSpan(runCtx, "call")
+	assert.True(t, span.IsRecording())
+	EndSpan(span, errors.New("access denied"))
+	EndSpan(runSpan, nil)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "call", spans[0].Name())
+	assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+	assert.Equal(t, runSpan.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "access denied", spans[0].Status().Description)
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+	assert.Contains(t, spans[1].Attributes(), ResourceIDKey.String("vol-1"))
+}
+
+func Test_newExporter(t *testing.T) {
+	_, err := newExporter(&config.TracingConfig{Exporter: "zipkin"})
+	assert.ErrorIs(t, err, config.ErrTracingExporterNotSupported)
+
+	exporter, err := newExporter(&config.TracingConfig{Exporter: config.TracingExporterStdout})
+	assert.NoError(t, err)
+	assert.NotNil(t, exporter)
+
+	// Collector isn't contacted on creation
+	exporter, err = newExporter(&config.TracingConfig{OTLP: &config.TracingOTLPConfig{Endpoint: "localhost:4318", Insecure: true}})
+	assert.NoError(t, err)
+	assert.NotNil(t, exporter)
+}
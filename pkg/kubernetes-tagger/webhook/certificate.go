@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+)
+
+// CertificateLoader Load TLS certificate from files and reload it when files change.
+// This allows certificate rotation in mounted secrets without restart.
+type CertificateLoader struct {
+	certFile string
+	keyFile  string
+	mutex    sync.Mutex
+	cert     *tls.Certificate
+	modTime  time.Time
+}
+
+// NewCertificateLoader Create a certificate loader.
+func NewCertificateLoader(certFile, keyFile string) *CertificateLoader {
+	return &CertificateLoader{certFile: certFile, keyFile: keyFile}
+}
+
+// GetCertificate Get certificate for TLS configuration.
+func (cl *CertificateLoader) GetCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cl.mutex.Lock()
+	defer cl.mutex.Unlock()
+
+	modTime, err := cl.getModTime()
+	if err != nil {
+		return nil, err
+	}
+
+	// Reload only when files changed
+	if cl.cert != nil && modTime.Equal(cl.modTime) {
+		return cl.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(cl.certFile, cl.keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	cl.cert = &cert
+	cl.modTime = modTime
+
+	return cl.cert, nil
+}
+
+// getModTime Get last modification time of certificate and key files.
+func (cl *CertificateLoader) getModTime() (time.Time, error) {
+	certInfo, err := os.Stat(cl.certFile)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	keyInfo, err := os.Stat(cl.keyFile)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if keyInfo.ModTime().After(certInfo.ModTime()) {
+		return keyInfo.ModTime(), nil
+	}
+
+	return certInfo.ModTime(), nil
+}
@@ -0,0 +1,292 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/policies"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/resources"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/rules"
+	"github.com/thoas/go-funk"
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Roots of available tag values where labels are checked.
+const (
+	persistentVolumeClaimRoot = "persistentvolumeclaim"
+	serviceRoot               = "service"
+)
+
+// ErrCannotGetRules Rules applied to the object cannot be loaded.
+var ErrCannotGetRules = errors.New("cannot get tagging rules")
+
+// Source Rules and cluster facts used by reconcile.
+type Source interface {
+	// GetAdmissionRules Get configuration and tagging policies rules applied to a resource type in a namespace.
+	GetAdmissionRules(reviewCtx context.Context, resourceType, namespace string) ([]*rules.Rule, error)
+	// GetClusterFacts Get discovered cluster facts, nil when they aren't known.
+	GetClusterFacts(reviewCtx context.Context) *resources.ClusterFacts
+}
+
+// RequiredLabels Get labels used in add rules queries on a root object of available tag values (for example: "service").
+// Rules can't add tags when those labels are missing.
+// Rules with conditions not matching available tag values are ignored. Conditions on values
+// unknown on admission (like persistent volume ones) can't be checked and are considered matched.
+func RequiredLabels(rulesList []*rules.Rule, root string, availableTagValues map[string]interface{}) ([]string, error) {
+	labels := make([]string, 0)
+
+	for _, rule := range rulesList {
+		if rule.Action != rules.RuleActionAdd || rule.Query == "" {
+			continue
+		}
+
+		parts := resources.SplitQuery(rule.Query)
+		if len(parts) != 3 || parts[0] != root || parts[1] != "labels" {
+			continue
+		}
+
+		matched, err := rules.MatchConditions(getKnownConditions(rule.When, availableTagValues), availableTagValues)
+		if err != nil {
+			return nil, err
+		}
+
+		if matched && !funk.ContainsString(labels, parts[2]) {
+			labels = append(labels, parts[2])
+		}
+	}
+
+	sort.Strings(labels)
+
+	return labels, nil
+}
+
+// getKnownConditions Get conditions on roots of available tag values.
+func getKnownConditions(conditions []*rules.Condition, availableTagValues map[string]interface{}) []*rules.Condition {
+	result := make([]*rules.Condition, 0, len(conditions))
+
+	for _, condition := range conditions {
+		if _, ok := availableTagValues[resources.SplitQuery(condition.Condition)[0]]; ok {
+			result = append(result, condition)
+		}
+	}
+
+	return result
+}
+
+// Review Validate admission request with rules applied by reconcile and return the admission response.
+func Review(
+	reviewCtx context.Context,
+	request *admissionv1.AdmissionRequest,
+	source Source,
+	cfg *config.Configuration,
+) *admissionv1.AdmissionResponse {
+	response := &admissionv1.AdmissionResponse{UID: request.UID, Allowed: true}
+
+	// Nothing to check on delete
+	if request.Operation == admissionv1.Delete {
+		return response
+	}
+
+	var (
+		missingLabels []string
+		errs          []string
+		err           error
+	)
+
+	switch {
+	case request.Kind.Group == "" && request.Kind.Kind == "PersistentVolumeClaim":
+		missingLabels, err = reviewPersistentVolumeClaim(reviewCtx, request, source, cfg)
+	case request.Kind.Group == "" && request.Kind.Kind == "Service":
+		missingLabels, err = reviewService(reviewCtx, request, source, cfg)
+	case request.Kind.Group == policies.Group &&
+		(request.Kind.Kind == policies.TaggingPolicyKind || request.Kind.Kind == policies.NamespaceTaggingPolicyKind):
+		errs, err = reviewPolicy(request)
+	}
+
+	// Webhook configuration can be removed by a reload
+	missingLabelsAction := ""
+	if cfg.Webhook != nil {
+		missingLabelsAction = cfg.Webhook.MissingLabelsAction
+	}
+
+	switch {
+	case errors.Is(err, ErrCannotGetRules):
+		// Objects aren't rejected because of an unavailable tagging policies list
+		response.Warnings = []string{"labels required by tagging rules not checked: " + err.Error()}
+	case err != nil:
+		response.Allowed = false
+		response.Result = &metav1.Status{Code: http.StatusBadRequest, Message: err.Error()}
+	case len(errs) != 0:
+		response.Allowed = false
+		response.Result = &metav1.Status{
+			Code:    http.StatusUnprocessableEntity,
+			Message: "invalid tagging policy: " + strings.Join(errs, ", "),
+		}
+	case len(missingLabels) != 0:
+		message := "missing labels required by tagging rules: " + strings.Join(missingLabels, ", ")
+
+		if missingLabelsAction == config.WebhookMissingLabelsActionReject {
+			response.Allowed = false
+			response.Result = &metav1.Status{Code: http.StatusForbidden, Message: message}
+		} else {
+			response.Warnings = []string{message}
+		}
+	}
+
+	return response
+}
+
+func reviewPersistentVolumeClaim(
+	reviewCtx context.Context,
+	request *admissionv1.AdmissionRequest,
+	source Source,
+	cfg *config.Configuration,
+) ([]string, error) {
+	var pvc v1.PersistentVolumeClaim
+
+	err := json.Unmarshal(request.Object.Raw, &pvc)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode persistent volume claim: %w", err)
+	}
+
+	// Namespace isn't always set in object on creation
+	if pvc.Namespace == "" {
+		pvc.Namespace = request.Namespace
+	}
+
+	// Claims not watched are never tagged
+	watched, err := isWatched(cfg, pvc.Namespace, pvc.Labels, getPersistentVolumeClaimsSelector(cfg))
+	if err != nil || !watched {
+		return nil, err
+	}
+
+	availableTagValues := resources.NewPersistentVolumeClaimTagValues(cfg.Provider, &pvc)
+
+	return getMissingLabels(reviewCtx, source, cfg, resources.VolumeResourceType, pvc.Namespace,
+		pvc.Labels, persistentVolumeClaimRoot, availableTagValues)
+}
+
+func reviewService(
+	reviewCtx context.Context,
+	request *admissionv1.AdmissionRequest,
+	source Source,
+	cfg *config.Configuration,
+) ([]string, error) {
+	var svc v1.Service
+
+	err := json.Unmarshal(request.Object.Raw, &svc)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode service: %w", err)
+	}
+
+	// Only load balancer services create cloud resources
+	if svc.Spec.Type != v1.ServiceTypeLoadBalancer {
+		return nil, nil
+	}
+
+	// Namespace isn't always set in object on creation
+	if svc.Namespace == "" {
+		svc.Namespace = request.Namespace
+	}
+
+	// Services not watched are never tagged
+	watched, err := isWatched(cfg, svc.Namespace, svc.Labels, getServicesSelector(cfg))
+	if err != nil || !watched {
+		return nil, err
+	}
+
+	availableTagValues := resources.NewServiceTagValues(cfg.Provider, &svc, nil)
+
+	return getMissingLabels(reviewCtx, source, cfg, resources.LoadBalancerResourceType, svc.Namespace,
+		svc.Labels, serviceRoot, availableTagValues)
+}
+
+// isWatched Check if an object is in the watch scope from its namespace and labels.
+func isWatched(cfg *config.Configuration, namespace string, objectLabels map[string]string, labelSelector string) (bool, error) {
+	if !cfg.IsNamespaceWatched(namespace) {
+		return false, nil
+	}
+
+	if labelSelector == "" {
+		return true, nil
+	}
+
+	selector, err := labels.Parse(labelSelector)
+	if err != nil {
+		return false, err
+	}
+
+	return selector.Matches(labels.Set(objectLabels)), nil
+}
+
+func getPersistentVolumeClaimsSelector(cfg *config.Configuration) string {
+	if cfg.LabelSelectors == nil {
+		return ""
+	}
+
+	return cfg.LabelSelectors.PersistentVolumeClaims
+}
+
+func getServicesSelector(cfg *config.Configuration) string {
+	if cfg.LabelSelectors == nil {
+		return ""
+	}
+
+	return cfg.LabelSelectors.Services
+}
+
+// getMissingLabels Get labels required by rules applied to object and missing in its labels.
+func getMissingLabels(
+	reviewCtx context.Context,
+	source Source,
+	cfg *config.Configuration,
+	resourceType, namespace string,
+	labels map[string]string,
+	root string,
+	availableTagValues map[string]interface{},
+) ([]string, error) {
+	rulesList, err := source.GetAdmissionRules(reviewCtx, resourceType, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCannotGetRules, err)
+	}
+
+	resources.AddClusterTagValues(availableTagValues, cfg.Cluster, source.GetClusterFacts(reviewCtx))
+
+	requiredLabels, err := RequiredLabels(rulesList, root, availableTagValues)
+	if err != nil {
+		return nil, err
+	}
+
+	missingLabels := make([]string, 0)
+
+	for _, label := range requiredLabels {
+		if labels[label] == "" {
+			missingLabels = append(missingLabels, label)
+		}
+	}
+
+	return missingLabels, nil
+}
+
+func reviewPolicy(request *admissionv1.AdmissionRequest) ([]string, error) {
+	var policy policies.Policy
+
+	err := json.Unmarshal(request.Object.Raw, &policy)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode tagging policy: %w", err)
+	}
+
+	// Kind gives rules allowed in the policy, use the reviewed one
+	policy.Kind = request.Kind.Kind
+
+	return policy.Validate(), nil
+}
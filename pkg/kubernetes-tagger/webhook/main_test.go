@@ -0,0 +1,226 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/config"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/policies"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/resources"
+	"github.com/oxyno-zeta/kubernetes-tagger/pkg/kubernetes-tagger/rules"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var testRules = []*rules.Rule{
+	{Tag: "team", Query: "persistentvolumeclaim.labels.team", Action: rules.RuleActionAdd},
+	{Tag: "cost-center", Query: "persistentvolumeclaim.labels.cost-center", Action: rules.RuleActionAdd},
+	{Tag: "app", Query: "service.labels.app\\.kubernetes\\.io/name", Action: rules.RuleActionAdd},
+	{Tag: "team", Query: "service.labels.team", Action: rules.RuleActionAdd},
+	{Tag: "name", Query: "service.name", Action: rules.RuleActionAdd},
+	{Tag: "old", Action: rules.RuleActionDelete},
+}
+
+// fakeSource Source giving test rules for all resource types and namespaces.
+type fakeSource struct {
+	rules []*rules.Rule
+	err   error
+}
+
+func (fs *fakeSource) GetAdmissionRules(_ context.Context, _, _ string) ([]*rules.Rule, error) {
+	return fs.rules, fs.err
+}
+
+func (fs *fakeSource) GetClusterFacts(_ context.Context) *resources.ClusterFacts {
+	return &resources.ClusterFacts{Region: "eu-west-1"}
+}
+
+func newRequest(group, kind string, raw string) *admissionv1.AdmissionRequest {
+	return &admissionv1.AdmissionRequest{
+		UID:       "uid",
+		Kind:      metav1.GroupVersionKind{Group: group, Kind: kind},
+		Operation: admissionv1.Create,
+		Object:    runtime.RawExtension{Raw: []byte(raw)},
+	}
+}
+
+func TestRequiredLabels(t *testing.T) {
+	labels, err := RequiredLabels(testRules, "persistentvolumeclaim", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cost-center", "team"}, labels)
+
+	labels, err = RequiredLabels(testRules, "service", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"app.kubernetes.io/name", "team"}, labels)
+
+	labels, err = RequiredLabels(testRules, "persistentvolume", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{}, labels)
+}
+
+func TestRequiredLabels_Conditions(t *testing.T) {
+	conditionalRules := []*rules.Rule{
+		{
+			Tag: "team", Query: "service.labels.team", Action: rules.RuleActionAdd,
+			When: []*rules.Condition{{Condition: "service.namespace", Value: "prod", Operator: rules.ConditionOperatorEqual}},
+		},
+		{
+			Tag: "app", Query: "service.labels.app", Action: rules.RuleActionAdd,
+			When: []*rules.Condition{{Condition: "cluster.region", Value: "eu-west-1", Operator: rules.ConditionOperatorEqual}},
+		},
+		{
+			// Load balancer provider values aren't known on admission
+			Tag: "owner", Query: "service.labels.owner", Action: rules.RuleActionAdd,
+			When: []*rules.Condition{{Condition: "workload.kind", Value: "Deployment", Operator: rules.ConditionOperatorEqual}},
+		},
+	}
+
+	availableTagValues := map[string]interface{}{
+		"service": map[string]interface{}{"namespace": "dev"},
+		"cluster": map[string]interface{}{"region": "eu-west-1"},
+	}
+
+	labels, err := RequiredLabels(conditionalRules, "service", availableTagValues)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"app", "owner"}, labels)
+}
+
+func TestReview(t *testing.T) {
+	tests := []struct {
+		name                string
+		request             *admissionv1.AdmissionRequest
+		missingLabelsAction string
+		excludeNamespaces   []string
+		labelSelectors      *config.LabelSelectorsConfig
+		wantAllowed         bool
+		wantCode            int32
+		wantWarnings        []string
+	}{
+		{
+			name:        "pvc with labels",
+			request:     newRequest("", "PersistentVolumeClaim", `{"metadata":{"labels":{"team":"a","cost-center":"1"}}}`),
+			wantAllowed: true,
+		},
+		{
+			name:         "pvc missing labels with warn action",
+			request:      newRequest("", "PersistentVolumeClaim", `{"metadata":{"labels":{"team":"a"}}}`),
+			wantAllowed:  true,
+			wantWarnings: []string{"missing labels required by tagging rules: cost-center"},
+		},
+		{
+			name:                "pvc missing labels with reject action",
+			request:             newRequest("", "PersistentVolumeClaim", `{"metadata":{}}`),
+			missingLabelsAction: config.WebhookMissingLabelsActionReject,
+			wantAllowed:         false,
+			wantCode:            http.StatusForbidden,
+		},
+		{
+			name:                "cluster ip service isn't checked",
+			request:             newRequest("", "Service", `{"spec":{"type":"ClusterIP"}}`),
+			missingLabelsAction: config.WebhookMissingLabelsActionReject,
+			wantAllowed:         true,
+		},
+		{
+			name:                "load balancer service missing labels",
+			request:             newRequest("", "Service", `{"metadata":{"labels":{"team":"a"}},"spec":{"type":"LoadBalancer"}}`),
+			missingLabelsAction: config.WebhookMissingLabelsActionReject,
+			wantAllowed:         false,
+			wantCode:            http.StatusForbidden,
+		},
+		{
+			name:                "pvc in excluded namespace isn't checked",
+			request:             newRequest("", "PersistentVolumeClaim", `{"metadata":{"namespace":"kube-system"}}`),
+			missingLabelsAction: config.WebhookMissingLabelsActionReject,
+			excludeNamespaces:   []string{"kube-system"},
+			wantAllowed:         true,
+		},
+		{
+			name:                "pvc not matching label selector isn't checked",
+			request:             newRequest("", "PersistentVolumeClaim", `{"metadata":{"labels":{"tier":"cache"}}}`),
+			missingLabelsAction: config.WebhookMissingLabelsActionReject,
+			labelSelectors:      &config.LabelSelectorsConfig{PersistentVolumeClaims: "tier=db"},
+			wantAllowed:         true,
+		},
+		{
+			name:                "service matching label selector is checked",
+			request:             newRequest("", "Service", `{"metadata":{"labels":{"tier":"db"}},"spec":{"type":"LoadBalancer"}}`),
+			missingLabelsAction: config.WebhookMissingLabelsActionReject,
+			labelSelectors:      &config.LabelSelectorsConfig{Services: "tier=db"},
+			wantAllowed:         false,
+			wantCode:            http.StatusForbidden,
+		},
+		{
+			name:        "invalid object",
+			request:     newRequest("", "Service", `{`),
+			wantAllowed: false,
+			wantCode:    http.StatusBadRequest,
+		},
+		{
+			name: "valid policy",
+			request: newRequest(policies.Group, policies.TaggingPolicyKind,
+				`{"spec":{"rules":[{"tag":"team","value":"a","action":"add"}]}}`),
+			wantAllowed: true,
+		},
+		{
+			name: "invalid policy",
+			request: newRequest(policies.Group, policies.NamespaceTaggingPolicyKind,
+				`{"spec":{"rules":[{"tag":"team","action":"add"}]}}`),
+			wantAllowed: false,
+			wantCode:    http.StatusUnprocessableEntity,
+		},
+		{
+			name: "namespace policy with delete rule",
+			request: newRequest(policies.Group, policies.NamespaceTaggingPolicyKind,
+				`{"spec":{"rules":[{"tag":"kubernetes-cluster","action":"delete"}]}}`),
+			wantAllowed: false,
+			wantCode:    http.StatusUnprocessableEntity,
+		},
+		{
+			name: "cluster policy with delete rule",
+			request: newRequest(policies.Group, policies.TaggingPolicyKind,
+				`{"spec":{"rules":[{"tag":"kubernetes-cluster","action":"delete"}]}}`),
+			wantAllowed: true,
+		},
+		{
+			name:        "other kind",
+			request:     newRequest("apps", "Deployment", `{}`),
+			wantAllowed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Configuration{
+				Provider:          config.AWSProviderName,
+				Webhook:           &config.WebhookConfig{MissingLabelsAction: tt.missingLabelsAction},
+				ExcludeNamespaces: tt.excludeNamespaces,
+				LabelSelectors:    tt.labelSelectors,
+			}
+			response := Review(context.Background(), tt.request, &fakeSource{rules: testRules}, cfg)
+
+			assert.Equal(t, tt.request.UID, response.UID)
+			assert.Equal(t, tt.wantAllowed, response.Allowed)
+			assert.Equal(t, tt.wantWarnings, response.Warnings)
+
+			if tt.wantCode != 0 {
+				assert.Equal(t, tt.wantCode, response.Result.Code)
+			}
+		})
+	}
+}
+
+func TestReview_RulesError(t *testing.T) {
+	cfg := &config.Configuration{
+		Provider: config.AWSProviderName,
+		Webhook:  &config.WebhookConfig{MissingLabelsAction: config.WebhookMissingLabelsActionReject},
+	}
+	request := newRequest("", "PersistentVolumeClaim", `{"metadata":{}}`)
+
+	response := Review(context.Background(), request, &fakeSource{err: errors.New("forbidden")}, cfg)
+
+	assert.True(t, response.Allowed)
+	assert.Equal(t, []string{"labels required by tagging rules not checked: cannot get tagging rules: forbidden"}, response.Warnings)
+}